@@ -0,0 +1,51 @@
+package doctor
+
+import (
+	"fmt"
+
+	"github.com/hirotoni/memov2/cmd/app"
+	"github.com/spf13/cobra"
+)
+
+var (
+	fixFlag    bool
+	formatFlag string
+)
+
+// DoctorCmd represents the doctor command
+var DoctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "check the memos and todos for problems",
+	Long: `Check the memos and todos for problems and print each as
+"path:line: severity code: message", or as a JSON array with --format json.
+
+Codes:
+  frontmatter-malformed   error    the frontmatter cannot be parsed
+  title-missing           error    the memo does not start with an H1
+  memo-unparseable        error    the memo cannot be parsed otherwise
+  title-mismatch          warning  the H1 differs from the title in the file name (fixable)
+  duplicate-timestamp     warning  memos share the date and time in their file names
+  filename-nonconforming  warning  a markdown file in the memos directory is not named as a memo
+  link-broken             warning  a relative link points at a missing file
+  todo-heading-mismatch   warning  a todo file's H1 is not its date (fixable)
+  directory-empty         info     a category directory holds no files (fixable)
+
+With --fix, fixable problems are repaired: a memo whose file name and H1 differ gets
+the title of the one title_source names (heading or filename) in both, a todo file's
+H1 is set to its date, and empty directories are removed. doctor exits non-zero while
+errors or warnings remain, so it can run in a pre-commit hook.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ap, err := app.InitializeApp(cmd)
+		if err != nil {
+			return fmt.Errorf("error initializing app: %w", err)
+		}
+		return ap.Services().Doctor().Check(fixFlag, formatFlag)
+	},
+}
+
+func init() {
+	DoctorCmd.Flags().BoolVar(&fixFlag, "fix", false, "repair the problems that can be repaired")
+	DoctorCmd.Flags().StringVarP(&formatFlag, "format", "f", "text", "output format: text or json")
+}
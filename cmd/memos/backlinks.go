@@ -0,0 +1,26 @@
+package memos
+
+import (
+	"github.com/hirotoni/memov2/cmd/app"
+	"github.com/spf13/cobra"
+)
+
+// backlinksCmd represents the backlinks command
+var backlinksCmd = &cobra.Command{
+	Use:   "backlinks [path]",
+	Short: "list memos that link to a memo",
+	Long:  `List every memo that links to the given memo with a [[title]] or [[title#heading]] wiki link. The path is relative to the memos directory, as printed by "memos list", or id:<id>.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ap, err := app.InitializeApp(cmd)
+		if err != nil {
+			cmd.PrintErrf("Error initializing app: %v\n", err)
+			return
+		}
+		err = ap.Services().Memo().Backlinks(args[0])
+		if err != nil {
+			cmd.PrintErrf("Error listing backlinks: %v\n", err)
+			return
+		}
+	},
+}
@@ -0,0 +1,38 @@
+package memos
+
+import (
+	"github.com/hirotoni/memov2/cmd/app"
+	"github.com/spf13/cobra"
+)
+
+// dictCmd groups the commands managing the SKK dictionaries used by search
+var dictCmd = &cobra.Command{
+	Use:   "dict",
+	Short: "manage the search dictionaries",
+	Long: `Manage the SKK dictionaries search converts romaji with.
+
+Besides the embedded dictionary, search loads the personal dictionary (search_user_dictionary in config.toml) and the dictionaries listed in search_dictionaries, in that order of precedence.`,
+}
+
+// dictAddCmd represents the search dict add command
+var dictAddCmd = &cobra.Command{
+	Use:   "add [reading] [word]",
+	Short: "add a word to the personal search dictionary",
+	Long:  `Add a word to the personal SKK dictionary, so that searching for its reading also finds the word. The reading is hiragana or romaji, e.g. "memos search dict add yamada 山田".`,
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		ap, err := app.InitializeApp(cmd)
+		if err != nil {
+			cmd.PrintErrf("Error initializing app: %v\n", err)
+			return
+		}
+		if err := ap.Services().Memo().AddDictionaryWord(args[0], args[1]); err != nil {
+			cmd.PrintErrf("Error adding dictionary word: %v\n", err)
+			return
+		}
+	},
+}
+
+func init() {
+	dictCmd.AddCommand(dictAddCmd)
+}
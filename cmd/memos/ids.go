@@ -0,0 +1,29 @@
+package memos
+
+import (
+	"github.com/hirotoni/memov2/cmd/app"
+	"github.com/spf13/cobra"
+)
+
+// backfillIDsCmd represents the backfill-ids command
+var backfillIDsCmd = &cobra.Command{
+	Use:   "backfill-ids",
+	Short: "give every memo without one a stable id",
+	Long: `Write a generated id into the frontmatter of every memo that has none, and print
+each as "id<TAB>path". New memos get one when they are created. Unlike the path, the
+id survives renames and moves: "memos open id:<id>" opens the memo, and [[id:<id>]]
+links to it.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ap, err := app.InitializeApp(cmd)
+		if err != nil {
+			cmd.PrintErrf("Error initializing app: %v\n", err)
+			return
+		}
+		err = ap.Services().Memo().BackfillIDs()
+		if err != nil {
+			cmd.PrintErrf("Error backfilling ids: %v\n", err)
+			return
+		}
+	},
+}
@@ -5,20 +5,24 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var shortFlag bool
+var (
+	shortFlag bool
+	tagFlags  []string
+)
 
 // listCmd represents the list command
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "list all memos",
-	Long:  `List all memos in "title\tpath" format. Useful with peco for interactive selection.`,
+	Long: `List all memos in "title\tpath" format. Useful with peco for interactive selection.
+With --tag, only memos tagged with all the given tags are listed.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		ap, err := app.InitializeApp(cmd)
 		if err != nil {
 			cmd.PrintErrf("Error initializing app: %v\n", err)
 			return
 		}
-		err = ap.Services().Memo().List(!shortFlag)
+		err = ap.Services().Memo().List(!shortFlag, tagFlags)
 		if err != nil {
 			cmd.PrintErrf("Error listing memos: %v\n", err)
 			return
@@ -28,4 +32,5 @@ var listCmd = &cobra.Command{
 
 func init() {
 	listCmd.Flags().BoolVarP(&shortFlag, "short", "s", false, "show relative paths instead of full paths")
+	listCmd.Flags().StringSliceVarP(&tagFlags, "tag", "t", nil, "only list memos with this tag (repeatable)")
 }
@@ -21,4 +21,8 @@ func init() {
 	MemosCmd.AddCommand(searchCmd)
 	MemosCmd.AddCommand(renameCmd)
 	MemosCmd.AddCommand(categoriesCmd)
+	MemosCmd.AddCommand(tagsCmd)
+	MemosCmd.AddCommand(backlinksCmd)
+	MemosCmd.AddCommand(backfillIDsCmd)
+	MemosCmd.AddCommand(tidyCmd)
 }
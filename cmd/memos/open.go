@@ -9,8 +9,9 @@ import (
 var openCmd = &cobra.Command{
 	Use:   "open [path]",
 	Short: "open a memo file in the configured editor",
-	Long:  `Open a memo file in the configured editor. The path is relative to the base directory.`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Open a memo file in the configured editor. The path is relative to the base directory,
+or id:<id> for the memo with that id in its frontmatter.`,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		ap, err := app.InitializeApp(cmd)
 		if err != nil {
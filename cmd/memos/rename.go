@@ -13,7 +13,8 @@ import (
 var renameCmd = &cobra.Command{
 	Use:   "rename",
 	Short: "interactively pick a memo and rename it",
-	Long:  `Pick a memo in an embedded TUI, type a new title, and rename it (updates both title and filename).`,
+	Long: `Pick a memo in an embedded TUI, type a new title, and rename it (updates both title and filename).
+Links to the memo in other files are rewritten after a diff preview.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		ap, err := app.InitializeApp(cmd)
 		if err != nil {
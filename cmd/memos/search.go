@@ -4,25 +4,59 @@ Copyright © 2025 hirotoni
 package memos
 
 import (
+	"fmt"
+
 	"github.com/hirotoni/memov2/cmd/app"
 	"github.com/spf13/cobra"
 )
 
+var (
+	queryFlag  string
+	savedFlag  string
+	scopeFlag  string
+	formatFlag string
+	sortFlag   string
+)
+
 // searchCmd launches an interactive, romaji-aware search TUI. Selecting a result
-// opens it in the configured editor.
+// opens it in the configured editor. With --query it prints the results instead.
 var searchCmd = &cobra.Command{
 	Use:   "search",
-	Short: "interactively search memos and open the selection",
-	Long:  `Incrementally search memos in an embedded TUI (romaji-aware): type to filter, ctrl+n/ctrl+p to move, enter to open the highlighted memo in the configured editor.`,
-	Run: func(cmd *cobra.Command, args []string) {
+	Short: "interactively search memos and todos and open the selection",
+	Long: `Search memos and the tasks of the daily todo files in an embedded TUI (romaji-aware) and open the selection in the configured editor. With --query or --saved the results are printed as grep, tsv or json instead.
+
+Queries take qualifiers such as cat:work, after:2025-01-01 and is:open; see "Search queries" and "Headless search" in the README for the syntax and the output formats.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		ap, err := app.InitializeApp(cmd)
 		if err != nil {
-			cmd.PrintErrf("Error initializing app: %v\n", err)
-			return
+			return fmt.Errorf("error initializing app: %w", err)
 		}
-		if err := ap.Services().Memo().SearchInteractive(); err != nil {
-			cmd.PrintErrf("Error searching memos: %v\n", err)
-			return
+		if cmd.Flags().Changed("saved") {
+			if err := ap.Services().Memo().SearchSaved(savedFlag, scopeFlag, formatFlag, sortFlag); err != nil {
+				return fmt.Errorf("error searching memos: %w", err)
+			}
+			return nil
 		}
+		if cmd.Flags().Changed("query") {
+			if err := ap.Services().Memo().Search(queryFlag, scopeFlag, formatFlag, sortFlag); err != nil {
+				return fmt.Errorf("error searching memos: %w", err)
+			}
+			return nil
+		}
+		if err := ap.Services().Memo().SearchInteractive(scopeFlag); err != nil {
+			return fmt.Errorf("error searching memos: %w", err)
+		}
+		return nil
 	},
 }
+
+func init() {
+	searchCmd.AddCommand(dictCmd)
+	searchCmd.Flags().StringVarP(&queryFlag, "query", "q", "", "search without the TUI and print the results")
+	searchCmd.Flags().StringVar(&savedFlag, "saved", "", "run the saved search of this name without the TUI, as --query does")
+	searchCmd.Flags().StringVarP(&scopeFlag, "category", "c", "", "search only the memos in this category and its subcategories, e.g. work/projects")
+	searchCmd.Flags().StringVarP(&formatFlag, "format", "f", "grep", "output format with --query or --saved: json, tsv or grep")
+	searchCmd.Flags().StringVarP(&sortFlag, "sort", "s", "relevance", "order of the results with --query or --saved: relevance or date")
+	searchCmd.MarkFlagsMutuallyExclusive("query", "saved")
+}
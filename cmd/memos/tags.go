@@ -0,0 +1,25 @@
+package memos
+
+import (
+	"github.com/hirotoni/memov2/cmd/app"
+	"github.com/spf13/cobra"
+)
+
+var tagsCmd = &cobra.Command{
+	Use:   "tags",
+	Short: "list all tags with counts",
+	Long: `List the tags of the memos in "tag\tcount" format, the most used first.
+Tags are the tags list in a memo's frontmatter, e.g. tags: [client, meeting].`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ap, err := app.InitializeApp(cmd)
+		if err != nil {
+			cmd.PrintErrf("Error initializing app: %v\n", err)
+			return
+		}
+		err = ap.Services().Memo().ListTags()
+		if err != nil {
+			cmd.PrintErrf("Error listing tags: %v\n", err)
+			return
+		}
+	},
+}
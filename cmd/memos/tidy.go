@@ -0,0 +1,41 @@
+package memos
+
+import (
+	"fmt"
+
+	"github.com/hirotoni/memov2/cmd/app"
+	"github.com/spf13/cobra"
+)
+
+var tidyDryRunFlag bool
+
+// tidyCmd represents the tidy command
+var tidyCmd = &cobra.Command{
+	Use:   "tidy",
+	Short: "move memos into the directory of their category",
+	Long: `Move every memo whose frontmatter category differs from its directory into the
+directory of its category, then remove empty directories. weekly and index tidy first
+too. Each change is printed as "move<TAB>from<TAB>to" and "remove<TAB>directory".
+
+Memos whose file name and H1 disagree are then renamed, as title_source and title_sync
+in the config say, and printed as "title<TAB>path<TAB>new title". title_sync "prompt"
+asks only when run in a terminal; otherwise the renames are printed, not made, and
+count as problems.
+
+A memo is never moved over an existing file: it is reported as a conflict and left in
+place, as are files that cannot be parsed. tidy exits non-zero if there are any.
+With --dry-run, only the moves that would be made and the problems are printed.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ap, err := app.InitializeApp(cmd)
+		if err != nil {
+			return fmt.Errorf("error initializing app: %w", err)
+		}
+		return ap.Services().Memo().Tidy(tidyDryRunFlag)
+	},
+}
+
+func init() {
+	tidyCmd.Flags().BoolVarP(&tidyDryRunFlag, "dry-run", "n", false, "print the planned moves without making them")
+}
@@ -4,6 +4,7 @@ import (
 	"os"
 
 	cmdconfig "github.com/hirotoni/memov2/cmd/config"
+	cmddoctor "github.com/hirotoni/memov2/cmd/doctor"
 	cmdmemos "github.com/hirotoni/memov2/cmd/memos"
 	cmdtodos "github.com/hirotoni/memov2/cmd/todos"
 
@@ -30,4 +31,5 @@ func init() {
 	RootCmd.AddCommand(cmdmemos.MemosCmd)
 	RootCmd.AddCommand(cmdtodos.TodosCmd)
 	RootCmd.AddCommand(cmdconfig.ConfigCmd)
+	RootCmd.AddCommand(cmddoctor.DoctorCmd)
 }
@@ -8,6 +8,40 @@ const (
 	DefaultFolderNameMemos  = "memos/"
 	DefaultTodosDaysToSeek  = 10
 	DefaultEditor           = "vi"
+	// DefaultUserDictionary is the personal SKK dictionary in the config directory
+	DefaultUserDictionary = "SKK-JISYO.user"
+	// DefaultSearchHistory is the file in the config directory the search TUI
+	// keeps its query history in
+	DefaultSearchHistory = "search_history"
+)
+
+// Title sources: which of a memo's file name and H1 holds its title when they disagree
+const (
+	TitleSourceFileName = "filename"
+	TitleSourceHeading  = "heading"
+)
+
+// Title sync modes: what to do when a memo's file name and H1 disagree after
+// an editor session or during tidy
+const (
+	TitleSyncAuto   = "auto"   // rename without asking
+	TitleSyncPrompt = "prompt" // ask before renaming
+	TitleSyncOff    = "off"    // leave the memo alone
+)
+
+const (
+	DefaultTitleSource = TitleSourceHeading
+	DefaultTitleSync   = TitleSyncPrompt
 )
 
 var DefaultEditorArgs = []string{"{path}"}
+
+// DefaultSearchTransliterators are the transliterators search expands words with
+var DefaultSearchTransliterators = []string{"romaji"}
+
+// SavedSearch is a named search query, listed as a virtual folder in the
+// browse tree and run by `memos search --saved`
+type SavedSearch struct {
+	Name  string
+	Query string
+}
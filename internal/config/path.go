@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // ConfigDir returns the configuration directory path
@@ -15,3 +16,15 @@ func ConfigDir() (string, error) {
 	configDir := filepath.Join(home, DefaultFolderNameConfig)
 	return configDir, nil
 }
+
+// ExpandHome replaces a leading ~ in path with the home directory
+func ExpandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
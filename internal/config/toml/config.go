@@ -14,6 +14,15 @@ type Config struct {
 	todosDaysToSeek int
 	editor          string
 	editorArgs      []string
+
+	searchUserDictionary  string
+	searchDictionaries    []string
+	searchTransliterators []string
+	searchHistory         string
+	savedSearches         []config.SavedSearch
+
+	titleSource string
+	titleSync   string
 }
 
 // Option holds configuration options for creating a new Config
@@ -24,6 +33,15 @@ type Option struct {
 	TodosDaysToSeek int
 	Editor          string
 	EditorArgs      []string
+
+	SearchUserDictionary  string
+	SearchDictionaries    []string
+	SearchTransliterators []string
+	SearchHistory         string
+	SavedSearches         []config.SavedSearch
+
+	TitleSource string
+	TitleSync   string
 }
 
 // DTO is a decode/encode surrogate with exported fields for the TOML library
@@ -34,6 +52,21 @@ type DTO struct {
 	TodosDaysToSeek int      `toml:"todos_daystoseek"`
 	Editor          string   `toml:"editor"`
 	EditorArgs      []string `toml:"editor_args"`
+
+	SearchUserDictionary  string           `toml:"search_user_dictionary"`
+	SearchDictionaries    []string         `toml:"search_dictionaries"`
+	SearchTransliterators []string         `toml:"search_transliterators"`
+	SearchHistory         string           `toml:"search_history"`
+	SavedSearches         []SavedSearchDTO `toml:"saved_searches"`
+
+	TitleSource string `toml:"title_source"`
+	TitleSync   string `toml:"title_sync"`
+}
+
+// SavedSearchDTO is the TOML form of a saved search
+type SavedSearchDTO struct {
+	Name  string `toml:"name"`
+	Query string `toml:"query"`
 }
 
 // toDTO converts Config to DTO for TOML encoding
@@ -45,6 +78,15 @@ func (c *Config) toDTO() DTO {
 		TodosDaysToSeek: c.todosDaysToSeek,
 		Editor:          c.editor,
 		EditorArgs:      c.editorArgs,
+
+		SearchUserDictionary:  c.searchUserDictionary,
+		SearchDictionaries:    c.searchDictionaries,
+		SearchTransliterators: c.searchTransliterators,
+		SearchHistory:         c.searchHistory,
+		SavedSearches:         savedSearchDTOs(c.savedSearches),
+
+		TitleSource: c.titleSource,
+		TitleSync:   c.titleSync,
 	}
 }
 
@@ -57,7 +99,32 @@ func fromDTO(d DTO) *Config {
 		todosDaysToSeek: d.TodosDaysToSeek,
 		editor:          d.Editor,
 		editorArgs:      d.EditorArgs,
+
+		searchUserDictionary:  d.SearchUserDictionary,
+		searchDictionaries:    d.SearchDictionaries,
+		searchTransliterators: d.SearchTransliterators,
+		searchHistory:         d.SearchHistory,
+		savedSearches:         savedSearches(d.SavedSearches),
+
+		titleSource: d.TitleSource,
+		titleSync:   d.TitleSync,
+	}
+}
+
+func savedSearchDTOs(searches []config.SavedSearch) []SavedSearchDTO {
+	var dtos []SavedSearchDTO
+	for _, s := range searches {
+		dtos = append(dtos, SavedSearchDTO{Name: s.Name, Query: s.Query})
 	}
+	return dtos
+}
+
+func savedSearches(dtos []SavedSearchDTO) []config.SavedSearch {
+	var searches []config.SavedSearch
+	for _, d := range dtos {
+		searches = append(searches, config.SavedSearch{Name: d.Name, Query: d.Query})
+	}
+	return searches
 }
 
 // BaseDir returns the base directory path
@@ -90,6 +157,65 @@ func (c *Config) EditorArgs() []string {
 	return c.editorArgs
 }
 
+// SearchUserDictionary returns the path of the personal SKK dictionary, which
+// `memos search dict add` appends to
+func (c *Config) SearchUserDictionary() string {
+	return config.ExpandHome(c.searchUserDictionary)
+}
+
+// SearchDictionaries returns the SKK dictionaries search loads before the
+// embedded one, highest precedence first: the personal dictionary, then the
+// search_dictionaries in the order listed
+func (c *Config) SearchDictionaries() []string {
+	var paths []string
+	if c.searchUserDictionary != "" {
+		paths = append(paths, c.SearchUserDictionary())
+	}
+	for _, p := range c.searchDictionaries {
+		paths = append(paths, config.ExpandHome(p))
+	}
+	return paths
+}
+
+// SearchTransliterators returns the names of the transliterators search
+// expands words with, such as romaji for Japanese
+func (c *Config) SearchTransliterators() []string {
+	return c.searchTransliterators
+}
+
+// SearchHistory returns the path of the file the search TUI keeps its query
+// history in, "" if history is off
+func (c *Config) SearchHistory() string {
+	return config.ExpandHome(c.searchHistory)
+}
+
+// SavedSearches returns the saved searches in the order configured
+func (c *Config) SavedSearches() []config.SavedSearch {
+	return c.savedSearches
+}
+
+// SavedSearch returns the saved search named name
+func (c *Config) SavedSearch(name string) (config.SavedSearch, bool) {
+	for _, s := range c.savedSearches {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return config.SavedSearch{}, false
+}
+
+// TitleSource returns which of a memo's file name and H1 holds its title:
+// config.TitleSourceFileName or config.TitleSourceHeading
+func (c *Config) TitleSource() string {
+	return c.titleSource
+}
+
+// TitleSync returns what to do when a memo's file name and H1 disagree:
+// config.TitleSyncAuto, config.TitleSyncPrompt or config.TitleSyncOff
+func (c *Config) TitleSync() string {
+	return c.titleSync
+}
+
 // ConfigDirPath returns the config directory and file path
 func (c *Config) ConfigDirPath() (string, string, error) {
 	dir, err := config.ConfigDir()
@@ -1,8 +1,13 @@
 package toml
 
 import (
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 
+	"github.com/BurntSushi/toml"
+	"github.com/hirotoni/memov2/internal/config"
 	"github.com/stretchr/testify/require"
 )
 
@@ -92,3 +97,85 @@ func TestConfig_Dirs(t *testing.T) {
 	}
 }
 
+
+func TestConfig_SearchDictionaries(t *testing.T) {
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+
+	cfg, err := NewConfig(Option{
+		SearchUserDictionary: "/dict/user",
+		SearchDictionaries:   []string{"~/skk/SKK-JISYO.L", "/dict/names"},
+	})
+	require.NoError(t, err)
+
+	want := []string{"/dict/user", filepath.Join(home, "skk/SKK-JISYO.L"), "/dict/names"}
+	if got := cfg.SearchDictionaries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("SearchDictionaries() = %v, want %v", got, want)
+	}
+
+	def, err := NewDefaultConfig()
+	require.NoError(t, err)
+	if got := def.SearchDictionaries(); len(got) != 1 || filepath.Base(got[0]) != "SKK-JISYO.user" {
+		t.Errorf("default SearchDictionaries() = %v, want the personal dictionary only", got)
+	}
+}
+
+func TestConfig_SearchTransliterators(t *testing.T) {
+	def, err := NewDefaultConfig()
+	require.NoError(t, err)
+	if got := def.SearchTransliterators(); !reflect.DeepEqual(got, []string{"romaji"}) {
+		t.Errorf("default SearchTransliterators() = %v, want [romaji]", got)
+	}
+
+	cfg, err := NewConfig(Option{SearchTransliterators: []string{"romaji", "pinyin", "hangul"}})
+	require.NoError(t, err)
+	if got := cfg.SearchTransliterators(); !reflect.DeepEqual(got, []string{"romaji", "pinyin", "hangul"}) {
+		t.Errorf("SearchTransliterators() = %v, want [romaji pinyin hangul]", got)
+	}
+}
+
+func TestConfig_SavedSearches(t *testing.T) {
+	var dto DTO
+	_, err := toml.Decode(`
+[[saved_searches]]
+name = "open questions"
+query = "is:open question"
+
+[[saved_searches]]
+name = "work"
+query = "cat:work"
+`, &dto)
+	require.NoError(t, err)
+
+	cfg := fromDTO(dto)
+	want := []config.SavedSearch{
+		{Name: "open questions", Query: "is:open question"},
+		{Name: "work", Query: "cat:work"},
+	}
+	if got := cfg.SavedSearches(); !reflect.DeepEqual(got, want) {
+		t.Errorf("SavedSearches() = %v, want %v", got, want)
+	}
+
+	if got, ok := cfg.SavedSearch("work"); !ok || got.Query != "cat:work" {
+		t.Errorf("SavedSearch(work) = %v, %v, want cat:work", got, ok)
+	}
+	if _, ok := cfg.SavedSearch("missing"); ok {
+		t.Errorf("SavedSearch(missing) found, want none")
+	}
+}
+
+func TestConfig_Title(t *testing.T) {
+	cfg, err := NewConfig(Option{})
+	require.NoError(t, err)
+	if cfg.TitleSource() != config.TitleSourceHeading || cfg.TitleSync() != config.TitleSyncPrompt {
+		t.Errorf("TitleSource(), TitleSync() = %q, %q, want heading, prompt", cfg.TitleSource(), cfg.TitleSync())
+	}
+
+	var dto DTO
+	_, err = toml.Decode("title_source = \"filename\"\ntitle_sync = \"auto\"\n", &dto)
+	require.NoError(t, err)
+	cfg = fromDTO(dto)
+	if cfg.TitleSource() != config.TitleSourceFileName || cfg.TitleSync() != config.TitleSyncAuto {
+		t.Errorf("TitleSource(), TitleSync() = %q, %q, want filename, auto", cfg.TitleSource(), cfg.TitleSync())
+	}
+}
@@ -34,6 +34,27 @@ func NewConfig(opt Option) (*Config, error) {
 	if len(opt.EditorArgs) > 0 {
 		c.editorArgs = opt.EditorArgs
 	}
+	if opt.SearchUserDictionary != "" {
+		c.searchUserDictionary = opt.SearchUserDictionary
+	}
+	if len(opt.SearchDictionaries) > 0 {
+		c.searchDictionaries = opt.SearchDictionaries
+	}
+	if len(opt.SearchTransliterators) > 0 {
+		c.searchTransliterators = opt.SearchTransliterators
+	}
+	if opt.SearchHistory != "" {
+		c.searchHistory = opt.SearchHistory
+	}
+	if len(opt.SavedSearches) > 0 {
+		c.savedSearches = opt.SavedSearches
+	}
+	if opt.TitleSource != "" {
+		c.titleSource = opt.TitleSource
+	}
+	if opt.TitleSync != "" {
+		c.titleSync = opt.TitleSync
+	}
 
 	return c, nil
 }
@@ -52,6 +73,13 @@ func NewDefaultConfig() (*Config, error) {
 		todosDaysToSeek: config.DefaultTodosDaysToSeek,
 		editor:          config.DefaultEditor,
 		editorArgs:      config.DefaultEditorArgs,
+
+		searchUserDictionary:  filepath.Join(dir, config.DefaultUserDictionary),
+		searchTransliterators: config.DefaultSearchTransliterators,
+		searchHistory:         filepath.Join(dir, config.DefaultSearchHistory),
+
+		titleSource: config.DefaultTitleSource,
+		titleSync:   config.DefaultTitleSync,
 	}, nil
 }
 
@@ -121,6 +149,32 @@ func LoadConfig() (*Config, error) {
 	if len(c.editorArgs) == 0 {
 		c.editorArgs = config.DefaultEditorArgs
 	}
+	if c.searchUserDictionary == "" {
+		c.searchUserDictionary = filepath.Join(dir, config.DefaultUserDictionary)
+	}
+	if len(c.searchTransliterators) == 0 {
+		c.searchTransliterators = config.DefaultSearchTransliterators
+	}
+	if c.searchHistory == "" {
+		c.searchHistory = filepath.Join(dir, config.DefaultSearchHistory)
+	}
+	if c.titleSource == "" {
+		c.titleSource = config.DefaultTitleSource
+	}
+	if c.titleSync == "" {
+		c.titleSync = config.DefaultTitleSync
+	}
+	if c.titleSource != config.TitleSourceFileName && c.titleSource != config.TitleSourceHeading {
+		return nil, fmt.Errorf("title_source is %q, want %q or %q", c.titleSource, config.TitleSourceFileName, config.TitleSourceHeading)
+	}
+	if c.titleSync != config.TitleSyncAuto && c.titleSync != config.TitleSyncPrompt && c.titleSync != config.TitleSyncOff {
+		return nil, fmt.Errorf("title_sync is %q, want %q, %q or %q", c.titleSync, config.TitleSyncAuto, config.TitleSyncPrompt, config.TitleSyncOff)
+	}
+	for _, s := range c.savedSearches {
+		if s.Name == "" || s.Query == "" {
+			return nil, fmt.Errorf("saved search %q needs both a name and a query", s.Name)
+		}
+	}
 	return c, nil
 }
 
@@ -1,6 +1,7 @@
 package toml
 
 import (
+	"github.com/hirotoni/memov2/internal/config"
 	"github.com/hirotoni/memov2/internal/interfaces"
 )
 
@@ -44,6 +45,46 @@ func (p *Provider) EditorArgs() []string {
 	return p.config.EditorArgs()
 }
 
+// SearchUserDictionary returns the path of the personal SKK dictionary
+func (p *Provider) SearchUserDictionary() string {
+	return p.config.SearchUserDictionary()
+}
+
+// SearchDictionaries returns the SKK dictionaries to load, highest precedence first
+func (p *Provider) SearchDictionaries() []string {
+	return p.config.SearchDictionaries()
+}
+
+// SearchTransliterators returns the names of the transliterators search uses
+func (p *Provider) SearchTransliterators() []string {
+	return p.config.SearchTransliterators()
+}
+
+// SearchHistory returns the path of the search TUI's query history
+func (p *Provider) SearchHistory() string {
+	return p.config.SearchHistory()
+}
+
+// SavedSearches returns the saved searches in the order configured
+func (p *Provider) SavedSearches() []config.SavedSearch {
+	return p.config.SavedSearches()
+}
+
+// SavedSearch returns the saved search named name
+func (p *Provider) SavedSearch(name string) (config.SavedSearch, bool) {
+	return p.config.SavedSearch(name)
+}
+
+// TitleSource returns which of a memo's file name and H1 holds its title
+func (p *Provider) TitleSource() string {
+	return p.config.TitleSource()
+}
+
+// TitleSync returns what to do when a memo's file name and H1 disagree
+func (p *Provider) TitleSync() string {
+	return p.config.TitleSync()
+}
+
 // ConfigDirPath returns the config directory and file path
 func (p *Provider) ConfigDirPath() (string, string, error) {
 	return p.config.ConfigDirPath()
@@ -97,9 +97,14 @@ func (f *file) OverrideHeadingBlocksMatched(entities []*markdown.HeadingBlock) e
 // Data implements the common markdown serialization for files.
 // File-type specific structs embedding file may override to prepend/append sections via composition.
 func (f *file) ContentString() string {
+	return f.contentString(f.Title())
+}
+
+// contentString serializes the file with heading as its H1
+func (f *file) contentString(heading string) string {
 	var sb strings.Builder
 	// 1) Title
-	sb.WriteString("# " + f.Title() + "\n\n")
+	sb.WriteString("# " + heading + "\n\n")
 
 	// 2) Top level body content (if any)
 	if tl := f.TopLevelBodyContent(); tl != nil && tl.ContentText != "" {
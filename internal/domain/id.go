@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"strings"
+	"time"
+)
+
+// MemoIDPrefix marks a memo ID where a path or a link target is expected, as in
+// `memos open id:01J...` or [[id:01J...]]
+const MemoIDPrefix = "id:"
+
+// crockford is the base32 alphabet of ULIDs, without I, L, O and U
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewMemoID returns a new ULID: 26 characters that sort by t, to the
+// millisecond, and are otherwise random
+func NewMemoID(t time.Time) string {
+	var id [16]byte
+	ms := uint64(t.UnixMilli())
+	binary.BigEndian.PutUint16(id[0:2], uint16(ms>>32))
+	binary.BigEndian.PutUint32(id[2:6], uint32(ms))
+	rand.Read(id[6:])
+
+	// 128 bits in 26 characters of 5 bits, the first of which has 2 bits to spare
+	var sb strings.Builder
+	for i := range 26 {
+		offset := i*5 - 2
+		var v byte
+		for b := range 5 {
+			bit := offset + b
+			if bit < 0 {
+				continue
+			}
+			v = v<<1 | (id[bit/8]>>(7-bit%8))&1
+		}
+		sb.WriteByte(crockford[v])
+	}
+	return sb.String()
+}
+
+// ParseMemoIDRef returns the ID of ref if it is written as id:<id>
+func ParseMemoIDRef(ref string) (string, bool) {
+	id, ok := strings.CutPrefix(ref, MemoIDPrefix)
+	if !ok || strings.TrimSpace(id) == "" {
+		return "", false
+	}
+	return strings.TrimSpace(id), true
+}
@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMemoID(t *testing.T) {
+	base := time.Date(2025, 2, 14, 9, 30, 0, 0, time.UTC)
+
+	id := NewMemoID(base)
+	assert.Regexp(t, `^[0-9A-HJKMNP-TV-Z]{26}$`, id)
+	assert.Equal(t, "01JM1", id[:5], "the time comes first")
+	assert.NotEqual(t, id, NewMemoID(base), "the rest is random")
+
+	// IDs sort by time
+	assert.Less(t, id, NewMemoID(base.Add(time.Millisecond)))
+	assert.Less(t, NewMemoID(base.Add(-time.Hour)), id)
+}
+
+func TestParseMemoIDRef(t *testing.T) {
+	id, ok := ParseMemoIDRef("id:01JM1ABC")
+	assert.True(t, ok)
+	assert.Equal(t, "01JM1ABC", id)
+
+	for _, ref := range []string{"01JM1ABC", "id:", "memos/work/note.md"} {
+		_, ok := ParseMemoIDRef(ref)
+		assert.False(t, ok, ref)
+	}
+}
@@ -0,0 +1,181 @@
+package domain
+
+import (
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/hirotoni/memov2/internal/domain/markdown"
+	"github.com/hirotoni/memov2/internal/interfaces"
+)
+
+// LinkIndex resolves wiki links between a set of memos
+type LinkIndex struct {
+	memos     []MemoFileInterface
+	byName    map[string]MemoFileInterface
+	byID      map[string]MemoFileInterface
+	byTitle   map[string][]MemoFileInterface // newest first
+	backlinks map[string][]interfaces.Backlink
+}
+
+// NewLinkIndex creates a link index over memos
+func NewLinkIndex(memos []MemoFileInterface) *LinkIndex {
+	idx := &LinkIndex{
+		memos:   memos,
+		byName:  make(map[string]MemoFileInterface),
+		byID:    make(map[string]MemoFileInterface),
+		byTitle: make(map[string][]MemoFileInterface),
+	}
+
+	for _, m := range memos {
+		idx.add(m)
+	}
+
+	for _, ms := range idx.byTitle {
+		sortNewestFirst(ms)
+	}
+
+	return idx
+}
+
+// Replace puts m in the place of the memo at oldPath, relative to the memos
+// directory, as after a rename or move
+func (idx *LinkIndex) Replace(oldPath string, m MemoFileInterface) {
+	i := slices.IndexFunc(idx.memos, func(old MemoFileInterface) bool { return MemoPath(old) == oldPath })
+	if i < 0 {
+		return
+	}
+	old := idx.memos[i]
+	idx.memos[i] = m
+
+	name := strings.ToLower(old.FileName())
+	for _, key := range []string{name, strings.TrimSuffix(name, FileExtension)} {
+		if idx.byName[key] == old {
+			delete(idx.byName, key)
+		}
+	}
+	if id := strings.ToUpper(old.ID()); id != "" && idx.byID[id] == old {
+		delete(idx.byID, id)
+	}
+	for _, key := range titleKeys(old) {
+		ms := slices.DeleteFunc(idx.byTitle[key], func(t MemoFileInterface) bool { return t == old })
+		if len(ms) == 0 {
+			delete(idx.byTitle, key)
+		} else {
+			idx.byTitle[key] = ms
+		}
+	}
+
+	idx.add(m)
+	for _, key := range titleKeys(m) {
+		sortNewestFirst(idx.byTitle[key])
+	}
+	idx.backlinks = nil
+}
+
+// add indexes m by its file name, ID and titles
+func (idx *LinkIndex) add(m MemoFileInterface) {
+	name := strings.ToLower(m.FileName())
+	idx.byName[name] = m
+	idx.byName[strings.TrimSuffix(name, FileExtension)] = m
+	if id := m.ID(); id != "" {
+		idx.byID[strings.ToUpper(id)] = m
+	}
+	for _, key := range titleKeys(m) {
+		idx.byTitle[key] = append(idx.byTitle[key], m)
+	}
+}
+
+// titleKeys returns the keys a link to the title or H1 of m is looked up by
+func titleKeys(m MemoFileInterface) []string {
+	titles := []string{m.Title()}
+	if tl := m.TopLevelBodyContent(); tl != nil && tl.HeadingText != "" {
+		titles = append(titles, tl.HeadingText)
+	}
+	var keys []string
+	for _, title := range titles {
+		key := normalizeLinkTarget(title)
+		if key != "" && !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func sortNewestFirst(ms []MemoFileInterface) {
+	sort.SliceStable(ms, func(i, j int) bool { return ms[i].Date().After(ms[j].Date()) })
+}
+
+// normalizeLinkTarget makes titles comparable regardless of case and of spaces
+// having been replaced with FileFiller in file names
+func normalizeLinkTarget(s string) string {
+	s = strings.ToLower(strings.ReplaceAll(s, FileFiller, " "))
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// MemoPath returns the path of a memo relative to the memos directory, which identifies it
+func MemoPath(m MemoFileInterface) string {
+	return filepath.Join(m.Location(), m.FileName())
+}
+
+// Resolve returns the memo a link points to. A target matches a file name
+// (with or without extension) or a title; if several memos share the title,
+// the newest one wins. A target written as id:<id> matches the memo's ID.
+func (idx *LinkIndex) Resolve(link markdown.WikiLink) (MemoFileInterface, bool) {
+	if id, ok := ParseMemoIDRef(link.Target); ok {
+		m, ok := idx.byID[strings.ToUpper(id)]
+		return m, ok
+	}
+	if m, ok := idx.byName[strings.ToLower(link.Target)]; ok {
+		return m, true
+	}
+	if ms := idx.byTitle[normalizeLinkTarget(link.Target)]; len(ms) > 0 {
+		return ms[0], true
+	}
+	return nil, false
+}
+
+// Backlinks returns the links in other memos that point to target, ordered by source memo date (newest first)
+func (idx *LinkIndex) Backlinks(target MemoFileInterface) []interfaces.Backlink {
+	if idx.backlinks == nil {
+		idx.buildBacklinks()
+	}
+	return idx.backlinks[MemoPath(target)]
+}
+
+func (idx *LinkIndex) buildBacklinks() {
+	idx.backlinks = make(map[string][]interfaces.Backlink)
+
+	sources := make([]MemoFileInterface, len(idx.memos))
+	copy(sources, idx.memos)
+	sort.SliceStable(sources, func(i, j int) bool { return sources[i].Date().After(sources[j].Date()) })
+
+	for _, source := range sources {
+		sourcePath := MemoPath(source)
+		add := func(section, text string) {
+			for _, link := range markdown.ParseWikiLinks(text) {
+				target, ok := idx.Resolve(link)
+				if !ok {
+					continue
+				}
+				targetPath := MemoPath(target)
+				if targetPath == sourcePath {
+					continue
+				}
+				idx.backlinks[targetPath] = append(idx.backlinks[targetPath], interfaces.Backlink{
+					Source:  source,
+					Link:    link,
+					Section: section,
+				})
+			}
+		}
+
+		if tl := source.TopLevelBodyContent(); tl != nil {
+			add("", tl.ContentText)
+		}
+		for _, hb := range source.HeadingBlocks() {
+			add(hb.HeadingText, hb.ContentText)
+		}
+	}
+}
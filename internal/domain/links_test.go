@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hirotoni/memov2/internal/domain/markdown"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLinkTestMemo(t *testing.T, date time.Time, title string, category []string, body string, blocks ...*markdown.HeadingBlock) MemoFileInterface {
+	t.Helper()
+	m, err := NewMemoFile(date, title, category)
+	require.NoError(t, err)
+	m.SetTopLevelBodyContent(&markdown.HeadingBlock{Level: 1, HeadingText: title, ContentText: body})
+	m.SetHeadingBlocks(blocks)
+	return m
+}
+
+func TestLinkIndex_Resolve(t *testing.T) {
+	base := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	older := newLinkTestMemo(t, base, "meeting-notes", []string{"work"}, "")
+	newer := newLinkTestMemo(t, base.Add(time.Hour), "meeting-notes", []string{"archive"}, "")
+	other := newLinkTestMemo(t, base, "Design Doc", nil, "")
+	other.SetID("01JKZ3Q8W5XG7M2N4P6R8T0V9B")
+
+	idx := NewLinkIndex([]MemoFileInterface{older, newer, other})
+
+	testCases := []struct {
+		name   string
+		target string
+		want   MemoFileInterface
+	}{
+		{name: "title with spaces matches filler", target: "Meeting Notes", want: newer},
+		{name: "file name without extension", target: "20250101Wed090000_memo_meeting-notes", want: older},
+		{name: "file name with extension", target: "20250101Wed090000_memo_meeting-notes.md", want: older},
+		{name: "exact title", target: "design doc", want: other},
+		{name: "id", target: "id:01jkz3q8w5xg7m2n4p6r8t0v9b", want: other},
+		{name: "unknown id", target: "id:01JKZ3Q8W5XG7M2N4P6R8T0V9C", want: nil},
+		{name: "unknown", target: "nothing", want: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := idx.Resolve(markdown.WikiLink{Target: tc.target})
+			assert.Equal(t, tc.want != nil, ok)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestLinkIndex_Replace(t *testing.T) {
+	base := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	older := newLinkTestMemo(t, base, "meeting-notes", []string{"work"}, "")
+	newer := newLinkTestMemo(t, base.Add(time.Hour), "meeting-notes", []string{"archive"}, "")
+	idx := NewLinkIndex([]MemoFileInterface{older, newer})
+
+	renamed := newLinkTestMemo(t, newer.Date(), "retro", []string{"archive"}, "")
+	idx.Replace(MemoPath(newer), renamed)
+
+	got, ok := idx.Resolve(markdown.WikiLink{Target: "meeting notes"})
+	assert.True(t, ok)
+	assert.Equal(t, older, got, "the title falls back to the other memo")
+	got, ok = idx.Resolve(markdown.WikiLink{Target: "retro"})
+	assert.True(t, ok)
+	assert.Equal(t, renamed, got)
+	_, ok = idx.Resolve(markdown.WikiLink{Target: newer.FileName()})
+	assert.False(t, ok)
+}
+
+func TestLinkIndex_Backlinks(t *testing.T) {
+	base := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	target := newLinkTestMemo(t, base, "target", []string{"work"}, "self link [[target]]")
+	first := newLinkTestMemo(t, base.Add(time.Hour), "first", nil, "see [[target]]")
+	second := newLinkTestMemo(t, base.Add(2*time.Hour), "second", nil, "no links here",
+		&markdown.HeadingBlock{Level: 2, HeadingText: "refs", ContentText: "[[Target#agenda]] and [[missing]]\n"},
+	)
+
+	idx := NewLinkIndex([]MemoFileInterface{target, first, second})
+
+	backlinks := idx.Backlinks(target)
+	require.Len(t, backlinks, 2)
+
+	assert.Equal(t, second, backlinks[0].Source)
+	assert.Equal(t, "refs", backlinks[0].Section)
+	assert.Equal(t, "agenda", backlinks[0].Link.Heading)
+
+	assert.Equal(t, first, backlinks[1].Source)
+	assert.Equal(t, "", backlinks[1].Section)
+	assert.Equal(t, "[[target]]", backlinks[1].Link.Raw)
+
+	assert.Empty(t, idx.Backlinks(first))
+}
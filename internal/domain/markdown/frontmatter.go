@@ -0,0 +1,368 @@
+package markdown
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FrontmatterDelimiter opens and closes the YAML frontmatter block of a markdown file.
+const FrontmatterDelimiter = "---"
+
+// frontmatterEntry is a single top-level key of the frontmatter.
+type frontmatterEntry struct {
+	key    string
+	value  *yaml.Node
+	prefix string // comment and blank lines preceding the key in the source
+	raw    string // original source text of the entry; empty once the value is modified
+}
+
+// Frontmatter represents the YAML metadata block of a markdown file.
+// Every top-level key is kept in its original order, and entries that are not
+// modified are written back byte-for-byte, so keys unknown to the application
+// survive a load/save round trip.
+type Frontmatter struct {
+	head    string // comment and blank lines before the first key
+	tail    string // comment and blank lines after the last key
+	entries []*frontmatterEntry
+}
+
+// NewFrontmatter creates an empty frontmatter
+func NewFrontmatter() *Frontmatter {
+	return &Frontmatter{}
+}
+
+// SplitFrontmatter splits source into the YAML text between the frontmatter
+// delimiters and the remaining markdown body. ok is false if source does not
+// start with a frontmatter block.
+func SplitFrontmatter(source []byte) (yamlText []byte, body []byte, ok bool) {
+	first, rest, found := bytes.Cut(source, []byte("\n"))
+	if !found || !IsFrontmatterDelimiter(first) {
+		return nil, source, false
+	}
+
+	offset := 0
+	for offset < len(rest) {
+		line, _, _ := bytes.Cut(rest[offset:], []byte("\n"))
+		end := offset + len(line) + 1
+		if IsFrontmatterDelimiter(line) {
+			return rest[:offset], rest[min(end, len(rest)):], true
+		}
+		offset = end
+	}
+
+	return nil, source, false
+}
+
+// IsFrontmatterDelimiter reports whether line opens or closes a frontmatter
+// block: a line of dashes only, surrounding whitespace aside
+func IsFrontmatterDelimiter(line []byte) bool {
+	line = bytes.TrimSpace(line)
+	return len(line) > 0 && len(bytes.Trim(line, "-")) == 0
+}
+
+// ParseFrontmatter parses the YAML text of a frontmatter block (without delimiters)
+func ParseFrontmatter(src []byte) (*Frontmatter, error) {
+	fm := NewFrontmatter()
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(src, &doc); err != nil {
+		return nil, err
+	}
+
+	// Empty or comment-only frontmatter
+	if len(doc.Content) == 0 {
+		fm.head = string(src)
+		return fm, nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, errors.New("frontmatter must be a mapping")
+	}
+
+	// A flow mapping ({a: 1, b: 2}) cannot be split into per-key source
+	// spans, so its entries are always re-encoded.
+	if root.Style&yaml.FlowStyle != 0 {
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			fm.entries = append(fm.entries, &frontmatterEntry{key: root.Content[i].Value, value: root.Content[i+1]})
+		}
+		return fm, nil
+	}
+
+	lines := strings.SplitAfter(string(src), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) > 0 && !strings.HasSuffix(lines[len(lines)-1], "\n") {
+		lines[len(lines)-1] += "\n"
+	}
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+
+		start := key.Line - 1
+		end := len(lines)
+		if i+2 < len(root.Content) {
+			end = root.Content[i+2].Line - 1
+		}
+
+		fm.entries = append(fm.entries, &frontmatterEntry{
+			key:   key.Value,
+			value: value,
+			raw:   strings.Join(lines[start:end], ""),
+		})
+	}
+
+	fm.head = strings.Join(lines[:root.Content[0].Line-1], "")
+
+	// Comment and blank lines between two entries belong to the next key, so
+	// that they stay in place even if the previous value is rewritten.
+	for i, e := range fm.entries {
+		trailing := trailingCommentLines(e.raw)
+		if trailing == "" {
+			continue
+		}
+		e.raw = strings.TrimSuffix(e.raw, trailing)
+		if i+1 < len(fm.entries) {
+			fm.entries[i+1].prefix = trailing
+		} else {
+			fm.tail = trailing
+		}
+	}
+
+	return fm, nil
+}
+
+// trailingCommentLines returns the trailing run of unindented comment and blank lines of s
+func trailingCommentLines(s string) string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	i := len(lines)
+	for i > 1 {
+		line := lines[i-1]
+		if strings.TrimSpace(line) != "" && !strings.HasPrefix(line, "#") {
+			break
+		}
+		i--
+	}
+	return strings.Join(lines[i:], "")
+}
+
+// Keys returns the top-level keys in their order of appearance
+func (f *Frontmatter) Keys() []string {
+	keys := make([]string, len(f.entries))
+	for i, e := range f.entries {
+		keys[i] = e.key
+	}
+	return keys
+}
+
+// Has reports whether key is present
+func (f *Frontmatter) Has(key string) bool {
+	return f.entry(key) != nil
+}
+
+// Get decodes the value of key into a generic Go value
+func (f *Frontmatter) Get(key string) (any, bool) {
+	e := f.entry(key)
+	if e == nil {
+		return nil, false
+	}
+	var v any
+	if err := e.value.Decode(&v); err != nil {
+		return nil, false
+	}
+	return v, true
+}
+
+// GetString returns the value of key if it is a scalar
+func (f *Frontmatter) GetString(key string) (string, bool) {
+	e := f.entry(key)
+	if e == nil || e.value.Kind != yaml.ScalarNode || e.value.Tag == "!!null" {
+		return "", false
+	}
+	return e.value.Value, true
+}
+
+// GetStrings returns the value of key if it is a sequence of scalars.
+// A single scalar is returned as a one-element slice.
+func (f *Frontmatter) GetStrings(key string) ([]string, bool) {
+	e := f.entry(key)
+	if e == nil {
+		return nil, false
+	}
+
+	switch e.value.Kind {
+	case yaml.ScalarNode:
+		if e.value.Tag == "!!null" {
+			return []string{}, true
+		}
+		return []string{e.value.Value}, true
+	case yaml.SequenceNode:
+		values := make([]string, 0, len(e.value.Content))
+		for _, c := range e.value.Content {
+			if c.Kind != yaml.ScalarNode {
+				return nil, false
+			}
+			values = append(values, c.Value)
+		}
+		return values, true
+	default:
+		return nil, false
+	}
+}
+
+// GetBool returns the value of key if it is a boolean
+func (f *Frontmatter) GetBool(key string) (bool, bool) {
+	e := f.entry(key)
+	if e == nil || e.value.Kind != yaml.ScalarNode {
+		return false, false
+	}
+	var v bool
+	if err := e.value.Decode(&v); err != nil {
+		return false, false
+	}
+	return v, true
+}
+
+// GetInt returns the value of key if it is an integer
+func (f *Frontmatter) GetInt(key string) (int, bool) {
+	e := f.entry(key)
+	if e == nil || e.value.Kind != yaml.ScalarNode {
+		return 0, false
+	}
+	var v int
+	if err := e.value.Decode(&v); err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+// SetString sets key to a scalar string. Existing keys keep their position,
+// new keys are appended.
+func (f *Frontmatter) SetString(key, value string) {
+	if current, ok := f.GetString(key); ok && current == value {
+		return
+	}
+	f.set(key, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value})
+}
+
+// SetStrings sets key to a flow sequence of double-quoted strings, e.g. ["a", "b"]
+func (f *Frontmatter) SetStrings(key string, values []string) {
+	if current, ok := f.GetStrings(key); ok && slices.Equal(current, values) {
+		return
+	}
+
+	seq := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle}
+	for _, v := range values {
+		seq.Content = append(seq.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v, Style: yaml.DoubleQuotedStyle})
+	}
+	f.set(key, seq)
+}
+
+// SetBool sets key to a boolean
+func (f *Frontmatter) SetBool(key string, value bool) {
+	if current, ok := f.GetBool(key); ok && current == value {
+		return
+	}
+	f.set(key, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(value)})
+}
+
+// SetInt sets key to an integer
+func (f *Frontmatter) SetInt(key string, value int) {
+	if current, ok := f.GetInt(key); ok && current == value {
+		return
+	}
+	f.set(key, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(value)})
+}
+
+// Delete removes key
+func (f *Frontmatter) Delete(key string) {
+	f.entries = slices.DeleteFunc(f.entries, func(e *frontmatterEntry) bool {
+		return e.key == key
+	})
+}
+
+// Clone returns a deep copy
+func (f *Frontmatter) Clone() *Frontmatter {
+	c := &Frontmatter{head: f.head, tail: f.tail}
+	for _, e := range f.entries {
+		ce := *e
+		ce.value = cloneNode(e.value)
+		c.entries = append(c.entries, &ce)
+	}
+	return c
+}
+
+// YAML returns the frontmatter as YAML text without delimiters
+func (f *Frontmatter) YAML() string {
+	var sb strings.Builder
+	sb.WriteString(f.head)
+	for _, e := range f.entries {
+		sb.WriteString(e.prefix)
+		if e.raw != "" {
+			sb.WriteString(e.raw)
+			continue
+		}
+		sb.WriteString(encodeEntry(e))
+	}
+	sb.WriteString(f.tail)
+	return sb.String()
+}
+
+func (f *Frontmatter) entry(key string) *frontmatterEntry {
+	for _, e := range f.entries {
+		if e.key == key {
+			return e
+		}
+	}
+	return nil
+}
+
+func (f *Frontmatter) set(key string, value *yaml.Node) {
+	if e := f.entry(key); e != nil {
+		e.value = value
+		e.raw = ""
+		return
+	}
+	f.entries = append(f.entries, &frontmatterEntry{key: key, value: value})
+}
+
+func encodeEntry(e *frontmatterEntry) string {
+	mapping := &yaml.Node{
+		Kind: yaml.MappingNode,
+		Content: []*yaml.Node{
+			{Kind: yaml.ScalarNode, Tag: "!!str", Value: e.key},
+			e.value,
+		},
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(mapping); err != nil {
+		return ""
+	}
+	_ = enc.Close()
+	return buf.String()
+}
+
+func cloneNode(n *yaml.Node) *yaml.Node {
+	if n == nil {
+		return nil
+	}
+	c := *n
+	c.Content = make([]*yaml.Node, len(n.Content))
+	for i, child := range n.Content {
+		c.Content[i] = cloneNode(child)
+	}
+	return &c
+}
@@ -0,0 +1,238 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitFrontmatter(t *testing.T) {
+	testCases := []struct {
+		name     string
+		source   string
+		wantYAML string
+		wantBody string
+		wantOk   bool
+	}{
+		{
+			name:     "frontmatter and body",
+			source:   "---\ncategory: [\"a\"]\n---\n\n# Title\n",
+			wantYAML: "category: [\"a\"]\n",
+			wantBody: "\n# Title\n",
+			wantOk:   true,
+		},
+		{
+			name:     "empty frontmatter",
+			source:   "---\n---\n# Title\n",
+			wantYAML: "",
+			wantBody: "# Title\n",
+			wantOk:   true,
+		},
+		{
+			name:     "no frontmatter",
+			source:   "# Title\n",
+			wantBody: "# Title\n",
+			wantOk:   false,
+		},
+		{
+			name:     "unterminated frontmatter",
+			source:   "---\ncategory: []\n# Title\n",
+			wantBody: "---\ncategory: []\n# Title\n",
+			wantOk:   false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			yamlText, body, ok := SplitFrontmatter([]byte(tc.source))
+			assert.Equal(t, tc.wantOk, ok)
+			assert.Equal(t, tc.wantYAML, string(yamlText))
+			assert.Equal(t, tc.wantBody, string(body))
+		})
+	}
+}
+
+func TestParseFrontmatter_RoundTrip(t *testing.T) {
+	testCases := []struct {
+		name string
+		src  string
+	}{
+		{name: "category only", src: "category: [\"work\", \"projects\"]\n"},
+		{name: "empty", src: ""},
+		{name: "comment only", src: "# nothing here\n"},
+		{
+			name: "unknown keys, comments and block values",
+			src: "# memo metadata\n" +
+				"category: [\"work\"]\n" +
+				"tags:\n" +
+				"  - client\n" +
+				"  - meeting\n" +
+				"\n" +
+				"# where this came from\n" +
+				"source: https://example.com/a?b=c\n" +
+				"status: draft   # trailing comment\n" +
+				"notes: |\n" +
+				"  line 1\n" +
+				"  line 2\n",
+		},
+		{name: "no trailing newline", src: "category: []\nstatus: open"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fm, err := ParseFrontmatter([]byte(tc.src))
+			require.NoError(t, err)
+
+			want := tc.src
+			if want != "" && want[len(want)-1] != '\n' {
+				want += "\n"
+			}
+			assert.Equal(t, want, fm.YAML())
+		})
+	}
+}
+
+func TestParseFrontmatter_Errors(t *testing.T) {
+	testCases := []struct {
+		name string
+		src  string
+	}{
+		{name: "malformed yaml", src: "category: [\"a\"\n"},
+		{name: "not a mapping", src: "- a\n- b\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseFrontmatter([]byte(tc.src))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestFrontmatter_Getters(t *testing.T) {
+	src := "category: work\n" +
+		"tags: [a, b]\n" +
+		"draft: true\n" +
+		"priority: 3\n" +
+		"empty:\n" +
+		"nested:\n" +
+		"  key: value\n"
+
+	fm, err := ParseFrontmatter([]byte(src))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"category", "tags", "draft", "priority", "empty", "nested"}, fm.Keys())
+	assert.True(t, fm.Has("tags"))
+	assert.False(t, fm.Has("missing"))
+
+	s, ok := fm.GetString("category")
+	assert.True(t, ok)
+	assert.Equal(t, "work", s)
+
+	ss, ok := fm.GetStrings("category")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"work"}, ss)
+
+	ss, ok = fm.GetStrings("tags")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"a", "b"}, ss)
+
+	ss, ok = fm.GetStrings("empty")
+	assert.True(t, ok)
+	assert.Empty(t, ss)
+
+	_, ok = fm.GetStrings("nested")
+	assert.False(t, ok)
+
+	b, ok := fm.GetBool("draft")
+	assert.True(t, ok)
+	assert.True(t, b)
+
+	n, ok := fm.GetInt("priority")
+	assert.True(t, ok)
+	assert.Equal(t, 3, n)
+
+	_, ok = fm.GetInt("tags")
+	assert.False(t, ok)
+
+	v, ok := fm.Get("nested")
+	assert.True(t, ok)
+	assert.Equal(t, map[string]any{"key": "value"}, v)
+}
+
+func TestFrontmatter_Setters(t *testing.T) {
+	src := "category: [\"old\"]\n" +
+		"# keep me\n" +
+		"tags:\n" +
+		"  - a\n" +
+		"status: draft\n"
+
+	testCases := []struct {
+		name   string
+		modify func(fm *Frontmatter)
+		want   string
+	}{
+		{
+			name:   "set same value keeps source",
+			modify: func(fm *Frontmatter) { fm.SetStrings("tags", []string{"a"}) },
+			want:   src,
+		},
+		{
+			name:   "replace value in place",
+			modify: func(fm *Frontmatter) { fm.SetStrings("category", []string{"new", "sub"}) },
+			want: "category: [\"new\", \"sub\"]\n" +
+				"# keep me\n" +
+				"tags:\n" +
+				"  - a\n" +
+				"status: draft\n",
+		},
+		{
+			name:   "rewrite value after comment",
+			modify: func(fm *Frontmatter) { fm.SetStrings("tags", []string{"x", "y"}) },
+			want: "category: [\"old\"]\n" +
+				"# keep me\n" +
+				"tags: [\"x\", \"y\"]\n" +
+				"status: draft\n",
+		},
+		{
+			name: "append new keys",
+			modify: func(fm *Frontmatter) {
+				fm.SetString("source", "web")
+				fm.SetBool("pinned", true)
+				fm.SetInt("priority", 2)
+			},
+			want: src + "source: web\npinned: true\npriority: 2\n",
+		},
+		{
+			name:   "delete key",
+			modify: func(fm *Frontmatter) { fm.Delete("status") },
+			want: "category: [\"old\"]\n" +
+				"# keep me\n" +
+				"tags:\n" +
+				"  - a\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fm, err := ParseFrontmatter([]byte(src))
+			require.NoError(t, err)
+
+			tc.modify(fm)
+			assert.Equal(t, tc.want, fm.YAML())
+		})
+	}
+}
+
+func TestFrontmatter_Clone(t *testing.T) {
+	fm, err := ParseFrontmatter([]byte("tags: [a]\nstatus: draft\n"))
+	require.NoError(t, err)
+
+	c := fm.Clone()
+	c.SetStrings("tags", []string{"b"})
+	c.Delete("status")
+
+	assert.Equal(t, "tags: [a]\nstatus: draft\n", fm.YAML())
+	assert.Equal(t, "tags: [\"b\"]\n", c.YAML())
+}
@@ -4,10 +4,11 @@ import "strings"
 
 // HeadingBlock represents a markdown heading block
 type HeadingBlock struct {
-	Level       int
-	HeadingText string
-	ContentText string
-	LineNumber  int // Line number of the heading in the original file
+	Level             int
+	HeadingText       string
+	ContentText       string
+	LineNumber        int // Line number of the heading in the original file
+	ContentLineNumber int // Line number of the first line of ContentText in the original file, 0 if there is none
 }
 
 // String returns the string representation of the heading block
@@ -0,0 +1,99 @@
+package markdown
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	// [text](dest) or [text](dest "title"), also matching images. Link text may hold one level of brackets.
+	inlineLinkRegex = regexp.MustCompile(`\[(?:[^\[\]\n]|\[[^\[\]\n]*\])*\]\([ \t]*(<[^<>\n]*>|[^\s()<>]+)(?:[ \t]+(?:"[^"\n]*"|'[^'\n]*'|\([^()\n]*\)))?[ \t]*\)`)
+	// [label]: dest
+	linkDefinitionRegex = regexp.MustCompile(`^ {0,3}\[[^\[\]\n]+\]:[ \t]*(<[^<>\n]*>|\S+)`)
+)
+
+// RewriteLinks replaces the destination of every inline link, image and link
+// reference definition in text for which rewrite returns true. Angle brackets
+// around a destination are kept. Links inside fenced code blocks and code spans
+// are left as is.
+func RewriteLinks(text string, rewrite func(dest string) (string, bool)) string {
+	return eachProseLine(text, func(i int, line, masked string) string {
+		var sb strings.Builder
+		last := 0
+		for _, m := range linkMatches(masked) {
+			start, stop := m[2], m[3]
+			dest := line[start:stop]
+			if strings.HasPrefix(dest, "<") {
+				start, stop = start+1, stop-1
+				dest = line[start:stop]
+			}
+
+			newDest, ok := rewrite(dest)
+			if !ok || newDest == dest {
+				continue
+			}
+			sb.WriteString(line[last:start])
+			sb.WriteString(newDest)
+			last = stop
+		}
+		if last == 0 {
+			return line
+		}
+		sb.WriteString(line[last:])
+		return sb.String()
+	})
+}
+
+// linkMatches returns the submatch indexes of the links in a line masked by
+// eachProseLine; the destination is submatch 1
+func linkMatches(masked string) [][]int {
+	if m := linkDefinitionRegex.FindStringSubmatchIndex(masked); m != nil {
+		return [][]int{m}
+	}
+	return inlineLinkRegex.FindAllStringSubmatchIndex(masked, -1)
+}
+
+// urlSchemeRegex matches the scheme of an absolute URL, such as https: or mailto:
+var urlSchemeRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*:`)
+
+// LinkDestination is the destination of an inline link, image or link
+// reference definition
+type LinkDestination struct {
+	Line int    // 0-based line in the text
+	Dest string // without angle brackets
+}
+
+// LinkDestinations returns the destinations of the links RewriteLinks would
+// rewrite in text, in order
+func LinkDestinations(text string) []LinkDestination {
+	var dests []LinkDestination
+	eachProseLine(text, func(i int, line, masked string) string {
+		for _, m := range linkMatches(masked) {
+			dest := line[m[2]:m[3]]
+			if strings.HasPrefix(dest, "<") {
+				dest = dest[1 : len(dest)-1]
+			}
+			dests = append(dests, LinkDestination{Line: i, Dest: dest})
+		}
+		return line
+	})
+	return dests
+}
+
+// RelativePath returns the file a destination relative to the linking file
+// points at, unescaped and without its fragment, in slash form. ok is false
+// for URLs, absolute paths and links within the same file.
+func (d LinkDestination) RelativePath() (path string, ok bool) {
+	if d.Dest == "" || strings.HasPrefix(d.Dest, "#") || strings.HasPrefix(d.Dest, "/") || urlSchemeRegex.MatchString(d.Dest) {
+		return "", false
+	}
+	p, _, _ := strings.Cut(d.Dest, "#")
+	if p == "" {
+		return "", false
+	}
+	if unescaped, err := url.PathUnescape(p); err == nil {
+		p = unescaped
+	}
+	return p, true
+}
@@ -0,0 +1,90 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRewriteLinks(t *testing.T) {
+	upper := func(dest string) (string, bool) {
+		if strings.HasPrefix(dest, "http") {
+			return "", false
+		}
+		return strings.ToUpper(dest), true
+	}
+
+	testCases := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "inline links and images",
+			text: "see [a](a.md) and ![img](pic.png \"title\") and [web](https://example.com)\n",
+			want: "see [a](A.MD) and ![img](PIC.PNG \"title\") and [web](https://example.com)\n",
+		},
+		{
+			name: "anchor, nested brackets and angle brackets",
+			text: "[x [y] z](dir/b.md#sec) [q](<c d.md>)",
+			want: "[x [y] z](DIR/B.MD#SEC) [q](<C D.MD>)",
+		},
+		{
+			name: "link reference definition",
+			text: "[ref]\n\n[ref]: ref.md \"title\"\n",
+			want: "[ref]\n\n[ref]: REF.MD \"title\"\n",
+		},
+		{
+			name: "code is left alone",
+			text: "`[a](a.md)`\n```\n[b](b.md)\n```\n[c](c.md)",
+			want: "`[a](a.md)`\n```\n[b](b.md)\n```\n[c](C.MD)",
+		},
+		{
+			name: "no links",
+			text: "[not a link] (a.md)\n",
+			want: "[not a link] (a.md)\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, RewriteLinks(tc.text, upper))
+		})
+	}
+}
+
+func TestLinkDestinations(t *testing.T) {
+	text := "---\ncategory: [\"a\"]\n---\n\n# t\n\n" +
+		"see [a](../a%20b.md#sec) and [web](https://example.com) and [top](#top)\n" +
+		"`[code](code.md)`\n" +
+		"[ref]: <c d.md>\n"
+
+	dests := LinkDestinations(text)
+	assert.Equal(t, []LinkDestination{
+		{Line: 6, Dest: "../a%20b.md#sec"},
+		{Line: 6, Dest: "https://example.com"},
+		{Line: 6, Dest: "#top"},
+		{Line: 8, Dest: "c d.md"},
+	}, dests)
+
+	var paths []string
+	for _, d := range dests {
+		if p, ok := d.RelativePath(); ok {
+			paths = append(paths, p)
+		}
+	}
+	assert.Equal(t, []string{"../a b.md", "c d.md"}, paths)
+}
+
+func TestRewriteWikiLinks(t *testing.T) {
+	text := "[[old]] and [[Old#part|label]] and [[other]]\n`[[old]]`\n"
+	got := RewriteWikiLinks(text, func(link WikiLink) (WikiLink, bool) {
+		if !strings.EqualFold(link.Target, "old") {
+			return link, false
+		}
+		link.Target = "new"
+		return link, true
+	})
+	assert.Equal(t, "[[new]] and [[new#part|label]] and [[other]]\n`[[old]]`\n", got)
+}
@@ -0,0 +1,49 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	taskRegex    = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s+\[([ xX])\]\s+(\S.*?)\s*$`)
+	headingRegex = regexp.MustCompile(`^ {0,3}#{1,6}\s+(.*?)\s*#*\s*$`)
+)
+
+// Task is a checkbox list item, "- [ ] write report" or "- [x] write report"
+type Task struct {
+	Text    string // The item without its checkbox
+	Done    bool
+	Section string // Heading the task is under, "" if there is none
+	Line    int    // Line number of the task within the parsed text, starting from 0
+	Column  int    // Column of Text within its line, in characters, starting from 0
+}
+
+// ParseTasks returns the checkbox list items of markdown text, in order.
+// Items inside fenced code blocks are ignored.
+func ParseTasks(text string) []Task {
+	var tasks []Task
+	section := ""
+
+	eachProseLine(text, func(i int, line, masked string) string {
+		if m := headingRegex.FindStringSubmatch(line); m != nil {
+			section = m[1]
+			return line
+		}
+		m := taskRegex.FindStringSubmatchIndex(line)
+		if m == nil {
+			return line
+		}
+		tasks = append(tasks, Task{
+			Text:    line[m[4]:m[5]],
+			Done:    !strings.EqualFold(line[m[2]:m[3]], " "),
+			Section: section,
+			Line:    i,
+			Column:  utf8.RuneCountInString(line[:m[4]]),
+		})
+		return line
+	})
+
+	return tasks
+}
@@ -0,0 +1,49 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTasks(t *testing.T) {
+	testCases := []struct {
+		name string
+		text string
+		want []Task
+	}{
+		{
+			name: "open and done",
+			text: "# 20250101Wed\n\n## todos\n\n- [ ] write report\n- [x] book flights\n",
+			want: []Task{
+				{Text: "write report", Section: "todos", Line: 4, Column: 6},
+				{Text: "book flights", Done: true, Section: "todos", Line: 5, Column: 6},
+			},
+		},
+		{
+			name: "nested, numbered and other markers",
+			text: "## wanttodos\n  * [X] 資料を読む\n1. [ ] call back  \n+ [ ] 会議",
+			want: []Task{
+				{Text: "資料を読む", Done: true, Section: "wanttodos", Line: 1, Column: 8},
+				{Text: "call back", Section: "wanttodos", Line: 2, Column: 7},
+				{Text: "会議", Section: "wanttodos", Line: 3, Column: 6},
+			},
+		},
+		{
+			name: "not tasks",
+			text: "- plain item\n- [ ]\n- [y] other\n-[ ] no space\n```\n- [ ] in fence\n```",
+			want: nil,
+		},
+		{
+			name: "before any heading",
+			text: "- [ ] loose",
+			want: []Task{{Text: "loose", Column: 6}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, ParseTasks(tc.text))
+		})
+	}
+}
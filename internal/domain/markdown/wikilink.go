@@ -0,0 +1,144 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+)
+
+var wikiLinkRegex = regexp.MustCompile(`\[\[([^\[\]\n]+?)\]\]`)
+
+// WikiLink represents a [[target]], [[target#heading]] or [[target|label]] link to another memo
+type WikiLink struct {
+	Target  string // Title or file name of the linked memo
+	Heading string // Heading within the linked memo, if any
+	Label   string // Display text, if any
+	Raw     string // The link as written, including brackets
+	Line    int    // Line number of the link within the parsed text, starting from 0
+}
+
+// String returns the link in its canonical [[target#heading|label]] form
+func (l WikiLink) String() string {
+	s := l.Target
+	if l.Heading != "" {
+		s += "#" + l.Heading
+	}
+	if l.Label != "" {
+		s += "|" + l.Label
+	}
+	return "[[" + s + "]]"
+}
+
+// ParseWikiLinks returns the wiki links found in markdown text.
+// Links inside fenced code blocks and code spans are ignored.
+func ParseWikiLinks(text string) []WikiLink {
+	var links []WikiLink
+
+	eachProseLine(text, func(i int, line, masked string) string {
+		for _, m := range wikiLinkRegex.FindAllStringSubmatchIndex(masked, -1) {
+			link := parseWikiLink(line[m[2]:m[3]])
+			link.Raw = line[m[0]:m[1]]
+			link.Line = i
+			links = append(links, link)
+		}
+		return line
+	})
+
+	return links
+}
+
+// RewriteWikiLinks replaces every wiki link in text for which rewrite returns true
+// with the returned link. Links inside fenced code blocks and code spans are left as is.
+func RewriteWikiLinks(text string, rewrite func(link WikiLink) (WikiLink, bool)) string {
+	return eachProseLine(text, func(i int, line, masked string) string {
+		var sb strings.Builder
+		last := 0
+		for _, m := range wikiLinkRegex.FindAllStringSubmatchIndex(masked, -1) {
+			link := parseWikiLink(line[m[2]:m[3]])
+			link.Raw = line[m[0]:m[1]]
+			link.Line = i
+			newLink, ok := rewrite(link)
+			if !ok {
+				continue
+			}
+			sb.WriteString(line[last:m[0]])
+			sb.WriteString(newLink.String())
+			last = m[1]
+		}
+		if last == 0 {
+			return line
+		}
+		sb.WriteString(line[last:])
+		return sb.String()
+	})
+}
+
+// parseWikiLink parses the inside of [[...]]
+func parseWikiLink(s string) WikiLink {
+	var link WikiLink
+	if target, label, ok := strings.Cut(s, "|"); ok {
+		s = target
+		link.Label = strings.TrimSpace(label)
+	}
+	if target, heading, ok := strings.Cut(s, "#"); ok {
+		s = target
+		link.Heading = strings.TrimSpace(heading)
+	}
+	link.Target = strings.TrimSpace(s)
+	return link
+}
+
+// eachProseLine calls fn for every line of text outside fenced code blocks and
+// returns text with those lines replaced by what fn returns. masked is the line
+// with code spans blanked out, so offsets into it are valid offsets into line.
+func eachProseLine(text string, fn func(i int, line, masked string) string) string {
+	lines := strings.Split(text, "\n")
+
+	var fence string
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " ")
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+
+		lines[i] = fn(i, line, stripCodeSpans(line))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// stripCodeSpans replaces code spans in line with spaces, keeping byte offsets intact
+func stripCodeSpans(line string) string {
+	b := []byte(line)
+	for i := 0; i < len(b); {
+		if b[i] != '`' {
+			i++
+			continue
+		}
+
+		// Opening backtick run
+		n := 0
+		for i+n < len(b) && b[i+n] == '`' {
+			n++
+		}
+		delim := strings.Repeat("`", n)
+		end := strings.Index(string(b[i+n:]), delim)
+		if end < 0 {
+			i += n
+			continue
+		}
+
+		stop := i + n + end + n
+		for j := i; j < stop; j++ {
+			b[j] = ' '
+		}
+		i = stop
+	}
+	return string(b)
+}
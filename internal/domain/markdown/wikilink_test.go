@@ -0,0 +1,55 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWikiLinks(t *testing.T) {
+	testCases := []struct {
+		name string
+		text string
+		want []WikiLink
+	}{
+		{
+			name: "title",
+			text: "see [[meeting notes]] for details",
+			want: []WikiLink{{Target: "meeting notes", Raw: "[[meeting notes]]"}},
+		},
+		{
+			name: "title with heading and label",
+			text: "intro\n[[ meeting notes # agenda | the agenda ]] and [[other]]",
+			want: []WikiLink{
+				{Target: "meeting notes", Heading: "agenda", Label: "the agenda", Raw: "[[ meeting notes # agenda | the agenda ]]", Line: 1},
+				{Target: "other", Raw: "[[other]]", Line: 1},
+			},
+		},
+		{
+			name: "ignores code",
+			text: "`[[in span]]` and ``[[double `span`]]``\n```\n[[in fence]]\n```\n~~~md\n[[in tilde fence]]\n~~~\n[[after]]",
+			want: []WikiLink{{Target: "after", Raw: "[[after]]", Line: 7}},
+		},
+		{
+			name: "unterminated code span",
+			text: "a ` b [[link]]",
+			want: []WikiLink{{Target: "link", Raw: "[[link]]"}},
+		},
+		{
+			name: "no links",
+			text: "[single](brackets.md) and [[]] and [[broken\n]]",
+			want: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, ParseWikiLinks(tc.text))
+		})
+	}
+}
+
+func TestWikiLink_String(t *testing.T) {
+	assert.Equal(t, "[[a]]", WikiLink{Target: "a"}.String())
+	assert.Equal(t, "[[a#h|l]]", WikiLink{Target: "a", Heading: "h", Label: "l"}.String())
+}
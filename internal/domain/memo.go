@@ -2,12 +2,13 @@ package domain
 
 import (
 	"errors"
-	"fmt"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/hirotoni/memov2/internal/domain/markdown"
 	"github.com/hirotoni/memov2/internal/interfaces"
 )
 
@@ -16,6 +17,16 @@ const (
 	FileNameDateTimeRegexMemo = `^\d{8}\S{3}\d{6}`
 	FileNameRegexMemo         = `^\d{8}\S{3}\d{6}_memo_.*\.md$`
 	FileNameExtractRegexMemo  = `^\d{8}\S{3}\d{6}_memo_(.*)\.md$`
+
+	// MetaKeyCategory is the frontmatter key holding the category tree
+	MetaKeyCategory = "category"
+
+	// MetaKeyTags is the frontmatter key holding the tags
+	MetaKeyTags = "tags"
+
+	// MetaKeyID is the frontmatter key holding the memo's stable ID, which
+	// survives renames and moves
+	MetaKeyID = "id"
 )
 
 // MemoFileInterface is an alias for interfaces.MemoFileInterface to maintain backward compatibility
@@ -23,7 +34,8 @@ type MemoFileInterface = interfaces.MemoFileInterface
 
 type MemoFile struct {
 	file
-	categoryTree []string // tree structure for memo files
+	categoryTree []string              // tree structure for memo files
+	frontmatter  *markdown.Frontmatter // all frontmatter keys, including ones unknown to memov2
 }
 
 func NewMemoFile(date time.Time, title string, categoryTree []string) (MemoFileInterface, error) {
@@ -35,7 +47,7 @@ func NewMemoFile(date time.Time, title string, categoryTree []string) (MemoFileI
 		file: file{
 			date:     date,
 			fileType: FileTypeMemo,
-			title:    title,
+			title:    SanitizeTitle(title),
 		},
 		categoryTree: categoryTree,
 	}
@@ -53,8 +65,114 @@ func (f *MemoFile) FileName() string {
 	return filename + FileExtension
 }
 
-func (f *MemoFile) CategoryTree() []string        { return f.categoryTree }
-func (f *MemoFile) SetCategoryTree(tree []string) { f.categoryTree = tree }
+func (f *MemoFile) CategoryTree() []string { return f.categoryTree }
+
+// SetCategoryTree sets the category tree and the category key with it
+func (f *MemoFile) SetCategoryTree(tree []string) {
+	f.categoryTree = tree
+	f.Frontmatter().SetStrings(MetaKeyCategory, tree)
+}
+
+// Frontmatter returns the memo's frontmatter. Reading it leaves the memo as it
+// is; the category key is brought in line with CategoryTree when the category
+// tree is set and when the memo is written.
+func (f *MemoFile) Frontmatter() *markdown.Frontmatter {
+	if f.frontmatter == nil {
+		// A new memo's frontmatter starts with its category
+		f.frontmatter = markdown.NewFrontmatter()
+		f.frontmatter.SetStrings(MetaKeyCategory, f.categoryTree)
+	}
+	return f.frontmatter
+}
+
+// SetFrontmatter replaces the frontmatter. The category tree is taken from it if present.
+func (f *MemoFile) SetFrontmatter(fm *markdown.Frontmatter) {
+	f.frontmatter = fm
+	if fm == nil {
+		return
+	}
+	if category, ok := fm.GetStrings(MetaKeyCategory); ok {
+		f.categoryTree = category
+	}
+}
+
+// ID returns the memo's stable ID, "" if it has none
+func (f *MemoFile) ID() string {
+	id, _ := f.Frontmatter().GetString(MetaKeyID)
+	return id
+}
+
+// SetID sets the memo's stable ID
+func (f *MemoFile) SetID(id string) { f.Frontmatter().SetString(MetaKeyID, id) }
+
+// Tags returns the tags of the tags key. Both a list and a comma separated
+// string are accepted; a leading # is dropped and duplicates are removed.
+func (f *MemoFile) Tags() []string {
+	values, _ := f.Frontmatter().GetStrings(MetaKeyTags)
+	var tags []string
+	for _, v := range values {
+		tags = append(tags, strings.Split(v, ",")...)
+	}
+	return NormalizeTags(tags)
+}
+
+// SetTags replaces the tags. The tags key is removed when tags is empty.
+func (f *MemoFile) SetTags(tags []string) {
+	tags = NormalizeTags(tags)
+	if len(tags) == 0 {
+		f.Frontmatter().Delete(MetaKeyTags)
+		return
+	}
+	f.Frontmatter().SetStrings(MetaKeyTags, tags)
+}
+
+// NormalizeTags trims the tags and drops a leading #, empty tags and duplicates
+func NormalizeTags(tags []string) []string {
+	var normalized []string
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		tag = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+func (f *MemoFile) MetaString(key string) (string, bool)    { return f.Frontmatter().GetString(key) }
+func (f *MemoFile) MetaStrings(key string) ([]string, bool) { return f.Frontmatter().GetStrings(key) }
+func (f *MemoFile) MetaBool(key string) (bool, bool)        { return f.Frontmatter().GetBool(key) }
+func (f *MemoFile) MetaInt(key string) (int, bool)          { return f.Frontmatter().GetInt(key) }
+
+func (f *MemoFile) SetMetaString(key, value string) {
+	if key == MetaKeyCategory {
+		f.SetCategoryTree([]string{value})
+		return
+	}
+	f.Frontmatter().SetString(key, value)
+}
+
+func (f *MemoFile) SetMetaStrings(key string, values []string) {
+	if key == MetaKeyCategory {
+		f.SetCategoryTree(values)
+		return
+	}
+	f.Frontmatter().SetStrings(key, values)
+}
+
+func (f *MemoFile) SetMetaBool(key string, value bool) { f.Frontmatter().SetBool(key, value) }
+func (f *MemoFile) SetMetaInt(key string, value int)   { f.Frontmatter().SetInt(key, value) }
+
+// DeleteMeta removes key from the frontmatter. The category key cannot be removed; it is emptied instead.
+func (f *MemoFile) DeleteMeta(key string) {
+	if key == MetaKeyCategory {
+		f.SetCategoryTree([]string{})
+		return
+	}
+	f.Frontmatter().Delete(key)
+}
 
 func (f *MemoFile) Location() string {
 	if len(f.categoryTree) == 0 {
@@ -74,15 +192,71 @@ func MemoTitle(filename string) string {
 	}
 }
 
+// titleSanitizer replaces the characters a title cannot hold in a file name
+var titleSanitizer = strings.NewReplacer("/", " ", "\\", " ", ":", " ")
+
+// SanitizeTitle replaces path separators and colons in title with spaces, so
+// that it can be written in a file name: "Q1: plan/review" becomes
+// "Q1 plan review"
+func SanitizeTitle(title string) string {
+	if !strings.ContainsAny(title, "/\\:") {
+		return title
+	}
+	return strings.Join(strings.Fields(titleSanitizer.Replace(title)), " ")
+}
+
+// TitlesMatch reports whether two titles make the same file name, as the
+// title in a memo's file name and its H1 do when spaces are written as fillers
+func TitlesMatch(a, b string) bool {
+	fileTitle := func(s string) string {
+		return strings.ReplaceAll(SanitizeTitle(strings.TrimSpace(s)), " ", FileFiller)
+	}
+	return fileTitle(a) == fileTitle(b)
+}
+
+// SyncedTitle returns the title to rename m to when the title in its file name
+// and its H1 disagree: the H1's if fromHeading, otherwise the file name's with
+// fillers read as spaces. m must be read in full. ok is false if the titles
+// agree or m has no H1.
+func SyncedTitle(m MemoFileInterface, fromHeading bool) (title string, ok bool) {
+	tl := m.TopLevelBodyContent()
+	if tl == nil || strings.TrimSpace(tl.HeadingText) == "" || TitlesMatch(tl.HeadingText, m.Title()) {
+		return "", false
+	}
+	if fromHeading {
+		return SanitizeTitle(strings.TrimSpace(tl.HeadingText)), true
+	}
+	return strings.ReplaceAll(m.Title(), FileFiller, " "), true
+}
+
+// SetTitle sets the title, and the H1 with it
+func (f *MemoFile) SetTitle(title string) {
+	f.file.SetTitle(title)
+	if tl := f.topLevelBodyContent; tl != nil && strings.TrimSpace(tl.HeadingText) != "" {
+		h1 := *tl
+		h1.HeadingText = title
+		f.topLevelBodyContent = &h1
+	}
+}
+
+// heading returns the H1 to write: the one the memo was read with, which may
+// differ from the title in its file name, or else the title
+func (f *MemoFile) heading() string {
+	if tl := f.topLevelBodyContent; tl != nil && strings.TrimSpace(tl.HeadingText) != "" {
+		return strings.TrimSpace(tl.HeadingText)
+	}
+	return f.Title()
+}
+
 // ContentString returns memo file content including metadata, title, body, and headings.
 // Order:
-//  1. YAML frontmatter with category
+//  1. YAML frontmatter (category and any other keys)
 //  2. Title
 //  3. Top-level body content
 //  4. Heading blocks
 func (f *MemoFile) ContentString() string {
 	meta := f.MetadataString()
-	rest := f.file.ContentString()
+	rest := f.file.contentString(f.heading())
 	// Concatenate metadata and common body
 	result := meta + rest
 
@@ -98,24 +272,20 @@ func (f *MemoFile) ContentString() string {
 
 func (f *MemoFile) MetadataString() string {
 	const (
-		header         = "---\n"
-		categoryPrefix = "category: "
-		footer         = "\n---\n\n"
+		header = markdown.FrontmatterDelimiter + "\n"
+		footer = markdown.FrontmatterDelimiter + "\n\n"
 	)
 
-	sb := strings.Builder{}
-	sb.WriteString(header)
-	sb.WriteString(categoryPrefix)
-
-	sb.WriteString("[")
-	for i, v := range f.CategoryTree() {
-		if i > 0 {
-			sb.WriteString(", ")
-		}
-		sb.WriteString(fmt.Sprintf("%q", v))
+	// Written with the category key of CategoryTree, leaving the memo as it is
+	fm := f.Frontmatter()
+	if category, ok := fm.GetStrings(MetaKeyCategory); !ok || !slices.Equal(category, f.categoryTree) {
+		fm = fm.Clone()
+		fm.SetStrings(MetaKeyCategory, f.categoryTree)
 	}
-	sb.WriteString("]")
 
+	sb := strings.Builder{}
+	sb.WriteString(header)
+	sb.WriteString(fm.YAML())
 	sb.WriteString(footer)
 	return sb.String()
 }
@@ -15,6 +15,7 @@ func MemoFileFromParsedData(
 	date time.Time,
 	title string,
 	category []string,
+	frontmatter *markdown.Frontmatter,
 	topLevelBodyContent *markdown.HeadingBlock,
 	headingBlocks []*markdown.HeadingBlock,
 ) (interfaces.MemoFileInterface, error) {
@@ -32,8 +33,8 @@ func MemoFileFromParsedData(
 			headingBlocks:       headingBlocks,
 		},
 		categoryTree: category,
+		frontmatter:  frontmatter,
 	}
 
 	return mf, nil
 }
-
@@ -304,3 +304,111 @@ context 2
 		})
 	}
 }
+
+func TestMemoFile_Meta(t *testing.T) {
+	fm, err := markdown.ParseFrontmatter([]byte("category: [\"a\"]\ntags: [x, y]\ndraft: true\n"))
+	require.NoError(t, err)
+
+	f, err := NewMemoFile(time.Now(), "title", []string{"a"})
+	require.NoError(t, err)
+	f.SetFrontmatter(fm)
+
+	tags, ok := f.MetaStrings("tags")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"x", "y"}, tags)
+
+	draft, ok := f.MetaBool("draft")
+	assert.True(t, ok)
+	assert.True(t, draft)
+
+	// category is kept in sync with the category tree
+	f.SetMetaStrings(MetaKeyCategory, []string{"b", "c"})
+	assert.Equal(t, []string{"b", "c"}, f.CategoryTree())
+
+	f.SetCategoryTree([]string{"d"})
+	f.SetMetaInt("priority", 1)
+	f.DeleteMeta("draft")
+	assert.Equal(t, "---\ncategory: [\"d\"]\ntags: [x, y]\npriority: 1\n---\n\n", f.(*MemoFile).MetadataString())
+}
+
+func TestMemoFile_FrontmatterReadOnly(t *testing.T) {
+	yamlText := "id: 01JKZ3Q8W5XG7M2N4P6R8T0V9B\ntags: [x]\n"
+	fm, err := markdown.ParseFrontmatter([]byte(yamlText))
+	require.NoError(t, err)
+	f, err := MemoFileFromParsedData(time.Now(), "title", nil, fm, nil, nil)
+	require.NoError(t, err)
+
+	// Reading leaves the frontmatter as it was
+	f.ID()
+	f.Tags()
+	f.MetaString("draft")
+	assert.Equal(t, yamlText, f.Frontmatter().YAML())
+
+	// Writing adds the category key
+	assert.Equal(t, "---\n"+yamlText+"category: []\n---\n\n", f.(*MemoFile).MetadataString())
+	assert.Equal(t, yamlText, f.Frontmatter().YAML())
+
+	// Setting the category tree updates the key
+	f.SetCategoryTree([]string{"work"})
+	assert.Equal(t, yamlText+"category: [\"work\"]\n", f.Frontmatter().YAML())
+}
+
+func TestMemoFile_Tags(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want []string
+	}{
+		{"list", "tags: [client, meeting]\n", []string{"client", "meeting"}},
+		{"comma separated", "tags: \"client, #meeting\"\n", []string{"client", "meeting"}},
+		{"duplicates and blanks", "tags: [\"#client\", client, \" \"]\n", []string{"client"}},
+		{"missing", "draft: true\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fm, err := markdown.ParseFrontmatter([]byte(tt.yaml))
+			require.NoError(t, err)
+			f, err := NewMemoFile(time.Now(), "title", nil)
+			require.NoError(t, err)
+			f.SetFrontmatter(fm)
+			assert.Equal(t, tt.want, f.Tags())
+		})
+	}
+
+	f, err := NewMemoFile(time.Now(), "title", []string{})
+	require.NoError(t, err)
+	f.SetTags([]string{"#client", "meeting", "client"})
+	assert.Equal(t, "---\ncategory: []\ntags: [\"client\", \"meeting\"]\n---\n\n", f.(*MemoFile).MetadataString())
+	f.SetTags(nil)
+	assert.Equal(t, "---\ncategory: []\n---\n\n", f.(*MemoFile).MetadataString())
+}
+
+func TestSyncedTitle(t *testing.T) {
+	assert.Equal(t, "Q1 plan review", SanitizeTitle("Q1: plan/review"))
+	assert.Equal(t, " kept  as is ", SanitizeTitle(" kept  as is "))
+
+	tests := []struct {
+		name        string
+		fileTitle   string
+		heading     string
+		fromHeading bool
+		want        string
+		wantOK      bool
+	}{
+		{"spaces match fillers", "weekly-sync", "weekly sync", true, "", false},
+		{"sanitized heading matches", "Q1-plan", "Q1: plan", true, "", false},
+		{"from heading", "draft", "final: v2", true, "final v2", true},
+		{"from file name", "weekly-sync", "draft", false, "weekly sync", true},
+		{"no heading", "draft", "", true, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewMemoFile(time.Now(), tt.fileTitle, nil)
+			require.NoError(t, err)
+			f.SetTopLevelBodyContent(&markdown.HeadingBlock{HeadingText: tt.heading, Level: 1})
+			got, ok := SyncedTitle(f, tt.fromHeading)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -1,5 +1,7 @@
 package interfaces
 
+import "github.com/hirotoni/memov2/internal/config"
+
 // ConfigProvider abstracts configuration access to reduce coupling
 type ConfigProvider interface {
 	BaseDir() string
@@ -8,6 +10,14 @@ type ConfigProvider interface {
 	TodosDaysToSeek() int
 	Editor() string
 	EditorArgs() []string
+	SearchUserDictionary() string
+	SearchDictionaries() []string
+	SearchTransliterators() []string
+	SearchHistory() string
+	SavedSearches() []config.SavedSearch
+	SavedSearch(name string) (config.SavedSearch, bool)
+	TitleSource() string
+	TitleSync() string
 	ConfigDirPath() (string, string, error)
 	// GetTomlConfig returns the underlying TomlConfig for cases where it's needed
 	// This method should be used sparingly and only when absolutely necessary
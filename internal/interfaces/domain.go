@@ -22,6 +22,46 @@ func (ft FileType) String() string { return string(ft) }
 // The concrete implementation is in the domain layer
 type HeadingBlock = domainmarkdown.HeadingBlock
 
+// Frontmatter is an alias for domain.markdown.Frontmatter
+// The concrete implementation is in the domain layer
+type Frontmatter = domainmarkdown.Frontmatter
+
+// WikiLink is an alias for domain.markdown.WikiLink
+// The concrete implementation is in the domain layer
+type WikiLink = domainmarkdown.WikiLink
+
+// Backlink is a wiki link in Source that points to another memo
+type Backlink struct {
+	Source  MemoFileInterface
+	Link    WikiLink
+	Section string // Heading of the section containing the link, empty for the top-level body
+}
+
+// LinkRewrite is a change to a file that keeps its links pointing at a memo being renamed or moved
+type LinkRewrite struct {
+	Path   string // Path of the changed file, relative to the memos directory
+	Before string
+	After  string
+}
+
+// Relocation is a planned rename or move of a memo, applied as planned by
+// MemoRepo.ApplyRelocation
+type Relocation interface {
+	LinkRewrites() []LinkRewrite
+}
+
+// MemoRename is a memo to rename and its new title
+type MemoRename struct {
+	File     MemoFileInterface
+	NewTitle string
+}
+
+// TagCount is a tag and the number of memos tagged with it
+type TagCount struct {
+	Tag   string
+	Count int
+}
+
 // FileInterface defines the interface for file operations
 type FileInterface interface {
 	// Meta data
@@ -56,6 +96,25 @@ type MemoFileInterface interface {
 	CategoryTree() []string
 	SetCategoryTree(tree []string)
 	Location() string
+	ID() string
+	SetID(id string)
+	Tags() []string
+	SetTags(tags []string)
+
+	// Frontmatter
+	Frontmatter() *Frontmatter
+	SetFrontmatter(fm *Frontmatter)
+	MetaString(key string) (string, bool)
+	MetaStrings(key string) ([]string, bool)
+	MetaBool(key string) (bool, bool)
+	MetaInt(key string) (int, bool)
+
+	// Frontmatter modification
+	SetMetaString(key, value string)
+	SetMetaStrings(key string, values []string)
+	SetMetaBool(key string, value bool)
+	SetMetaInt(key string, value int)
+	DeleteMeta(key string)
 }
 
 // TodoFileInterface defines the interface for todo file operations
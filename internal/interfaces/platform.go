@@ -4,7 +4,17 @@ import "os"
 
 // Editor defines the interface for editor operations
 type Editor interface {
+	// Open opens path at its first line
 	Open(basedir, path string) error
+	// OpenAt opens path at pos
+	OpenAt(basedir, path string, pos Position) error
+}
+
+// Position is a 1-based line and column in a file. Zero values mean the first
+// line or column.
+type Position struct {
+	Line   int
+	Column int
 }
 
 // FileSystem defines the interface for file system operations
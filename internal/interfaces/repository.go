@@ -13,14 +13,23 @@ type Repositories interface {
 // MemoRepo defines the interface for memo repository operations
 type MemoRepo interface {
 	MemoEntries() ([]MemoFileInterface, error)
+	MemoHeaders() ([]MemoFileInterface, error)
 	Metadata(file MemoFileInterface) (map[string]interface{}, error)
 	Save(file MemoFileInterface, truncate bool) error
+	SetMetadata(file MemoFileInterface, key, value string) error
 	Categories() ([][]string, error)
+	Tags() ([]TagCount, error)
 	Move(file MemoFileInterface, newCategoryTree []string) error
+	PlanMove(file MemoFileInterface, newCategoryTree []string) (Relocation, error)
 	Delete(file MemoFileInterface) error
 	Rename(file MemoFileInterface, newTitle string) error
+	PlanRename(file MemoFileInterface, newTitle string) (Relocation, error)
+	ApplyRelocation(plan Relocation) error
+	RenameAll(renames []MemoRename) (int, error)
 	Duplicate(file MemoFileInterface) (MemoFileInterface, error)
 	Memo(file MemoFileInterface) (MemoFileInterface, error)
+	MemoByID(id string) (MemoFileInterface, error)
+	Backlinks(file MemoFileInterface) ([]Backlink, error)
 }
 
 // TodoRepo defines the interface for todo repository operations
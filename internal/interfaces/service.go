@@ -5,22 +5,30 @@ type Services interface {
 	Memo() MemoService
 	Todo() TodoService
 	Config() ConfigService
+	Doctor() DoctorService
 }
 
 // MemoService defines the interface for memo service operations
 type MemoService interface {
 	BuildWeeklyReportMemos() error
-	GenerateMemoFile(title string, categoryTree []string) error
+	GenerateMemoFile(title string, categoryTree []string, tags []string) error
 	ListCategories() error
+	ListTags() error
 	GenerateMemoIndex() error
 	Browse() error
-	List(showFullPath bool) error
+	List(showFullPath bool, tags []string) error
 	Open(path string) error
 	Rename(path string, newTitle string) error
+	Backlinks(path string) error
+	Search(query, category, format, order string) error
+	SearchSaved(name, category, format, order string) error
+	AddDictionaryWord(reading, word string) error
+	BackfillIDs() error
 	TidyMemos() error
+	Tidy(dryRun bool) error
 
 	// Interactive embedded-TUI commands (memos search/rename/new).
-	SearchInteractive() error
+	SearchInteractive(category string) error
 	RenameInteractive() error
 	NewInteractive() error
 }
@@ -31,6 +39,11 @@ type TodoService interface {
 	BuildWeeklyReportTodos() error
 }
 
+// DoctorService defines the interface for vault consistency checks
+type DoctorService interface {
+	Check(fix bool, format string) error
+}
+
 // ConfigService defines the interface for config service operations
 type ConfigService interface {
 	Show()
@@ -3,6 +3,7 @@ package platform
 import (
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 
 	"github.com/hirotoni/memov2/internal/common"
@@ -19,7 +20,13 @@ type DefaultEditor struct {
 }
 
 func (eo DefaultEditor) Open(basedir, path string) error {
-	args := eo.buildArgs(basedir, path)
+	return eo.OpenAt(basedir, path, interfaces.Position{})
+}
+
+// OpenAt runs the editor with the args template filled in: {basedir}, {path},
+// and {line} and {column} of pos
+func (eo DefaultEditor) OpenAt(basedir, path string, pos interfaces.Position) error {
+	args := eo.buildArgs(basedir, path, pos)
 	cmd := exec.Command(eo.command, args...)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
@@ -30,11 +37,14 @@ func (eo DefaultEditor) Open(basedir, path string) error {
 	return nil
 }
 
-func (eo DefaultEditor) buildArgs(basedir, path string) []string {
+func (eo DefaultEditor) buildArgs(basedir, path string, pos interfaces.Position) []string {
+	line, column := max(pos.Line, 1), max(pos.Column, 1)
 	args := make([]string, len(eo.argsTemplate))
 	for i, t := range eo.argsTemplate {
 		t = strings.ReplaceAll(t, "{basedir}", basedir)
 		t = strings.ReplaceAll(t, "{path}", path)
+		t = strings.ReplaceAll(t, "{line}", strconv.Itoa(line))
+		t = strings.ReplaceAll(t, "{column}", strconv.Itoa(column))
 		args[i] = t
 	}
 	return args
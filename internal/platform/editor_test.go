@@ -20,6 +20,7 @@ func TestDefaultEditor_BuildArgs(t *testing.T) {
 		template []string
 		basedir  string
 		path     string
+		pos      interfaces.Position
 		want     []string
 	}{
 		{
@@ -43,11 +44,34 @@ func TestDefaultEditor_BuildArgs(t *testing.T) {
 			path:     "/tmp/test.txt",
 			want:     []string{"-c", "Neotree reveal", "/tmp/test.txt"},
 		},
+		{
+			name:     "vim at line",
+			template: []string{"+{line}", "{path}"},
+			basedir:  "/tmp",
+			path:     "/tmp/test.txt",
+			pos:      interfaces.Position{Line: 12, Column: 5},
+			want:     []string{"+12", "/tmp/test.txt"},
+		},
+		{
+			name:     "vscode at line and column",
+			template: []string{"--goto", "{path}:{line}:{column}"},
+			basedir:  "/tmp",
+			path:     "/tmp/test.txt",
+			pos:      interfaces.Position{Line: 12, Column: 5},
+			want:     []string{"--goto", "/tmp/test.txt:12:5"},
+		},
+		{
+			name:     "unknown position is the first line",
+			template: []string{"+call cursor({line},{column})", "{path}"},
+			basedir:  "/tmp",
+			path:     "/tmp/test.txt",
+			want:     []string{"+call cursor(1,1)", "/tmp/test.txt"},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			e := DefaultEditor{command: "editor", argsTemplate: tt.template}
-			got := e.buildArgs(tt.basedir, tt.path)
+			got := e.buildArgs(tt.basedir, tt.path, tt.pos)
 			assert.Equal(t, tt.want, got)
 		})
 	}
@@ -87,6 +87,51 @@ func WriteFileStream(path string, truncate bool, write func(w *bufio.Writer) err
 	return nil
 }
 
+// WriteFileAtomic writes data to path by writing a temporary file next to it and
+// renaming it into place, so the file never holds partial content.
+// The parent directory is created if missing. An existing file keeps its
+// permissions; a new one is created with 0644.
+func WriteFileAtomic(path string, data []byte) error {
+	if path == "" {
+		return common.New(common.ErrorTypeFileSystem, "path is empty")
+	}
+
+	dir := filepath.Dir(path)
+	if err := EnsureDir(dir); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return common.Wrap(err, common.ErrorTypeFileSystem, fmt.Sprintf("failed to create temporary file for: %s", path))
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return common.Wrap(err, common.ErrorTypeFileSystem, fmt.Sprintf("failed to write temporary file for: %s", path))
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return common.Wrap(err, common.ErrorTypeFileSystem, fmt.Sprintf("failed to close temporary file for: %s", path))
+	}
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.Chmod(tmpPath, mode); err != nil {
+		_ = os.Remove(tmpPath)
+		return common.Wrap(err, common.ErrorTypeFileSystem, fmt.Sprintf("failed to set permissions for: %s", path))
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return common.Wrap(err, common.ErrorTypeFileSystem, fmt.Sprintf("failed to replace file: %s", path))
+	}
+
+	return nil
+}
+
 // ReadDir reads the directory named by dirname and returns
 // a list of directory entries sorted by filename.
 func ReadDir(dirname string) ([]os.DirEntry, error) {
@@ -234,3 +234,50 @@ func TestWriteFileStream_LargeContent(t *testing.T) {
 	assert.Greater(t, info.Size(), int64(100000), "File should be large")
 }
 
+
+func TestWriteFileAtomic_CreateAndReplace(t *testing.T) {
+	// Setup
+	dir := t.TempDir()
+	path := filepath.Join(dir, "nested", "test.md")
+
+	// Execute
+	require.NoError(t, WriteFileAtomic(path, []byte("first")))
+	require.NoError(t, WriteFileAtomic(path, []byte("second")))
+
+	// Assert
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(content))
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "temporary files should not be left behind")
+}
+
+func TestWriteFileAtomic_EmptyPath(t *testing.T) {
+	// Execute
+	err := WriteFileAtomic("", []byte("content"))
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func TestWriteFileAtomic_KeepsMode(t *testing.T) {
+	// Setup
+	dir := t.TempDir()
+	private := filepath.Join(dir, "private.md")
+	require.NoError(t, os.WriteFile(private, []byte("first"), 0o600))
+	require.NoError(t, os.Chmod(private, 0o600))
+
+	// Execute
+	require.NoError(t, WriteFileAtomic(private, []byte("second")))
+	require.NoError(t, WriteFileAtomic(filepath.Join(dir, "new.md"), []byte("new")))
+
+	// Assert
+	info, err := os.Stat(private)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	info, err = os.Stat(filepath.Join(dir, "new.md"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o644), info.Mode().Perm())
+}
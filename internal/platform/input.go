@@ -11,13 +11,27 @@ import (
 	"golang.org/x/term"
 )
 
+// TTY is the terminal ReadLine asks on
+var TTY = "/dev/tty"
+
+// IsInteractive reports whether the process has a terminal, so that the user
+// can be asked with ReadLine. Like ReadLine it tests TTY, not stdin.
+func IsInteractive() bool {
+	tty, err := os.OpenFile(TTY, os.O_RDWR, 0)
+	if err != nil {
+		return false
+	}
+	defer tty.Close()
+	return term.IsTerminal(int(tty.Fd()))
+}
+
 // ReadLine reads a line interactively from the terminal.
-// It opens /dev/tty directly so that it works even when stdin is a pipe.
+// It opens TTY directly so that it works even when stdin is a pipe.
 // Uses raw mode to correctly handle backspace for multibyte characters.
 func ReadLine(prompt string) (string, error) {
-	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	tty, err := os.OpenFile(TTY, os.O_RDWR, 0)
 	if err != nil {
-		return "", common.Wrap(err, common.ErrorTypeService, "failed to open /dev/tty")
+		return "", common.Wrap(err, common.ErrorTypeService, "failed to open "+TTY)
 	}
 	defer tty.Close()
 
@@ -1,9 +1,13 @@
 package common
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"regexp"
+	"sync"
 	"time"
 
 	"github.com/hirotoni/memov2/internal/common"
@@ -46,6 +50,41 @@ func ReadMarkdownFile(path string) ([]byte, error) {
 	return b, nil
 }
 
+// ReadFrontmatter reads and parses the frontmatter block a markdown file starts
+// with, stopping at its closing delimiter so that the body is never read. A file
+// without a frontmatter block yields an empty Frontmatter.
+func ReadFrontmatter(path string) (*markdown.Frontmatter, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, common.Wrap(err, common.ErrorTypeFileSystem, fmt.Sprintf("error reading file: %s", path))
+	}
+	defer f.Close()
+
+	var head bytes.Buffer
+	r := bufio.NewReader(f)
+	for n := 0; ; n++ {
+		line, err := r.ReadBytes('\n')
+		head.Write(line)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, common.Wrap(err, common.ErrorTypeFileSystem, fmt.Sprintf("error reading file: %s", path))
+		}
+		// Stop after the first line if it does not open a block, or at the closing delimiter
+		isDelimiter := markdown.IsFrontmatterDelimiter(line)
+		if (n == 0 && !isDelimiter) || (n > 0 && isDelimiter) {
+			break
+		}
+	}
+
+	yamlText, _, ok := markdown.SplitFrontmatter(head.Bytes())
+	if !ok {
+		return markdown.NewFrontmatter(), nil
+	}
+	return markdown.ParseFrontmatter(yamlText)
+}
+
 // MarkdownParser provides markdown parsing functionality
 type MarkdownParser struct {
 	handler *utils.MarkdownHandler
@@ -58,11 +97,38 @@ func NewMarkdownParser() *MarkdownParser {
 	}
 }
 
+// markdownParsers holds parsers for reuse, as building the goldmark pipeline
+// costs about as much as parsing a small memo
+var markdownParsers = sync.Pool{
+	New: func() any { return NewMarkdownParser() },
+}
+
+// AcquireMarkdownParser returns a parser from the pool. A parser must not be
+// used by two goroutines at once; give it back with ReleaseMarkdownParser.
+func AcquireMarkdownParser() *MarkdownParser {
+	return markdownParsers.Get().(*MarkdownParser)
+}
+
+// ReleaseMarkdownParser returns a parser to the pool
+func ReleaseMarkdownParser(p *MarkdownParser) {
+	markdownParsers.Put(p)
+}
+
 // Metadata extracts metadata from markdown content
 func (p *MarkdownParser) Metadata(content []byte) map[string]interface{} {
 	return p.handler.Metadata(content)
 }
 
+// Frontmatter extracts the full YAML frontmatter from markdown content.
+// Content without a frontmatter block yields an empty Frontmatter.
+func (p *MarkdownParser) Frontmatter(content []byte) (*markdown.Frontmatter, error) {
+	yamlText, _, ok := markdown.SplitFrontmatter(content)
+	if !ok {
+		return markdown.NewFrontmatter(), nil
+	}
+	return markdown.ParseFrontmatter(yamlText)
+}
+
 // HeadingBlocksByLevel extracts heading blocks at the specified level
 func (p *MarkdownParser) HeadingBlocksByLevel(content []byte, level int) ([]*markdown.HeadingBlock, error) {
 	return p.handler.HeadingBlocksByLevel(content, level)
@@ -72,4 +138,3 @@ func (p *MarkdownParser) HeadingBlocksByLevel(content []byte, level int) ([]*mar
 func (p *MarkdownParser) TopLevelBodyContent(content []byte) *markdown.HeadingBlock {
 	return p.handler.TopLevelBodyContent(content)
 }
-
@@ -2,17 +2,22 @@ package memo
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
+	"io/fs"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/hirotoni/memov2/internal/common"
 	"github.com/hirotoni/memov2/internal/domain"
+	"github.com/hirotoni/memov2/internal/domain/markdown"
 	"github.com/hirotoni/memov2/internal/interfaces"
 	"github.com/hirotoni/memov2/internal/platform"
 	repoCommon "github.com/hirotoni/memov2/internal/repositories/common"
@@ -39,22 +44,38 @@ func (r *memo) Memo(file interfaces.MemoFileInterface) (interfaces.MemoFileInter
 }
 
 func (r *memo) MemoEntries() ([]interfaces.MemoFileInterface, error) {
-	files, err := r.scanDirectory()
+	return r.sortedEntries(memofilefromosfileinfo)
+}
+
+// MemoHeaders returns the memos as MemoEntries does, but reads only their file
+// names and frontmatter: their bodies and headings are empty. Listing, category
+// and picker views need nothing more; load a memo's content with Memo.
+func (r *memo) MemoHeaders() ([]interfaces.MemoFileInterface, error) {
+	return r.sortedEntries(memoheaderfromosfileinfo)
+}
+
+// memoParser builds a memo from the file at path
+type memoParser func(path string, info os.FileInfo, logger *slog.Logger) (interfaces.MemoFileInterface, error)
+
+// sortedEntries scans the directory with parse and sorts the memos by date
+func (r *memo) sortedEntries(parse memoParser) ([]interfaces.MemoFileInterface, error) {
+	files, err := r.scanDirectory(parse)
 	if err != nil {
 		return nil, err
 	}
 
-	slices.SortFunc(files, func(a, b domain.MemoFileInterface) int {
-		if a.Date().Before(b.Date()) {
-			return -1
-		}
-		return 1
+	// Stable, so memos of the same date keep the order of their paths
+	slices.SortStableFunc(files, func(a, b domain.MemoFileInterface) int {
+		return a.Date().Compare(b.Date())
 	})
 
 	return files, nil
 }
 
-func (r *memo) scanDirectory() ([]interfaces.MemoFileInterface, error) {
+// scanDirectory finds the memo files under the directory, then reads them with
+// parse on a pool of workers. Memos are returned in the order of their paths,
+// however the work was scheduled.
+func (r *memo) scanDirectory(parse memoParser) ([]interfaces.MemoFileInterface, error) {
 	// Check if directory exists before walking
 	if _, err := os.Stat(r.dir); os.IsNotExist(err) {
 		// Directory doesn't exist, return empty list
@@ -65,51 +86,86 @@ func (r *memo) scanDirectory() ([]interfaces.MemoFileInterface, error) {
 	if err != nil {
 		return nil, common.Wrap(err, common.ErrorTypeRepository, "invalid regex pattern")
 	}
-	var files []interfaces.MemoFileInterface
-	err = filepath.Walk(r.dir, func(path string, info os.FileInfo, err error) error {
+
+	type memoPath struct {
+		path string
+		info os.FileInfo
+	}
+	var paths []memoPath
+	err = filepath.WalkDir(r.dir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return common.Wrap(err, common.ErrorTypeFileSystem, "error walking path")
 		}
-		if path == r.dir {
-			return nil // Skip the root directory itself
+		if d.IsDir() || !reg.MatchString(d.Name()) {
+			return nil
 		}
-
-		if reg.MatchString(info.Name()) {
-			mm, err := memofilefromosfileinfo(path, info, r.logger)
-			if err != nil {
-				// Skip files that match the naming pattern but cannot be parsed
-				// (e.g. malformed frontmatter or body). A single bad file must not
-				// break listing, search, weekly reports, or the index.
-				r.logger.Warn("Skipping unparseable memo file", "path", path, "error", err)
-				return nil
-			}
-
-			files = append(files, mm)
+		info, err := d.Info()
+		if err != nil {
+			return common.Wrap(err, common.ErrorTypeFileSystem, "error getting file info")
 		}
-
+		paths = append(paths, memoPath{path: path, info: info})
 		return nil
 	})
 	if err != nil {
 		return nil, common.Wrap(err, common.ErrorTypeFileSystem, "error walking directory")
 	}
+
+	// Each worker writes to the slot of the file it parsed
+	parsed := make([]interfaces.MemoFileInterface, len(paths))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(runtime.GOMAXPROCS(0), len(paths)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				mm, err := parse(paths[i].path, paths[i].info, r.logger)
+				if err != nil {
+					// Skip files that match the naming pattern but cannot be parsed
+					// (e.g. malformed frontmatter or body). A single bad file must not
+					// break listing, search, weekly reports, or the index.
+					r.logger.Warn("Skipping unparseable memo file", "path", paths[i].path, "error", err)
+					continue
+				}
+				parsed[i] = mm
+			}
+		}()
+	}
+	for i := range paths {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	files := make([]interfaces.MemoFileInterface, 0, len(parsed))
+	for _, mm := range parsed {
+		if mm != nil {
+			files = append(files, mm)
+		}
+	}
 	return files, nil
 }
 
+// ParseMemoFile parses the memo file at path, as MemoEntries does for every memo
+func ParseMemoFile(path string, info os.FileInfo, logger *slog.Logger) (interfaces.MemoFileInterface, error) {
+	return memofilefromosfileinfo(path, info, logger)
+}
+
+// ParseMemoHeader parses the file name and frontmatter of the memo file at
+// path, as MemoHeaders does for every memo
+func ParseMemoHeader(path string, info os.FileInfo, logger *slog.Logger) (interfaces.MemoFileInterface, error) {
+	return memoheaderfromosfileinfo(path, info, logger)
+}
+
+// memoDateTimeRegex finds the date and time a memo file name starts with
+var memoDateTimeRegex = regexp.MustCompile(domain.FileNameDateTimeRegexMemo)
+
 func memofilefromosfileinfo(path string, info os.FileInfo, logger *slog.Logger) (interfaces.MemoFileInterface, error) {
-	// 日付抽出（共通パーサーを使用）
-	date, err := repoCommon.ParseDateFromFilename(info.Name(), repoCommon.DateParserConfig{
-		DateTimeRegex: domain.FileNameDateTimeRegexMemo,
-		DateLayout:    domain.FileNameDateLayoutMemo,
-	})
+	date, title, err := memoFileName(info.Name())
 	if err != nil {
 		return nil, err
 	}
 
-	// タイトル抽出（Memo固有）
-	datetimestring := regexp.MustCompile(domain.FileNameDateTimeRegexMemo).FindString(info.Name())
-	title := strings.TrimPrefix(info.Name(), datetimestring+"_memo_")
-	title = strings.TrimSuffix(title, ".md")
-
 	// Markdownファイル読み込み（共通パーサーを使用）
 	b, err := repoCommon.ReadMarkdownFile(path)
 	if err != nil {
@@ -117,27 +173,15 @@ func memofilefromosfileinfo(path string, info os.FileInfo, logger *slog.Logger)
 	}
 
 	// Markdown解析（共通パーサーを使用）
-	parser := repoCommon.NewMarkdownParser()
-	meta := parser.Metadata(b)
-
-	var category []string
-	if v, ok := meta["category"]; ok {
-		switch val := v.(type) {
-		case []string:
-			category = val
-		case string:
-			category = []string{val}
-		case []interface{}:
-			for _, item := range val {
-				if str, ok := item.(string); ok {
-					category = append(category, str)
-				}
-			}
-		default:
-			logger.Warn("Unexpected category type, using empty category", "type", fmt.Sprintf("%T", v), "path", path)
-		}
+	parser := repoCommon.AcquireMarkdownParser()
+	defer repoCommon.ReleaseMarkdownParser(parser)
+	fm, err := parser.Frontmatter(b)
+	if err != nil {
+		return nil, common.Wrap(err, common.ErrorTypeRepository, fmt.Sprintf("failed to parse frontmatter in file: %s", path))
 	}
 
+	category := memoCategory(fm, path, logger)
+
 	// TopLevelBodyContent抽出（Memo固有、共通パーサーを使用）
 	tlbc := parser.TopLevelBodyContent(b)
 	if tlbc == nil {
@@ -151,7 +195,54 @@ func memofilefromosfileinfo(path string, info os.FileInfo, logger *slog.Logger)
 	}
 
 	// Domain層のファクトリを使用してMemoFileを構築
-	return domain.MemoFileFromParsedData(date, title, category, tlbc, hbs)
+	return domain.MemoFileFromParsedData(date, title, category, fm, tlbc, hbs)
+}
+
+// memoheaderfromosfileinfo builds a memo from its file name and frontmatter
+// alone, leaving the body unread
+func memoheaderfromosfileinfo(path string, info os.FileInfo, logger *slog.Logger) (interfaces.MemoFileInterface, error) {
+	date, title, err := memoFileName(info.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	fm, err := repoCommon.ReadFrontmatter(path)
+	if err != nil {
+		return nil, common.Wrap(err, common.ErrorTypeRepository, fmt.Sprintf("failed to parse frontmatter in file: %s", path))
+	}
+
+	return domain.MemoFileFromParsedData(date, title, memoCategory(fm, path, logger), fm, nil, nil)
+}
+
+// memoFileName returns the date and title in a memo file name
+func memoFileName(name string) (time.Time, string, error) {
+	// 日付抽出（共通パーサーを使用）
+	date, err := repoCommon.ParseDateFromFilename(name, repoCommon.DateParserConfig{
+		DateTimeRegex: domain.FileNameDateTimeRegexMemo,
+		DateLayout:    domain.FileNameDateLayoutMemo,
+	})
+	if err != nil {
+		return time.Time{}, "", err
+	}
+
+	// タイトル抽出（Memo固有）
+	datetimestring := memoDateTimeRegex.FindString(name)
+	title := strings.TrimPrefix(name, datetimestring+"_memo_")
+	title = strings.TrimSuffix(title, ".md")
+	return date, title, nil
+}
+
+// memoCategory returns the category tree in a memo's frontmatter
+func memoCategory(fm *markdown.Frontmatter, path string, logger *slog.Logger) []string {
+	if !fm.Has(domain.MetaKeyCategory) {
+		return nil
+	}
+	if v, ok := fm.GetStrings(domain.MetaKeyCategory); ok {
+		return v
+	}
+	v, _ := fm.Get(domain.MetaKeyCategory)
+	logger.Warn("Unexpected category type, using empty category", "type", fmt.Sprintf("%T", v), "path", path)
+	return nil
 }
 
 func (r *memo) Metadata(f interfaces.MemoFileInterface) (map[string]interface{}, error) {
@@ -190,6 +281,38 @@ func (r *memo) Save(file interfaces.MemoFileInterface, truncate bool) error {
 	return nil
 }
 
+// SetMetadata sets one string frontmatter key in the memo's file. Only that
+// key is written: the rest of the frontmatter and the body keep their bytes.
+// A file without frontmatter gets a block holding just the key.
+func (r *memo) SetMetadata(file interfaces.MemoFileInterface, key, value string) error {
+	path := filepath.Join(r.dir, file.Location(), file.FileName())
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return common.Wrap(err, common.ErrorTypeFileSystem, fmt.Sprintf("error reading file: %s", path))
+	}
+
+	open := markdown.FrontmatterDelimiter + "\n"
+	closing := markdown.FrontmatterDelimiter + "\n"
+	yamlText, body, ok := markdown.SplitFrontmatter(source)
+	if ok {
+		// keep the delimiter lines as written
+		head := source[:len(source)-len(body)]
+		open = string(head[:bytes.IndexByte(head, '\n')+1])
+		closing = string(head[len(open)+len(yamlText):])
+	}
+
+	fm, err := markdown.ParseFrontmatter(yamlText)
+	if err != nil {
+		return common.Wrap(err, common.ErrorTypeRepository, fmt.Sprintf("error parsing frontmatter: %s", path))
+	}
+	fm.SetString(key, value)
+
+	if err := platform.WriteFileAtomic(path, []byte(open+fm.YAML()+closing+string(body))); err != nil {
+		return err
+	}
+	r.logger.Info("File saved", "path", path)
+	return nil
+}
 
 type CategoryCollector struct {
 	memorepo      interfaces.MemoRepo
@@ -243,9 +366,9 @@ func (cc *CategoryCollector) collectFromDirectory() error {
 }
 
 func (cc *CategoryCollector) collectFromFiles() error {
-	files, err := cc.memorepo.MemoEntries()
+	files, err := cc.memorepo.MemoHeaders()
 	if err != nil {
-		return common.Wrap(err, common.ErrorTypeRepository, "error getting memo entries")
+		return common.Wrap(err, common.ErrorTypeRepository, "error getting memo headers")
 	}
 	for _, memo := range files {
 		categoryTree := memo.CategoryTree()
@@ -304,41 +427,47 @@ func (r *memo) Categories() ([][]string, error) {
 	return cc.allCategories, nil
 }
 
-func (r *memo) Move(file interfaces.MemoFileInterface, newCategoryTree []string) error {
-	// Get current file path
-	currentPath := filepath.Join(r.dir, file.Location(), file.FileName())
-
-	// retrieve again
-	mm, err := r.Memo(file)
+// Tags returns the tags of all memos with the number of memos tagged with
+// each, the most used first and otherwise by name
+func (r *memo) Tags() ([]interfaces.TagCount, error) {
+	files, err := r.MemoHeaders()
 	if err != nil {
-		return common.Wrap(err, common.ErrorTypeRepository, "error getting memo file")
+		return nil, common.Wrap(err, common.ErrorTypeRepository, "error getting memo headers")
 	}
 
-	// Update category tree
-	mm.SetCategoryTree(newCategoryTree)
-
-	// Create new directory if needed
-	newLocation := filepath.Join(r.dir, mm.Location())
-	if err := platform.EnsureDir(newLocation); err != nil {
-		return common.Wrap(err, common.ErrorTypeFileSystem, fmt.Sprintf("error ensuring directory: %s", newLocation))
+	counts := make(map[string]int)
+	for _, file := range files {
+		for _, tag := range file.Tags() {
+			counts[tag]++
+		}
 	}
 
-	// Create new file path
-	newPath := filepath.Join(newLocation, mm.FileName())
+	tags := make([]interfaces.TagCount, 0, len(counts))
+	for tag, count := range counts {
+		tags = append(tags, interfaces.TagCount{Tag: tag, Count: count})
+	}
+	slices.SortFunc(tags, func(a, b interfaces.TagCount) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return strings.Compare(a.Tag, b.Tag)
+	})
+	return tags, nil
+}
 
-	// Write content to new location
-	err = r.Save(mm, true)
+// Move moves the memo to newCategoryTree, rewriting the links other files hold to it
+func (r *memo) Move(file interfaces.MemoFileInterface, newCategoryTree []string) error {
+	rl, err := r.planRelocation(file, "", nonNilCategoryTree(newCategoryTree), nil)
 	if err != nil {
-		return common.Wrap(err, common.ErrorTypeRepository, fmt.Sprintf("failed to save file: %s", newPath))
+		return err
 	}
 
-	// Remove old file only if it's different from the new location
-	if currentPath != newPath {
-		err = os.Remove(currentPath)
-		if err != nil {
-			return common.Wrap(err, common.ErrorTypeFileSystem, fmt.Sprintf("failed to remove old file: %s", currentPath))
-		}
-		r.logger.Info("Moved file", "from", currentPath, "to", newPath)
+	if err := r.applyRelocation(rl); err != nil {
+		return common.Wrap(err, common.ErrorTypeRepository, fmt.Sprintf("failed to move file: %s", rl.oldPath))
+	}
+
+	if rl.oldPath != rl.newPath {
+		r.logger.Info("Moved file", "from", rl.oldPath, "to", rl.newPath)
 	}
 
 	return nil
@@ -362,49 +491,53 @@ func (r *memo) Delete(file interfaces.MemoFileInterface) error {
 	return nil
 }
 
+// Rename changes the memo's title and file name, rewriting the links other files hold to it
 func (r *memo) Rename(file interfaces.MemoFileInterface, newTitle string) error {
-	// Get current file path
-	oldPath := filepath.Join(r.dir, file.Location(), file.FileName())
-
-	// Check if file exists
-	if !platform.Exists(oldPath) {
-		return common.New(common.ErrorTypeRepository, fmt.Sprintf("file does not exist: %s", oldPath))
-	}
-
-	// Retrieve the full memo with content
-	mm, err := r.Memo(file)
+	rl, err := r.planRelocation(file, newTitle, nil, nil)
 	if err != nil {
-		return common.Wrap(err, common.ErrorTypeRepository, "error getting memo file")
+		return err
 	}
 
-	// Update the title
-	mm.SetTitle(newTitle)
-
-	// Create new file path with new title
-	newPath := filepath.Join(r.dir, mm.Location(), mm.FileName())
-
-	// If the path is the same (shouldn't happen but check anyway), just update content
-	if oldPath == newPath {
-		// Just save with updated title in content
-		if err := r.Save(mm, true); err != nil {
-			return common.Wrap(err, common.ErrorTypeRepository, "failed to save renamed file")
-		}
-		return nil
+	if err := r.applyRelocation(rl); err != nil {
+		return common.Wrap(err, common.ErrorTypeRepository, "failed to save renamed file")
 	}
 
-	// Save the file with new name
-	if err := r.Save(mm, true); err != nil {
-		return common.Wrap(err, common.ErrorTypeRepository, "failed to save renamed file")
+	if rl.oldPath != rl.newPath {
+		r.logger.Info("Renamed file", "from", rl.oldPath, "to", rl.newPath)
 	}
 
-	// Remove old file
-	if err := os.Remove(oldPath); err != nil {
-		return common.Wrap(err, common.ErrorTypeFileSystem, fmt.Sprintf("failed to remove old file: %s", oldPath))
+	return nil
+}
+
+// RenameAll renames the memos in order as Rename does, reading the memos the
+// wiki links resolve against only once. It returns how many were renamed,
+// stopping at the first rename that fails.
+func (r *memo) RenameAll(renames []interfaces.MemoRename) (int, error) {
+	if len(renames) == 0 {
+		return 0, nil
+	}
+	entries, err := r.MemoEntries()
+	if err != nil {
+		return 0, common.Wrap(err, common.ErrorTypeRepository, "error getting memo entries")
 	}
+	links := domain.NewLinkIndex(entries)
 
-	r.logger.Info("Renamed file", "from", oldPath, "to", newPath)
+	for i, rn := range renames {
+		rl, err := r.planRelocation(rn.File, rn.NewTitle, nil, links)
+		if err != nil {
+			return i, err
+		}
+		if err := r.applyRelocation(rl); err != nil {
+			return i, common.Wrap(err, common.ErrorTypeRepository, "failed to save renamed file")
+		}
+		if rl.oldPath != rl.newPath {
+			r.logger.Info("Renamed file", "from", rl.oldPath, "to", rl.newPath)
+		}
 
-	return nil
+		// Later renames resolve wiki links to the memo by its new name
+		links.Replace(domain.MemoPath(rn.File), rl.memo)
+	}
+	return len(renames), nil
 }
 
 func (r *memo) Duplicate(file interfaces.MemoFileInterface) (interfaces.MemoFileInterface, error) {
@@ -433,8 +566,17 @@ func (r *memo) Duplicate(file interfaces.MemoFileInterface) (interfaces.MemoFile
 		return nil, common.Wrap(err, common.ErrorTypeRepository, "error creating duplicate memo")
 	}
 
-	// Copy content
-	newMemo.SetTopLevelBodyContent(origMemo.TopLevelBodyContent())
+	// Copy content, keeping every frontmatter key of the original but the ID
+	newMemo.SetFrontmatter(origMemo.Frontmatter().Clone())
+	if origMemo.ID() != "" {
+		newMemo.SetID(domain.NewMemoID(newMemo.Date()))
+	}
+	// The H1 is kept as written, with the same suffix as the title
+	tl := *origMemo.TopLevelBodyContent()
+	if strings.TrimSpace(tl.HeadingText) != "" {
+		tl.HeadingText = strings.TrimSpace(tl.HeadingText) + " copied"
+	}
+	newMemo.SetTopLevelBodyContent(&tl)
 	newMemo.SetHeadingBlocks(origMemo.HeadingBlocks())
 
 	// Save the duplicate
@@ -447,3 +589,26 @@ func (r *memo) Duplicate(file interfaces.MemoFileInterface) (interfaces.MemoFile
 
 	return newMemo, nil
 }
+
+// MemoByID returns the header of the memo whose frontmatter id is id, ignoring case
+func (r *memo) MemoByID(id string) (interfaces.MemoFileInterface, error) {
+	files, err := r.MemoHeaders()
+	if err != nil {
+		return nil, common.Wrap(err, common.ErrorTypeRepository, "error getting memo headers")
+	}
+	for _, file := range files {
+		if file.ID() != "" && strings.EqualFold(file.ID(), id) {
+			return file, nil
+		}
+	}
+	return nil, common.New(common.ErrorTypeRepository, fmt.Sprintf("memo not found: %s%s", domain.MemoIDPrefix, id))
+}
+
+func (r *memo) Backlinks(file interfaces.MemoFileInterface) ([]interfaces.Backlink, error) {
+	entries, err := r.MemoEntries()
+	if err != nil {
+		return nil, common.Wrap(err, common.ErrorTypeRepository, "error getting memo entries")
+	}
+
+	return domain.NewLinkIndex(entries).Backlinks(file), nil
+}
@@ -1,15 +1,18 @@
 package memo
 
 import (
+	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/hirotoni/memov2/internal/domain"
 	"github.com/hirotoni/memov2/internal/domain/markdown"
+	"github.com/hirotoni/memov2/internal/interfaces"
 )
 
 // createTestMemo creates a memo file for testing
@@ -268,6 +271,102 @@ func TestMemoEntries_SkipsUnparseableFiles(t *testing.T) {
 	}
 }
 
+func TestMemoEntries_DeterministicOrder(t *testing.T) {
+	// Setup: many memos, several on each date, across categories
+	tmpDir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	repo := NewMemo(tmpDir, logger)
+
+	date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 60; i++ {
+		memo, _ := domain.NewMemoFile(date.AddDate(0, 0, i%5), fmt.Sprintf("Memo %02d", i), []string{fmt.Sprintf("cat%d", i%3)})
+		if err := repo.Save(memo, false); err != nil {
+			t.Fatalf("failed to save memo: %v", err)
+		}
+	}
+
+	paths := func() []string {
+		entries, err := repo.MemoEntries()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var ps []string
+		for _, e := range entries {
+			ps = append(ps, filepath.Join(e.Location(), e.FileName()))
+		}
+		return ps
+	}
+
+	// Execute: scan repeatedly; workers finish in any order
+	first := paths()
+	if len(first) != 60 {
+		t.Fatalf("expected 60 entries, got %d", len(first))
+	}
+	for i := 0; i < 5; i++ {
+		if got := paths(); !slices.Equal(got, first) {
+			t.Fatalf("scan %d returned a different order:\n%v\nwant\n%v", i, got, first)
+		}
+	}
+}
+
+func TestMemoHeaders(t *testing.T) {
+	// Setup
+	tmpDir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	repo := NewMemo(tmpDir, logger)
+
+	date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, category := range [][]string{{"work", "projects"}, {}, {"notes"}} {
+		memo, _ := domain.NewMemoFile(date.AddDate(0, 0, i), fmt.Sprintf("Memo %d", i), category)
+		memo.SetHeadingBlocks([]*markdown.HeadingBlock{createTestHeadingBlock(2, "Section", "body text\n")})
+		if err := repo.Save(memo, false); err != nil {
+			t.Fatalf("failed to save memo: %v", err)
+		}
+	}
+
+	// A memo without frontmatter
+	plainName := date.AddDate(0, 0, 3).Format(domain.FileNameDateLayoutMemo) + "_memo_plain.md"
+	if err := os.WriteFile(filepath.Join(tmpDir, plainName), []byte("# plain\n\n---\n\ntext\n"), 0o644); err != nil {
+		t.Fatalf("failed to write memo: %v", err)
+	}
+
+	// Execute
+	headers, err := repo.MemoHeaders()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	entries, err := repo.MemoEntries()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Assert: the same memos, titles and categories as the full scan, without bodies
+	if len(headers) != len(entries) || len(headers) != 4 {
+		t.Fatalf("expected 4 headers and entries, got %d and %d", len(headers), len(entries))
+	}
+	for i, h := range headers {
+		e := entries[i]
+		if h.FileName() != e.FileName() || h.Title() != e.Title() || !h.Date().Equal(e.Date()) {
+			t.Errorf("header %d = %s %q, want %s %q", i, h.FileName(), h.Title(), e.FileName(), e.Title())
+		}
+		if !slices.Equal(h.CategoryTree(), e.CategoryTree()) {
+			t.Errorf("header %d category = %v, want %v", i, h.CategoryTree(), e.CategoryTree())
+		}
+		if len(h.HeadingBlocks()) != 0 || h.TopLevelBodyContent().HeadingText != "" {
+			t.Errorf("header %d has body content", i)
+		}
+	}
+
+	// Full content loads through Memo
+	full, err := repo.Memo(headers[0])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(full.HeadingBlocks()) != 1 || full.HeadingBlocks()[0].HeadingText != "Section" {
+		t.Errorf("expected the memo's section, got %v", full.HeadingBlocks())
+	}
+}
+
 func TestMemoEntries_EmptyDirectory(t *testing.T) {
 	// Setup
 	tmpDir := t.TempDir()
@@ -400,6 +499,33 @@ func TestCategories_EmptyDirectory(t *testing.T) {
 	}
 }
 
+func TestTags(t *testing.T) {
+	// Setup
+	tmpDir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	repo := NewMemo(tmpDir, logger)
+
+	for i, tags := range [][]string{{"client", "meeting"}, {"meeting"}, {"acme", "client", "meeting"}, nil} {
+		memo, _ := domain.NewMemoFile(time.Now(), fmt.Sprintf("Memo %d", i), []string{"category1"})
+		memo.SetTags(tags)
+		if err := repo.Save(memo, false); err != nil {
+			t.Fatalf("failed to save memo: %v", err)
+		}
+	}
+
+	// Execute
+	tags, err := repo.Tags()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []interfaces.TagCount{{Tag: "meeting", Count: 3}, {Tag: "client", Count: 2}, {Tag: "acme", Count: 1}}
+	if !slices.Equal(tags, want) {
+		t.Errorf("expected %v, got %v", want, tags)
+	}
+}
+
 func TestMove_Success(t *testing.T) {
 	// Setup
 	tmpDir := t.TempDir()
@@ -545,7 +671,7 @@ func TestScanDirectory_WithSubdirectories(t *testing.T) {
 	}
 
 	// Execute
-	files, err := repo.scanDirectory()
+	files, err := repo.scanDirectory(memofilefromosfileinfo)
 
 	// Assert
 	if err != nil {
@@ -859,3 +985,282 @@ func TestDuplicate_PreservesContent(t *testing.T) {
 			topLevel.ContentText, reloaded.TopLevelBodyContent().ContentText)
 	}
 }
+
+func TestMemoByID(t *testing.T) {
+	// Setup
+	tmpDir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	repo := NewMemo(tmpDir, logger)
+
+	now := time.Now()
+	memo, _ := domain.NewMemoFile(now, "Memo", []string{"work"})
+	id := domain.NewMemoID(now)
+	memo.SetID(id)
+	if err := repo.Save(memo, false); err != nil {
+		t.Fatalf("failed to save memo: %v", err)
+	}
+	other, _ := domain.NewMemoFile(now, "Other", nil)
+	if err := repo.Save(other, false); err != nil {
+		t.Fatalf("failed to save memo: %v", err)
+	}
+
+	// Execute & Assert - IDs are found regardless of case, and across renames
+	found, err := repo.MemoByID(strings.ToLower(id))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if found.FileName() != memo.FileName() {
+		t.Errorf("expected %s, got %s", memo.FileName(), found.FileName())
+	}
+
+	if err := repo.Rename(memo, "Renamed"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	found, err = repo.MemoByID(id)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if found.Title() != "Renamed" {
+		t.Errorf("expected the renamed memo, got %s", found.Title())
+	}
+
+	// A duplicate gets an ID of its own
+	duplicate, err := repo.Duplicate(found)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if duplicate.ID() == "" || duplicate.ID() == id {
+		t.Errorf("expected the duplicate to get a new ID, got %q", duplicate.ID())
+	}
+
+	if _, err := repo.MemoByID(domain.NewMemoID(now)); err == nil {
+		t.Error("expected an error for an unknown ID")
+	}
+}
+
+func TestMoveRenameDuplicate_PreserveFrontmatter(t *testing.T) {
+	const extraFrontmatter = "# metadata added by hand\n" +
+		"tags:\n" +
+		"  - client\n" +
+		"  - meeting\n" +
+		"status: draft # still open\n" +
+		"source: https://example.com/notes\n"
+
+	testCases := []struct {
+		name      string
+		operation func(t *testing.T, repo interfaces.MemoRepo, file domain.MemoFileInterface) domain.MemoFileInterface
+		wantCat   string
+	}{
+		{
+			name: "move",
+			operation: func(t *testing.T, repo interfaces.MemoRepo, file domain.MemoFileInterface) domain.MemoFileInterface {
+				if err := repo.Move(file, []string{"archive"}); err != nil {
+					t.Fatalf("failed to move memo: %v", err)
+				}
+				moved, _ := domain.NewMemoFile(file.Date(), file.Title(), []string{"archive"})
+				return moved
+			},
+			wantCat: `category: ["archive"]`,
+		},
+		{
+			name: "rename",
+			operation: func(t *testing.T, repo interfaces.MemoRepo, file domain.MemoFileInterface) domain.MemoFileInterface {
+				if err := repo.Rename(file, "renamed"); err != nil {
+					t.Fatalf("failed to rename memo: %v", err)
+				}
+				renamed, _ := domain.NewMemoFile(file.Date(), "renamed", file.CategoryTree())
+				return renamed
+			},
+			wantCat: `category: ["work"]`,
+		},
+		{
+			name: "duplicate",
+			operation: func(t *testing.T, repo interfaces.MemoRepo, file domain.MemoFileInterface) domain.MemoFileInterface {
+				duplicated, err := repo.Duplicate(file)
+				if err != nil {
+					t.Fatalf("failed to duplicate memo: %v", err)
+				}
+				return duplicated
+			},
+			wantCat: `category: ["work"]`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+			repo := NewMemo(tmpDir, logger)
+
+			file := createTestMemo(t, "with metadata", []string{"work"}, []*markdown.HeadingBlock{
+				createTestHeadingBlock(2, "Section", "body\n"),
+			})
+			if err := repo.Save(file, true); err != nil {
+				t.Fatalf("failed to save memo: %v", err)
+			}
+
+			// Add keys by hand, as a user editing the file would
+			path := filepath.Join(tmpDir, file.Location(), file.FileName())
+			b, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read memo: %v", err)
+			}
+			content := strings.Replace(string(b), "\n---\n", "\n"+extraFrontmatter+"---\n", 1)
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatalf("failed to write memo: %v", err)
+			}
+
+			result := tc.operation(t, repo, file)
+
+			b, err = os.ReadFile(filepath.Join(tmpDir, result.Location(), result.FileName()))
+			if err != nil {
+				t.Fatalf("failed to read result: %v", err)
+			}
+			want := "---\n" + tc.wantCat + "\n" + extraFrontmatter + "---\n"
+			if !strings.HasPrefix(string(b), want) {
+				t.Errorf("frontmatter not preserved\nwant prefix:\n%s\ngot:\n%s", want, string(b))
+			}
+
+			reloaded, err := repo.Memo(result)
+			if err != nil {
+				t.Fatalf("failed to reload memo: %v", err)
+			}
+			if tags, ok := reloaded.MetaStrings("tags"); !ok || strings.Join(tags, ",") != "client,meeting" {
+				t.Errorf("expected tags [client meeting], got %v (ok=%v)", tags, ok)
+			}
+			if status, ok := reloaded.MetaString("status"); !ok || status != "draft" {
+				t.Errorf("expected status draft, got %q (ok=%v)", status, ok)
+			}
+		})
+	}
+}
+
+func TestBacklinks(t *testing.T) {
+	// Setup
+	tmpDir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	repo := NewMemo(tmpDir, logger)
+
+	target := createTestMemo(t, "Target Memo", []string{"work"}, nil)
+	source := createTestMemo(t, "Source Memo", []string{"notes"}, []*markdown.HeadingBlock{
+		createTestHeadingBlock(2, "References", "see [[Target Memo#Agenda]] and [[Unknown]]\n"),
+	})
+	unrelated := createTestMemo(t, "Unrelated", nil, []*markdown.HeadingBlock{
+		createTestHeadingBlock(2, "Code", "`[[Target Memo]]`\n"),
+	})
+	for _, m := range []domain.MemoFileInterface{target, source, unrelated} {
+		if err := repo.Save(m, false); err != nil {
+			t.Fatalf("failed to save memo: %v", err)
+		}
+	}
+
+	// Execute
+	backlinks, err := repo.Backlinks(target)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Assert
+	if len(backlinks) != 1 {
+		t.Fatalf("expected 1 backlink, got %d", len(backlinks))
+	}
+	bl := backlinks[0]
+	if bl.Source.FileName() != source.FileName() {
+		t.Errorf("expected source %s, got %s", source.FileName(), bl.Source.FileName())
+	}
+	if bl.Section != "References" {
+		t.Errorf("expected section References, got %q", bl.Section)
+	}
+	if bl.Link.Heading != "Agenda" {
+		t.Errorf("expected heading Agenda, got %q", bl.Link.Heading)
+	}
+}
+
+func TestMoveRenameDuplicate_KeepH1(t *testing.T) {
+	testCases := []struct {
+		name      string
+		heading   string
+		operation func(t *testing.T, repo interfaces.MemoRepo, file domain.MemoFileInterface) domain.MemoFileInterface
+		wantH1    string
+	}{
+		{
+			name:    "move keeps spaces",
+			heading: "meeting notes",
+			operation: func(t *testing.T, repo interfaces.MemoRepo, file domain.MemoFileInterface) domain.MemoFileInterface {
+				if err := repo.Move(file, []string{"archive"}); err != nil {
+					t.Fatalf("failed to move memo: %v", err)
+				}
+				moved, _ := domain.NewMemoFile(file.Date(), file.Title(), []string{"archive"})
+				return moved
+			},
+			wantH1: "# meeting notes\n",
+		},
+		{
+			name:    "move keeps a heading unlike the file name",
+			heading: "Weekly sync",
+			operation: func(t *testing.T, repo interfaces.MemoRepo, file domain.MemoFileInterface) domain.MemoFileInterface {
+				if err := repo.Move(file, []string{"archive"}); err != nil {
+					t.Fatalf("failed to move memo: %v", err)
+				}
+				moved, _ := domain.NewMemoFile(file.Date(), file.Title(), []string{"archive"})
+				return moved
+			},
+			wantH1: "# Weekly sync\n",
+		},
+		{
+			name:    "duplicate",
+			heading: "meeting notes",
+			operation: func(t *testing.T, repo interfaces.MemoRepo, file domain.MemoFileInterface) domain.MemoFileInterface {
+				duplicated, err := repo.Duplicate(file)
+				if err != nil {
+					t.Fatalf("failed to duplicate memo: %v", err)
+				}
+				return duplicated
+			},
+			wantH1: "# meeting notes copied\n",
+		},
+		{
+			name:    "rename writes the new title",
+			heading: "Weekly sync",
+			operation: func(t *testing.T, repo interfaces.MemoRepo, file domain.MemoFileInterface) domain.MemoFileInterface {
+				if err := repo.Rename(file, "project plan"); err != nil {
+					t.Fatalf("failed to rename memo: %v", err)
+				}
+				renamed, _ := domain.NewMemoFile(file.Date(), "project plan", file.CategoryTree())
+				return renamed
+			},
+			wantH1: "# project plan\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+			repo := NewMemo(tmpDir, logger)
+
+			path := filepath.Join(tmpDir, "work", "20240501Wed090000_memo_meeting-notes.md")
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatalf("failed to create directory: %v", err)
+			}
+			content := "---\ncategory: [\"work\"]\n---\n\n# " + tc.heading + "\n\nbody\n"
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatalf("failed to write memo: %v", err)
+			}
+			entries, err := repo.MemoEntries()
+			if err != nil || len(entries) != 1 {
+				t.Fatalf("failed to read memo: %v (%d entries)", err, len(entries))
+			}
+
+			result := tc.operation(t, repo, entries[0])
+
+			b, err := os.ReadFile(filepath.Join(tmpDir, result.Location(), result.FileName()))
+			if err != nil {
+				t.Fatalf("failed to read result: %v", err)
+			}
+			if !strings.Contains(string(b), "\n"+tc.wantH1) {
+				t.Errorf("expected H1 %q, got:\n%s", tc.wantH1, string(b))
+			}
+		})
+	}
+}
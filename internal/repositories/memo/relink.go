@@ -0,0 +1,356 @@
+package memo
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/hirotoni/memov2/internal/common"
+	"github.com/hirotoni/memov2/internal/domain"
+	"github.com/hirotoni/memov2/internal/domain/markdown"
+	"github.com/hirotoni/memov2/internal/interfaces"
+	"github.com/hirotoni/memov2/internal/platform"
+	"github.com/hirotoni/memov2/internal/utils"
+)
+
+// urlSchemeRegex matches link destinations that are URLs rather than relative paths
+var urlSchemeRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*:`)
+
+// relocation is a planned rename or move of a memo, together with the link
+// rewrites that keep other files pointing at it
+type relocation struct {
+	oldPath  string // absolute path before the change
+	newPath  string // absolute path after the change
+	memo     interfaces.MemoFileInterface
+	content  string // content of the memo at newPath
+	rewrites []interfaces.LinkRewrite
+
+	// set when the title changes
+	oldTags     []string
+	newTag      string
+	oldFileName string
+	links       *domain.LinkIndex
+	memoPath    string
+
+	source string // content of the memo at oldPath when planned
+}
+
+// LinkRewrites returns the changes the relocation makes to the files linking to the memo
+func (rl *relocation) LinkRewrites() []interfaces.LinkRewrite { return rl.rewrites }
+
+// planRelocation plans moving file to newCategoryTree and renaming it to newTitle.
+// An empty newTitle keeps the title. Wiki links resolve with links, which is
+// built from the memos directory if nil.
+func (r *memo) planRelocation(file interfaces.MemoFileInterface, newTitle string, newCategoryTree []string, links *domain.LinkIndex) (*relocation, error) {
+	oldPath := filepath.Join(r.dir, file.Location(), file.FileName())
+	if !platform.Exists(oldPath) {
+		return nil, common.New(common.ErrorTypeRepository, fmt.Sprintf("file does not exist: %s", oldPath))
+	}
+
+	source, err := os.ReadFile(oldPath)
+	if err != nil {
+		return nil, common.Wrap(err, common.ErrorTypeFileSystem, fmt.Sprintf("failed to read file: %s", oldPath))
+	}
+	// retrieve again
+	mm, err := r.Memo(file)
+	if err != nil {
+		return nil, common.Wrap(err, common.ErrorTypeRepository, "error getting memo file")
+	}
+
+	rl := &relocation{oldPath: oldPath, memo: mm, source: string(source)}
+	newTitle = domain.SanitizeTitle(newTitle)
+
+	if newTitle != "" && newTitle != mm.Title() {
+		if links == nil {
+			entries, err := r.MemoEntries()
+			if err != nil {
+				return nil, common.Wrap(err, common.ErrorTypeRepository, "error getting memo entries")
+			}
+			links = domain.NewLinkIndex(entries)
+		}
+
+		b := utils.NewMarkdownBuilder()
+		rl.oldTags = []string{b.HeadingTag(mm.Title())}
+		if tl := mm.TopLevelBodyContent(); tl != nil && tl.HeadingText != "" {
+			rl.oldTags = append(rl.oldTags, b.HeadingTag(tl.HeadingText))
+		}
+		rl.newTag = b.HeadingTag(newTitle)
+		rl.oldFileName = mm.FileName()
+		rl.links = links
+		rl.memoPath = domain.MemoPath(mm)
+	}
+	if newTitle != "" {
+		// also rewrites an H1 that disagrees with the file name
+		mm.SetTitle(newTitle)
+	}
+	if newCategoryTree != nil {
+		mm.SetCategoryTree(newCategoryTree)
+	}
+	rl.newPath = filepath.Join(r.dir, mm.Location(), mm.FileName())
+	if rl.newPath != rl.oldPath && platform.Exists(rl.newPath) {
+		return nil, common.New(common.ErrorTypeRepository, fmt.Sprintf("file already exists: %s", rl.newPath))
+	}
+
+	// The memo's own relative links must keep pointing at the same files from its new directory
+	rl.content = markdown.RewriteLinks(mm.ContentString(), func(dest string) (string, bool) {
+		return rl.rewriteDest(dest, filepath.Dir(rl.oldPath), filepath.Dir(rl.newPath))
+	})
+
+	if rl.oldPath == rl.newPath {
+		return rl, nil
+	}
+
+	err = filepath.Walk(r.dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return common.Wrap(err, common.ErrorTypeFileSystem, "error walking path")
+		}
+		if path != r.dir && strings.HasPrefix(info.Name(), ".") {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() || filepath.Ext(path) != domain.FileExtension || path == rl.oldPath {
+			return nil
+		}
+
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return common.Wrap(err, common.ErrorTypeFileSystem, fmt.Sprintf("failed to read file: %s", path))
+		}
+		before := string(b)
+
+		dir := filepath.Dir(path)
+		after := markdown.RewriteLinks(before, func(dest string) (string, bool) {
+			return rl.rewriteDest(dest, dir, dir)
+		})
+		if rl.links != nil {
+			after = markdown.RewriteWikiLinks(after, rl.rewriteWikiLink)
+		}
+		if after == before {
+			return nil
+		}
+
+		rel, err := filepath.Rel(r.dir, path)
+		if err != nil {
+			return common.Wrap(err, common.ErrorTypeFileSystem, "error getting relative path")
+		}
+		rl.rewrites = append(rl.rewrites, interfaces.LinkRewrite{Path: rel, Before: before, After: after})
+		return nil
+	})
+	if err != nil {
+		return nil, common.Wrap(err, common.ErrorTypeRepository, "error finding links to memo")
+	}
+
+	return rl, nil
+}
+
+// rewriteDest rewrites a link destination written in a file in fromDir so that,
+// written in a file in toDir, it points at the relocated memo, or still at the
+// same file if it pointed elsewhere.
+func (rl *relocation) rewriteDest(dest, fromDir, toDir string) (string, bool) {
+	if dest == "" || strings.HasPrefix(dest, "#") || strings.HasPrefix(dest, "/") || urlSchemeRegex.MatchString(dest) {
+		return "", false
+	}
+
+	p, fragment, hasFragment := strings.Cut(dest, "#")
+	if p == "" {
+		return "", false
+	}
+	unescaped, err := url.PathUnescape(p)
+	if err != nil {
+		unescaped = p
+	}
+
+	target := filepath.Join(fromDir, filepath.FromSlash(unescaped))
+	switch {
+	case target == rl.oldPath:
+		target = rl.newPath
+		if hasFragment {
+			fragment = rl.rewriteTag(fragment)
+		}
+	case fromDir == toDir || !platform.Exists(target):
+		return "", false
+	}
+
+	rel, err := filepath.Rel(toDir, target)
+	if err != nil {
+		return "", false
+	}
+	rel = filepath.ToSlash(rel)
+	if unescaped != p {
+		rel = (&url.URL{Path: rel}).EscapedPath()
+	}
+	if strings.HasPrefix(p, "./") && !strings.HasPrefix(rel, "../") {
+		rel = "./" + rel
+	}
+	if hasFragment {
+		rel += "#" + fragment
+	}
+
+	return rel, rel != dest
+}
+
+// rewriteTag replaces an anchor pointing at the memo's title heading with the anchor of the new title
+func (rl *relocation) rewriteTag(tag string) string {
+	for _, old := range rl.oldTags {
+		if !strings.EqualFold(tag, old) {
+			continue
+		}
+		if tag == strings.ToLower(tag) {
+			return strings.ToLower(rl.newTag)
+		}
+		return rl.newTag
+	}
+	return tag
+}
+
+// rewriteWikiLink points a wiki link resolving to the renamed memo at its new title or file name
+func (rl *relocation) rewriteWikiLink(link markdown.WikiLink) (markdown.WikiLink, bool) {
+	if _, ok := domain.ParseMemoIDRef(link.Target); ok {
+		return link, false // IDs survive renames and moves
+	}
+	target, ok := rl.links.Resolve(link)
+	if !ok || domain.MemoPath(target) != rl.memoPath {
+		return link, false
+	}
+
+	oldName := strings.ToLower(rl.oldFileName)
+	switch strings.ToLower(link.Target) {
+	case oldName:
+		link.Target = rl.memo.FileName()
+	case strings.TrimSuffix(oldName, domain.FileExtension):
+		link.Target = strings.TrimSuffix(rl.memo.FileName(), domain.FileExtension)
+	default:
+		link.Target = rl.memo.Title()
+	}
+	return link, true
+}
+
+// applyRelocation writes the relocated memo and the link rewrites, then removes the memo
+// from its old path. Either every change is applied or none is. Nothing is
+// changed if the memo or a file linking to it was edited since it was planned.
+func (r *memo) applyRelocation(rl *relocation) error {
+	unchanged := map[string]string{rl.oldPath: rl.source}
+	for _, rw := range rl.rewrites {
+		unchanged[filepath.Join(r.dir, rw.Path)] = rw.Before
+	}
+	for path, before := range unchanged {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return common.Wrap(err, common.ErrorTypeFileSystem, fmt.Sprintf("failed to read file: %s", path))
+		}
+		if string(b) != before {
+			return common.New(common.ErrorTypeRepository, fmt.Sprintf("file changed since the links were planned: %s", path))
+		}
+	}
+
+	changes := []fileChange{{path: rl.newPath, content: rl.content}}
+	for _, rw := range rl.rewrites {
+		changes = append(changes, fileChange{path: filepath.Join(r.dir, rw.Path), content: rw.After})
+	}
+	if rl.oldPath != rl.newPath {
+		changes = append(changes, fileChange{path: rl.oldPath, remove: true})
+	}
+
+	if err := applyFileChanges(changes, platform.WriteFileAtomic); err != nil {
+		return err
+	}
+
+	for _, rw := range rl.rewrites {
+		r.logger.Info("Rewrote links", "path", filepath.Join(r.dir, rw.Path))
+	}
+	return nil
+}
+
+// fileChange is a write or removal of a single file
+type fileChange struct {
+	path    string
+	content string
+	remove  bool
+}
+
+// applyFileChanges applies changes in order. If one fails, the files already
+// changed are restored to their previous content.
+func applyFileChanges(changes []fileChange, write func(path string, data []byte) error) error {
+	type backup struct {
+		path    string
+		content []byte
+		existed bool
+	}
+	var done []backup
+
+	rollback := func() {
+		for i := len(done) - 1; i >= 0; i-- {
+			b := done[i]
+			if b.existed {
+				_ = write(b.path, b.content)
+			} else {
+				_ = os.Remove(b.path)
+			}
+		}
+	}
+
+	for _, c := range changes {
+		b := backup{path: c.path}
+		content, err := os.ReadFile(c.path)
+		switch {
+		case err == nil:
+			b.content, b.existed = content, true
+		case !errors.Is(err, os.ErrNotExist):
+			rollback()
+			return common.Wrap(err, common.ErrorTypeFileSystem, fmt.Sprintf("failed to read file: %s", c.path))
+		}
+
+		if c.remove {
+			err = os.Remove(c.path)
+		} else {
+			err = write(c.path, []byte(c.content))
+		}
+		if err != nil {
+			rollback()
+			return common.Wrap(err, common.ErrorTypeFileSystem, fmt.Sprintf("failed to update file, all changes were rolled back: %s", c.path))
+		}
+		done = append(done, b)
+	}
+
+	return nil
+}
+
+// PlanRename plans renaming file to newTitle, for ApplyRelocation
+func (r *memo) PlanRename(file interfaces.MemoFileInterface, newTitle string) (interfaces.Relocation, error) {
+	return r.planRelocation(file, newTitle, nil, nil)
+}
+
+// PlanMove plans moving file to newCategoryTree, for ApplyRelocation
+func (r *memo) PlanMove(file interfaces.MemoFileInterface, newCategoryTree []string) (interfaces.Relocation, error) {
+	return r.planRelocation(file, "", nonNilCategoryTree(newCategoryTree), nil)
+}
+
+// ApplyRelocation makes a rename or move exactly as PlanRename or PlanMove
+// planned it, so what is written is what was previewed
+func (r *memo) ApplyRelocation(plan interfaces.Relocation) error {
+	rl, ok := plan.(*relocation)
+	if !ok {
+		return common.New(common.ErrorTypeRepository, "relocation was not planned by this repository")
+	}
+	if err := r.applyRelocation(rl); err != nil {
+		return common.Wrap(err, common.ErrorTypeRepository, fmt.Sprintf("failed to relocate file: %s", rl.oldPath))
+	}
+	if rl.oldPath != rl.newPath {
+		r.logger.Info("Relocated file", "from", rl.oldPath, "to", rl.newPath)
+	}
+	return nil
+}
+
+// nonNilCategoryTree distinguishes moving to the root category from keeping the category
+func nonNilCategoryTree(tree []string) []string {
+	if tree == nil {
+		return []string{}
+	}
+	return tree
+}
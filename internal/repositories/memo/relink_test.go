@@ -0,0 +1,311 @@
+package memo
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hirotoni/memov2/internal/domain"
+	"github.com/hirotoni/memov2/internal/domain/markdown"
+	"github.com/hirotoni/memov2/internal/interfaces"
+)
+
+// setupLinkedMemos saves a target memo in work/ and a memo in notes/ linking to it in several ways
+func setupLinkedMemos(t *testing.T, repo interfaces.MemoRepo, dir string) (target, linker domain.MemoFileInterface) {
+	t.Helper()
+
+	now := time.Now()
+	target, err := domain.NewMemoFile(now, "Target Memo", []string{"work"})
+	if err != nil {
+		t.Fatalf("failed to create memo file: %v", err)
+	}
+	linker, err = domain.NewMemoFile(now.Add(time.Second), "Linker", []string{"notes"})
+	if err != nil {
+		t.Fatalf("failed to create memo file: %v", err)
+	}
+
+	target.SetHeadingBlocks([]*markdown.HeadingBlock{
+		createTestHeadingBlock(2, "Refs", "[linker](../notes/"+linker.FileName()+")\n"),
+	})
+	linker.SetHeadingBlocks([]*markdown.HeadingBlock{
+		createTestHeadingBlock(2, "Links", "- [plain](../work/"+target.FileName()+")\n"+
+			"- [anchor](../work/"+target.FileName()+"#Target-Memo)\n"+
+			"- [section](../work/"+target.FileName()+"#Refs)\n"+
+			"- [[Target Memo]]\n"+
+			"- `../work/"+target.FileName()+"`\n"),
+	})
+
+	for _, m := range []domain.MemoFileInterface{target, linker} {
+		if err := repo.Save(m, false); err != nil {
+			t.Fatalf("failed to save memo: %v", err)
+		}
+	}
+
+	index := "- [Target-Memo](work/" + target.FileName() + ")\n- [Linker](notes/" + linker.FileName() + ")\n"
+	if err := os.WriteFile(filepath.Join(dir, "index.md"), []byte(index), 0o644); err != nil {
+		t.Fatalf("failed to write index: %v", err)
+	}
+
+	return target, linker
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	return string(b)
+}
+
+func TestRename_RewritesInboundLinks(t *testing.T) {
+	// Setup
+	tmpDir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	repo := NewMemo(tmpDir, logger)
+	target, linker := setupLinkedMemos(t, repo, tmpDir)
+
+	renamed, err := domain.NewMemoFile(target.Date(), "New Name", []string{"work"})
+	if err != nil {
+		t.Fatalf("failed to create memo file: %v", err)
+	}
+
+	// Execute - preview
+	plan, err := repo.PlanRename(target, "New Name")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rewrites := plan.LinkRewrites()
+	if len(rewrites) != 2 {
+		t.Fatalf("expected 2 rewrites, got %d", len(rewrites))
+	}
+	if rewrites[0].Path != "index.md" || rewrites[1].Path != filepath.Join("notes", linker.FileName()) {
+		t.Errorf("unexpected rewrite paths: %s, %s", rewrites[0].Path, rewrites[1].Path)
+	}
+	if strings.Contains(readFile(t, filepath.Join(tmpDir, "index.md")), renamed.FileName()) {
+		t.Errorf("preview should not change files")
+	}
+
+	// Execute - rename as previewed
+	if err := repo.ApplyRelocation(plan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Assert
+	linkerContent := readFile(t, filepath.Join(tmpDir, "notes", linker.FileName()))
+	for _, want := range []string{
+		"[plain](../work/" + renamed.FileName() + ")",
+		"[anchor](../work/" + renamed.FileName() + "#New-Name)",
+		"[section](../work/" + renamed.FileName() + "#Refs)",
+		"[[New Name]]",
+		"`../work/" + target.FileName() + "`",
+	} {
+		if !strings.Contains(linkerContent, want) {
+			t.Errorf("expected linker to contain %q, got:\n%s", want, linkerContent)
+		}
+	}
+
+	index := readFile(t, filepath.Join(tmpDir, "index.md"))
+	if !strings.Contains(index, "(work/"+renamed.FileName()+")") {
+		t.Errorf("expected index to link to renamed memo, got:\n%s", index)
+	}
+
+	if _, err := os.Stat(filepath.Join(tmpDir, "work", target.FileName())); !os.IsNotExist(err) {
+		t.Errorf("old file should be removed")
+	}
+}
+
+func TestMove_RewritesLinks(t *testing.T) {
+	// Setup
+	tmpDir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	repo := NewMemo(tmpDir, logger)
+	target, linker := setupLinkedMemos(t, repo, tmpDir)
+
+	// Execute
+	plan, err := repo.PlanMove(target, []string{"archive", "2025"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(plan.LinkRewrites()) != 2 {
+		t.Fatalf("expected 2 rewrites, got %d", len(plan.LinkRewrites()))
+	}
+	if err := repo.ApplyRelocation(plan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Assert - inbound links follow the memo, wiki links and anchors stay as they are
+	linkerContent := readFile(t, filepath.Join(tmpDir, "notes", linker.FileName()))
+	for _, want := range []string{
+		"[plain](../archive/2025/" + target.FileName() + ")",
+		"[anchor](../archive/2025/" + target.FileName() + "#Target-Memo)",
+		"[[Target Memo]]",
+	} {
+		if !strings.Contains(linkerContent, want) {
+			t.Errorf("expected linker to contain %q, got:\n%s", want, linkerContent)
+		}
+	}
+
+	index := readFile(t, filepath.Join(tmpDir, "index.md"))
+	if !strings.Contains(index, "(archive/2025/"+target.FileName()+")") {
+		t.Errorf("expected index to link to moved memo, got:\n%s", index)
+	}
+
+	// Assert - the moved memo's own relative links still resolve
+	moved := readFile(t, filepath.Join(tmpDir, "archive", "2025", target.FileName()))
+	if !strings.Contains(moved, "[linker](../../notes/"+linker.FileName()+")") {
+		t.Errorf("expected moved memo to keep its link to linker, got:\n%s", moved)
+	}
+}
+
+func TestApplyRelocation_RefusesChangedFiles(t *testing.T) {
+	// Setup
+	tmpDir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	repo := NewMemo(tmpDir, logger)
+	target, linker := setupLinkedMemos(t, repo, tmpDir)
+
+	plan, err := repo.PlanRename(target, "New Name")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	linkerPath := filepath.Join(tmpDir, "notes", linker.FileName())
+	edited := readFile(t, linkerPath) + "\nedited after the preview\n"
+	if err := os.WriteFile(linkerPath, []byte(edited), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	// Execute
+	if err := repo.ApplyRelocation(plan); err == nil {
+		t.Fatal("expected an error applying a plan whose files changed")
+	}
+
+	// Assert - nothing was changed
+	if got := readFile(t, linkerPath); got != edited {
+		t.Errorf("expected linker to be left as edited, got:\n%s", got)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "work", target.FileName())); err != nil {
+		t.Errorf("expected memo to stay at its path: %v", err)
+	}
+}
+
+func TestRenameAll(t *testing.T) {
+	// Setup
+	tmpDir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	repo := NewMemo(tmpDir, logger)
+	target, linker := setupLinkedMemos(t, repo, tmpDir)
+
+	// Execute
+	n, err := repo.RenameAll([]interfaces.MemoRename{
+		{File: target, NewTitle: "New Name"},
+		{File: linker, NewTitle: "New Linker"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("expected 2 renames, got %d", n)
+	}
+
+	// Assert - each memo follows the other's rename
+	renamedTarget, err := domain.NewMemoFile(target.Date(), "New Name", []string{"work"})
+	if err != nil {
+		t.Fatalf("failed to create memo file: %v", err)
+	}
+	renamedLinker, err := domain.NewMemoFile(linker.Date(), "New Linker", []string{"notes"})
+	if err != nil {
+		t.Fatalf("failed to create memo file: %v", err)
+	}
+	linkerContent := readFile(t, filepath.Join(tmpDir, "notes", renamedLinker.FileName()))
+	for _, want := range []string{"[plain](../work/" + renamedTarget.FileName() + ")", "[[New Name]]"} {
+		if !strings.Contains(linkerContent, want) {
+			t.Errorf("expected linker to contain %q, got:\n%s", want, linkerContent)
+		}
+	}
+	targetContent := readFile(t, filepath.Join(tmpDir, "work", renamedTarget.FileName()))
+	if !strings.Contains(targetContent, "[linker](../notes/"+renamedLinker.FileName()+")") {
+		t.Errorf("expected target to link to renamed linker, got:\n%s", targetContent)
+	}
+}
+
+func TestRename_RefusesExistingFile(t *testing.T) {
+	// Setup
+	tmpDir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	repo := NewMemo(tmpDir, logger)
+	target, _ := setupLinkedMemos(t, repo, tmpDir)
+
+	taken, err := domain.NewMemoFile(target.Date(), "Taken", []string{"work"})
+	if err != nil {
+		t.Fatalf("failed to create memo file: %v", err)
+	}
+	takenPath := filepath.Join(tmpDir, "work", taken.FileName())
+	if err := os.WriteFile(takenPath, []byte("# Taken\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	// Execute
+	if err := repo.Rename(target, "Taken"); err == nil {
+		t.Fatal("expected an error renaming over an existing file")
+	}
+
+	// Assert - neither file was touched
+	if got := readFile(t, takenPath); got != "# Taken\n" {
+		t.Errorf("expected existing file to be kept, got:\n%s", got)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "work", target.FileName())); err != nil {
+		t.Errorf("expected memo to stay at its path: %v", err)
+	}
+}
+
+func TestApplyFileChanges_RollsBack(t *testing.T) {
+	// Setup
+	tmpDir := t.TempDir()
+	existing := filepath.Join(tmpDir, "existing.md")
+	created := filepath.Join(tmpDir, "created.md")
+	removed := filepath.Join(tmpDir, "removed.md")
+	failing := filepath.Join(tmpDir, "failing.md")
+	for path, content := range map[string]string{existing: "before", removed: "keep me", failing: "untouched"} {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+
+	write := func(path string, data []byte) error {
+		if path == failing {
+			return errors.New("disk full")
+		}
+		return os.WriteFile(path, data, 0o644)
+	}
+
+	// Execute
+	err := applyFileChanges([]fileChange{
+		{path: created, content: "new"},
+		{path: existing, content: "after"},
+		{path: removed, remove: true},
+		{path: failing, content: "changed"},
+	}, write)
+
+	// Assert
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Errorf("created file should be removed on rollback")
+	}
+	if got := readFile(t, existing); got != "before" {
+		t.Errorf("expected existing file to be restored, got %q", got)
+	}
+	if got := readFile(t, removed); got != "keep me" {
+		t.Errorf("expected removed file to be restored, got %q", got)
+	}
+	if got := readFile(t, failing); got != "untouched" {
+		t.Errorf("expected failing file to be unchanged, got %q", got)
+	}
+}
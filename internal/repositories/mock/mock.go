@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/hirotoni/memov2/internal/domain"
+	"github.com/hirotoni/memov2/internal/interfaces"
 )
 
 // Mock is a mock implementation of the fileRepo interface for testing
@@ -12,6 +13,7 @@ type Mock struct {
 	MockFindTodosFileByDate func(date time.Time) (domain.TodoFileInterface, error)
 	MockSave                func(file domain.MemoFileInterface, truncate bool) error
 	MockMemoEntries         func() ([]domain.MemoFileInterface, error)
+	MockMemoHeaders         func() ([]domain.MemoFileInterface, error)
 	MockTodoEntries         func() ([]domain.TodoFileInterface, error)
 	MockMetadata            func(file domain.MemoFileInterface) (map[string]interface{}, error)
 }
@@ -55,6 +57,14 @@ func (m *Mock) MemoEntries() ([]domain.MemoFileInterface, error) {
 	return nil, nil
 }
 
+// MemoHeaders calls the mock implementation
+func (m *Mock) MemoHeaders() ([]domain.MemoFileInterface, error) {
+	if m.MockMemoHeaders != nil {
+		return m.MockMemoHeaders()
+	}
+	return nil, nil
+}
+
 // Metadata calls the mock implementation
 func (m *Mock) Metadata(file domain.MemoFileInterface) (map[string]interface{}, error) {
 	if m.MockMetadata != nil {
@@ -74,18 +84,25 @@ type MockEditor struct {
 	Calls    []EditorCall // Track all calls made to the editor
 }
 
-// EditorCall tracks a call to the Open method
+// EditorCall tracks a call to the Open or OpenAt method
 type EditorCall struct {
-	Basedir string
-	Path    string
+	Basedir  string
+	Path     string
+	Position interfaces.Position
 }
 
 // Open implements the Editor interface
 func (m *MockEditor) Open(basedir, path string) error {
+	return m.OpenAt(basedir, path, interfaces.Position{})
+}
+
+// OpenAt implements the Editor interface
+func (m *MockEditor) OpenAt(basedir, path string, pos interfaces.Position) error {
 	// Record the call
 	m.Calls = append(m.Calls, EditorCall{
-		Basedir: basedir,
-		Path:    path,
+		Basedir:  basedir,
+		Path:     path,
+		Position: pos,
 	})
 
 	// Call the custom function if provided
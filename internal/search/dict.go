@@ -0,0 +1,162 @@
+package search
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hirotoni/memov2/internal/platform"
+	"golang.org/x/text/encoding/japanese"
+)
+
+// userDictionaryHeader starts a personal dictionary created by AddDictionaryEntry
+const userDictionaryHeader = ";; -*- coding: utf-8 -*-\n;; okuri-nasi entries.\n"
+
+// codingRegex matches the Emacs coding header of a dictionary, ;; -*- coding: euc-jp -*-
+var codingRegex = regexp.MustCompile(`-\*-.*\bcoding:\s*([\w-]+)`)
+
+// readDictionary reads an SKK dictionary file. Dictionaries are UTF-8 or, as
+// the ones distributed by the SKK project, EUC-JP. A missing file is empty.
+func readDictionary(path string) ([]byte, error) {
+	data, _, err := readDictionaryEncoding(path)
+	return data, err
+}
+
+// readDictionaryEncoding reads an SKK dictionary file as readDictionary does,
+// also reporting whether it is EUC-JP: as its coding header says or, without
+// one, when it is not valid UTF-8
+func readDictionaryEncoding(path string) ([]byte, bool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read dictionary %s: %w", path, err)
+	}
+
+	header, _, _ := bytes.Cut(data, []byte("\n"))
+	eucJP := !utf8.Valid(data)
+	if m := codingRegex.FindSubmatch(header); m != nil && strings.HasPrefix(strings.ToLower(string(m[1])), "euc-j") {
+		eucJP = true
+	}
+	if !eucJP {
+		return data, false, nil
+	}
+	decoded, err := japanese.EUCJP.NewDecoder().Bytes(data)
+	if err != nil {
+		return nil, false, fmt.Errorf("dictionary %s is neither UTF-8 nor EUC-JP: %w", path, err)
+	}
+	return decoded, true, nil
+}
+
+// parseCandidates parses the candidates of an SKK entry, /kanji1;comment/kanji2/,
+// dropping the comments
+func parseCandidates(field string) []string {
+	rawCandidates := strings.Split(strings.Trim(field, "/"), "/")
+	candidates := make([]string, 0, len(rawCandidates))
+	for _, c := range rawCandidates {
+		if semicolonIdx := strings.Index(c, ";"); semicolonIdx != -1 {
+			c = c[:semicolonIdx]
+		}
+		if c != "" {
+			candidates = append(candidates, c)
+		}
+	}
+	return candidates
+}
+
+// mergeCandidates appends the candidates of added missing from candidates
+func mergeCandidates(candidates, added []string) []string {
+	for _, c := range added {
+		if !slices.Contains(candidates, c) {
+			candidates = append(candidates, c)
+		}
+	}
+	return candidates
+}
+
+// AddDictionaryEntry adds word as the first candidate of reading to the SKK
+// dictionary at path, creating it if needed. reading is hiragana, or romaji
+// converted to hiragana. The dictionary keeps its encoding and header.
+func AddDictionaryEntry(path, reading, word string) error {
+	reading, err := dictionaryReading(reading)
+	if err != nil {
+		return err
+	}
+	if word == "" || strings.ContainsAny(word, "/;") || strings.IndexFunc(word, unicode.IsSpace) >= 0 {
+		return fmt.Errorf("invalid word %q: it must be non-empty, without spaces, / or ;", word)
+	}
+
+	data, eucJP, err := readDictionaryEncoding(path)
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		data = []byte(userDictionaryHeader)
+	}
+
+	// Move the word to the front of an existing entry, or add a new entry
+	var out bytes.Buffer
+	found := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if r, field, ok := strings.Cut(line, " "); ok && r == reading && !found {
+			found = true
+			candidates := []string{word}
+			for _, c := range strings.Split(strings.Trim(field, "/"), "/") {
+				if c != "" && c != word && !strings.HasPrefix(c, word+";") {
+					candidates = append(candidates, c)
+				}
+			}
+			line = reading + " /" + strings.Join(candidates, "/") + "/"
+		}
+		out.WriteString(line + "\n")
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read dictionary %s: %w", path, err)
+	}
+	if !found {
+		out.WriteString(reading + " /" + word + "/\n")
+	}
+
+	b := out.Bytes()
+	if eucJP {
+		if b, err = japanese.EUCJP.NewEncoder().Bytes(b); err != nil {
+			return fmt.Errorf("word %q cannot be written to the EUC-JP dictionary %s: %w", word, path, err)
+		}
+	}
+	if err := platform.WriteFileAtomic(path, b); err != nil {
+		return fmt.Errorf("failed to write dictionary %s: %w", path, err)
+	}
+	return nil
+}
+
+// dictionaryReading returns reading as hiragana, converting romaji
+func dictionaryReading(reading string) (string, error) {
+	kana := reading
+	if !containsJapanese(reading) {
+		var pending string
+		var ok bool
+		kana, pending, ok = (&RomajiConverter{}).splitRomaji(reading)
+		if !ok || pending != "" {
+			kana = ""
+		}
+	}
+	if kana == "" {
+		return "", fmt.Errorf("invalid reading %q: use hiragana or romaji", reading)
+	}
+	for _, r := range kana {
+		if !unicode.In(r, unicode.Hiragana) && r != 'ー' {
+			return "", fmt.Errorf("invalid reading %q: use hiragana or romaji", reading)
+		}
+	}
+	return kana, nil
+}
@@ -0,0 +1,79 @@
+package search
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/japanese"
+)
+
+func TestAddDictionaryEntry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dict", "SKK-JISYO.user")
+
+	require.NoError(t, AddDictionaryEntry(path, "yamada", "山田"))
+	require.NoError(t, AddDictionaryEntry(path, "やまだ", "山Ｄ"))
+	require.NoError(t, AddDictionaryEntry(path, "yamada", "山田")) // moves to the front
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, userDictionaryHeader+"やまだ /山田/山Ｄ/\n", string(b))
+
+	tests := []struct {
+		name    string
+		reading string
+		word    string
+	}{
+		{"pending romaji", "yamad", "山田"},
+		{"not romaji", "golang", "Go"},
+		{"katakana", "ヤマダ", "山田"},
+		{"empty word", "yamada", ""},
+		{"slash", "yamada", "a/b"},
+		{"space", "yamada", "山 田"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, AddDictionaryEntry(path, tt.reading, tt.word))
+		})
+	}
+}
+
+func TestAddDictionaryEntry_KeepsEncoding(t *testing.T) {
+	// An EUC-JP dictionary declared by its header, holding only ASCII so far
+	header := ";; -*- mode: fundamental; coding: euc-jp -*-\n"
+	path := filepath.Join(t.TempDir(), "SKK-JISYO.user")
+	require.NoError(t, os.WriteFile(path, []byte(header), 0o644))
+
+	require.NoError(t, AddDictionaryEntry(path, "yamada", "山田"))
+	require.NoError(t, AddDictionaryEntry(path, "suzuki", "鈴木"))
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	want, err := japanese.EUCJP.NewEncoder().Bytes([]byte(header + "やまだ /山田/\nすずき /鈴木/\n"))
+	require.NoError(t, err)
+	assert.Equal(t, want, b)
+
+	assert.Error(t, AddDictionaryEntry(path, "yamada", "😀"), "words EUC-JP cannot hold are refused")
+}
+
+func TestNewRomajiConverter_Dictionaries(t *testing.T) {
+	dir := t.TempDir()
+	user := filepath.Join(dir, "user")
+	require.NoError(t, os.WriteFile(user, []byte("かいぎ /カイギ会/\nやまだ /山田/\n"), 0o644))
+
+	// The SKK project distributes dictionaries in EUC-JP
+	eucjp, err := japanese.EUCJP.NewEncoder().Bytes([]byte("かいぎ /界議/カイギ会/\nすずき /鈴木/\n"))
+	require.NoError(t, err)
+	extra := filepath.Join(dir, "extra")
+	require.NoError(t, os.WriteFile(extra, eucjp, 0o644))
+
+	rc, err := NewRomajiConverter(user, extra, filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"カイギ会", "界議"}, rc.dict["かいぎ"][:2], "dictionaries merge in order of precedence")
+	assert.Contains(t, rc.Convert("yamada"), "山田")
+	assert.Contains(t, rc.Convert("suzuki"), "鈴木")
+	assert.Contains(t, rc.Convert("yamad"), "山田", "user readings are completed")
+}
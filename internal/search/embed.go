@@ -6,3 +6,6 @@ import (
 
 //go:embed assets/SKK-JISYO.L
 var skkDictData []byte
+
+//go:embed assets/pinyin.txt
+var pinyinTableData []byte
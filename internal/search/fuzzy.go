@@ -0,0 +1,231 @@
+package search
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sahilm/fuzzy"
+)
+
+// Fuzzy is how a pattern fuzzily matched a text
+type Fuzzy struct {
+	Score     int   // higher is better
+	Positions []int // byte offsets of the matched characters in the text, ascending
+}
+
+// Base scores of the ways a pattern word can match. A substring always beats a
+// subsequence, which always beats a typo.
+const (
+	fuzzyScoreSubstring   = 100
+	fuzzyScoreSubsequence = 50
+	fuzzyScoreTypo        = 25
+)
+
+// FuzzyMatch matches every word of pattern against text, ignoring case. A word
+// matches as a substring, as a subsequence of a single word of text starting at
+// its first character ("mtng" in "meeting"), or as a word of text with a typo or
+// two ("meetnig"). An empty pattern matches everything.
+func FuzzyMatch(pattern, text string) (Fuzzy, bool) {
+	words := textWords(text)
+
+	var f Fuzzy
+	for _, word := range strings.Fields(pattern) {
+		wf, ok := fuzzyMatchWord(word, text, words)
+		if !ok {
+			return Fuzzy{}, false
+		}
+		f.Score += wf.Score
+		f.Positions = append(f.Positions, wf.Positions...)
+	}
+
+	sort.Ints(f.Positions)
+	f.Positions = uniqueInts(f.Positions)
+	return f, true
+}
+
+// FuzzyMatchAny returns the best fuzzy match of any of the variations of a term in text
+func FuzzyMatchAny(variations []string, text string) (Fuzzy, bool) {
+	var best Fuzzy
+	found := false
+	for _, v := range variations {
+		if f, ok := FuzzyMatch(v, text); ok && (!found || f.Score > best.Score) {
+			best, found = f, true
+		}
+	}
+	return best, found
+}
+
+// textWord is a run of letters and digits in a text
+type textWord struct {
+	start int // byte offset in the text
+	text  string
+}
+
+func textWords(text string) []textWord {
+	var words []textWord
+	start := -1
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case isWord && start < 0:
+			start = i
+		case !isWord && start >= 0:
+			words = append(words, textWord{start, text[start:i]})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		words = append(words, textWord{start, text[start:]})
+	}
+	return words
+}
+
+func fuzzyMatchWord(word, text string, words []textWord) (Fuzzy, bool) {
+	if i := indexFold(text, word); i >= 0 {
+		score := fuzzyScoreSubstring
+		for _, w := range words {
+			if w.start == i {
+				score += 20 // starts a word
+				if strings.EqualFold(w.text, word) {
+					score += 10 // is the word
+				}
+				break
+			}
+		}
+		return Fuzzy{Score: score, Positions: runeOffsets(text, i, i+len(word))}, true
+	}
+
+	wordRunes := []rune(strings.ToLower(word))
+	if len(wordRunes) == 0 {
+		return Fuzzy{}, true
+	}
+
+	// Subsequence of a single word
+	var best *Fuzzy
+	for _, w := range words {
+		first, _ := utf8.DecodeRuneInString(w.text)
+		if unicode.ToLower(first) != wordRunes[0] {
+			continue
+		}
+		matches := fuzzy.Find(word, []string{w.text})
+		if len(matches) == 0 {
+			continue
+		}
+		score := min(max(fuzzyScoreSubsequence+matches[0].Score, fuzzyScoreTypo+1), fuzzyScoreSubstring-1)
+		if best == nil || score > best.Score {
+			positions := make([]int, len(matches[0].MatchedIndexes))
+			for i, p := range matches[0].MatchedIndexes {
+				positions[i] = w.start + p
+			}
+			best = &Fuzzy{Score: score, Positions: positions}
+		}
+	}
+	if best != nil {
+		return *best, true
+	}
+
+	// A word with typos
+	allowed := maxTypos(len(wordRunes))
+	bestDistance := allowed + 1
+	var bestWord textWord
+	for _, w := range words {
+		wRunes := []rune(strings.ToLower(w.text))
+		if abs(len(wRunes)-len(wordRunes)) > allowed {
+			continue
+		}
+		if d := editDistance(wordRunes, wRunes); d < bestDistance {
+			bestDistance, bestWord = d, w
+		}
+	}
+	if bestDistance <= allowed {
+		return Fuzzy{
+			Score:     fuzzyScoreTypo - 10*bestDistance,
+			Positions: runeOffsets(text, bestWord.start, bestWord.start+len(bestWord.text)),
+		}, true
+	}
+	return Fuzzy{}, false
+}
+
+// maxTypos is the number of edits tolerated in a word of n characters. Short
+// words must be typed correctly, or they would match almost anything.
+func maxTypos(n int) int {
+	switch {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistance is the optimal string alignment distance between a and b: the
+// number of insertions, deletions, substitutions and transpositions of adjacent
+// characters turning a into b
+func editDistance(a, b []rune) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+// indexFold returns the byte offset of the first occurrence of substr in s, ignoring case, or -1
+func indexFold(s, substr string) int {
+	if substr == "" {
+		return 0
+	}
+	for i := range s {
+		if len(s)-i < len(substr) {
+			break
+		}
+		if strings.EqualFold(s[i:i+len(substr)], substr) {
+			return i
+		}
+	}
+	return -1
+}
+
+// runeOffsets returns the byte offsets of the runes of s[start:end]
+func runeOffsets(s string, start, end int) []int {
+	var offsets []int
+	for i := range s[start:end] {
+		offsets = append(offsets, start+i)
+	}
+	return offsets
+}
+
+func uniqueInts(sorted []int) []int {
+	out := sorted[:0]
+	for _, v := range sorted {
+		if len(out) == 0 || v != out[len(out)-1] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
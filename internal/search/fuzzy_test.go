@@ -0,0 +1,66 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	testCases := []struct {
+		name          string
+		pattern       string
+		text          string
+		wantMatch     bool
+		wantPositions []int
+	}{
+		{name: "substring", pattern: "note", text: "Meeting Notes", wantMatch: true, wantPositions: []int{8, 9, 10, 11}},
+		{name: "abbreviated words", pattern: "mtng nts", text: "meeting notes", wantMatch: true, wantPositions: []int{0, 3, 5, 6, 8, 10, 12}},
+		{name: "transposed letters", pattern: "meetnig", text: "meeting notes", wantMatch: true, wantPositions: []int{0, 1, 2, 3, 4, 5, 6}},
+		{name: "missing letter", pattern: "meting", text: "meeting notes", wantMatch: true},
+		{name: "japanese", pattern: "会議", text: "定例会議メモ", wantMatch: true, wantPositions: []int{6, 9}},
+		{name: "subsequence across words", pattern: "mo", text: "meeting notes", wantMatch: false},
+		{name: "short words need no typos", pattern: "nots", text: "meeting notes", wantMatch: true},
+		{name: "too many typos", pattern: "mitnig", text: "meeting notes", wantMatch: false},
+		{name: "short word typo", pattern: "nte", text: "net", wantMatch: false},
+		{name: "every word must match", pattern: "meeting absent", text: "meeting notes", wantMatch: false},
+		{name: "empty pattern", pattern: "", text: "anything", wantMatch: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f, ok := FuzzyMatch(tc.pattern, tc.text)
+			assert.Equal(t, tc.wantMatch, ok)
+			if tc.wantPositions != nil {
+				assert.Equal(t, tc.wantPositions, f.Positions)
+			}
+		})
+	}
+}
+
+func TestFuzzyMatch_Scores(t *testing.T) {
+	score := func(pattern, text string) int {
+		t.Helper()
+		f, ok := FuzzyMatch(pattern, text)
+		if !ok {
+			t.Fatalf("expected %q to match %q", pattern, text)
+		}
+		return f.Score
+	}
+
+	// A whole word beats a word prefix, which beats a substring elsewhere
+	assert.Greater(t, score("note", "note"), score("note", "notes"))
+	assert.Greater(t, score("note", "notes"), score("note", "denote"))
+	// A substring beats a subsequence, which beats a typo
+	assert.Greater(t, score("meet", "meeting"), score("mtng", "meeting"))
+	assert.Greater(t, score("mtng", "meeting"), score("meetnig", "meeting"))
+}
+
+func TestFuzzyMatchAny(t *testing.T) {
+	f, ok := FuzzyMatchAny([]string{"kaigi", "会議"}, "定例会議")
+	assert.True(t, ok)
+	assert.Equal(t, []int{6, 9}, f.Positions)
+
+	_, ok = FuzzyMatchAny([]string{"absent"}, "定例会議")
+	assert.False(t, ok)
+}
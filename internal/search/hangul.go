@@ -0,0 +1,115 @@
+package search
+
+import "strings"
+
+// HangulConverter handles conversion between Hangul and its Revised
+// Romanization. Syllables are romanized one by one, without the sound changes
+// between them, which is also how most people type Korean in Latin letters.
+type HangulConverter struct{}
+
+// Jamo of a precomposed Hangul syllable, which is
+// hangulBase + (initial*len(medials)+medial)*len(finals) + final
+const hangulBase = 0xAC00
+
+var (
+	hangulInitials = []string{"g", "kk", "n", "d", "tt", "r", "m", "b", "pp", "s", "ss", "", "j", "jj", "ch", "k", "t", "p", "h"}
+	hangulMedials  = []string{"a", "ae", "ya", "yae", "eo", "e", "yeo", "ye", "o", "wa", "wae", "oe", "yo", "u", "wo", "we", "wi", "yu", "eu", "ui", "i"}
+	hangulFinals   = []string{"", "k", "k", "k", "n", "n", "n", "t", "l", "k", "m", "l", "l", "l", "p", "l", "m", "p", "p", "t", "t", "ng", "t", "t", "k", "t", "p", "t"}
+)
+
+// hangulSilentInitial is the index of ㅇ, the initial of syllables starting
+// with a vowel
+const hangulSilentInitial = 11
+
+// hangulFinalOf maps a romanized final consonant to the jamo most often
+// spelling it
+var hangulFinalOf = map[string]int{"k": 1, "n": 4, "l": 8, "m": 16, "p": 17, "t": 19, "ng": 21}
+
+// NewHangulConverter creates a new HangulConverter instance
+func NewHangulConverter() *HangulConverter {
+	return &HangulConverter{}
+}
+
+// Convert converts a romanized query ("hanguk") to Hangul (한국), and Hangul
+// to its romanization, so notes in either script match
+func (hc *HangulConverter) Convert(query string) []string {
+	results := []string{query}
+	if romaji, ok := romanizeHangul(query); ok {
+		return append(results, romaji)
+	}
+	if hangul, ok := composeHangul(Normalize(query)); ok {
+		results = append(results, hangul)
+	}
+	return removeDuplicates(results)
+}
+
+// romanizeHangul spells the Hangul syllables of s in Revised Romanization. ok
+// is false unless s holds Hangul.
+func romanizeHangul(s string) (string, bool) {
+	var sb strings.Builder
+	found := false
+	for _, r := range s {
+		i := int(r) - hangulBase
+		if i < 0 || i >= len(hangulInitials)*len(hangulMedials)*len(hangulFinals) {
+			sb.WriteRune(r)
+			continue
+		}
+		found = true
+		final := i % len(hangulFinals)
+		medial := i / len(hangulFinals) % len(hangulMedials)
+		initial := i / len(hangulFinals) / len(hangulMedials)
+		sb.WriteString(hangulInitials[initial] + hangulMedials[medial] + hangulFinals[final])
+	}
+	return sb.String(), found
+}
+
+// composeHangul spells romanized Korean in Hangul. Each syllable is an
+// optional initial, a vowel and an optional final, read longest first; a
+// consonant followed by a vowel starts the next syllable. ok is false unless
+// all of romaji reads as syllables.
+func composeHangul(romaji string) (string, bool) {
+	var sb strings.Builder
+	for rest := romaji; rest != ""; {
+		initial, n := longestJamo(rest, hangulInitials)
+		if initial < 0 {
+			initial = hangulSilentInitial
+			if strings.HasPrefix(rest, "l") {
+				initial, n = 5, 1 // ㄹ, as in loanwords
+			}
+		}
+		medial, m := longestJamo(rest[n:], hangulMedials)
+		if medial < 0 {
+			return "", false
+		}
+		rest = rest[n+m:]
+
+		final := 0
+		for _, f := range []string{"ng", "k", "n", "l", "m", "p", "t"} {
+			after, ok := strings.CutPrefix(rest, f)
+			if !ok {
+				continue
+			}
+			if next, _ := longestJamo(after, hangulMedials); next < 0 {
+				final, rest = hangulFinalOf[f], after
+				break
+			}
+		}
+		sb.WriteRune(rune(hangulBase + (initial*len(hangulMedials)+medial)*len(hangulFinals) + final))
+	}
+	if sb.Len() == 0 {
+		return "", false
+	}
+	return sb.String(), true
+}
+
+// longestJamo returns the index and length of the longest of jamo starting s,
+// or -1
+func longestJamo(s string, jamo []string) (index, length int) {
+	index = -1
+	for i, j := range jamo {
+		if j != "" && len(j) > length && strings.HasPrefix(s, j) {
+			index, length = i, len(j)
+		}
+	}
+	return index, length
+}
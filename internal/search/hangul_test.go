@@ -0,0 +1,29 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHangulConverter_Convert(t *testing.T) {
+	hc := NewHangulConverter()
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"hanguk", []string{"hanguk", "한국"}},
+		{"Seoul", []string{"Seoul", "서울"}},
+		{"annyeong", []string{"annyeong", "안녕"}},
+		{"gimchi", []string{"gimchi", "김치"}},
+		{"한국", []string{"한국", "hanguk"}},
+		{"회의록", []string{"회의록", "hoeuirok"}},
+		{"test", []string{"test"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			assert.Equal(t, tt.want, hc.Convert(tt.query))
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// historyLimit is the number of queries a History keeps
+const historyLimit = 100
+
+// History is the list of queries searched, oldest first, kept in a file with
+// one query per line
+type History struct {
+	path    string
+	queries []string
+}
+
+// LoadHistory reads the history kept at path. A missing file is an empty
+// history, and an empty path one that is not saved.
+func LoadHistory(path string) (*History, error) {
+	h := &History{path: path}
+	if path == "" {
+		return h, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read search history %s: %w", path, err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			h.queries = append(h.queries, line)
+		}
+	}
+	return h, nil
+}
+
+// Queries returns the queries, oldest first
+func (h *History) Queries() []string {
+	return h.queries
+}
+
+// Add records query as the newest and saves the history. A query searched
+// before moves to the end, and the oldest are dropped beyond historyLimit.
+func (h *History) Add(query string) error {
+	query = strings.Join(strings.Fields(query), " ")
+	if query == "" {
+		return nil
+	}
+	h.queries = slices.DeleteFunc(h.queries, func(q string) bool { return q == query })
+	h.queries = append(h.queries, query)
+	if len(h.queries) > historyLimit {
+		h.queries = h.queries[len(h.queries)-historyLimit:]
+	}
+
+	if h.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create search history directory: %w", err)
+	}
+	data := strings.Join(h.queries, "\n") + "\n"
+	if err := os.WriteFile(h.path, []byte(data), 0o644); err != nil {
+		return fmt.Errorf("failed to write search history %s: %w", h.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package search
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config", "search_history")
+
+	h, err := LoadHistory(path)
+	require.NoError(t, err)
+	assert.Empty(t, h.Queries())
+
+	require.NoError(t, h.Add("kaigi"))
+	require.NoError(t, h.Add("  cat:work   deploy "))
+	require.NoError(t, h.Add(""))
+	require.NoError(t, h.Add("kaigi")) // moves to the end
+	assert.Equal(t, []string{"cat:work deploy", "kaigi"}, h.Queries())
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "cat:work deploy\nkaigi\n", string(b))
+
+	reloaded, err := LoadHistory(path)
+	require.NoError(t, err)
+	assert.Equal(t, h.Queries(), reloaded.Queries())
+
+	for i := range historyLimit + 5 {
+		require.NoError(t, h.Add(fmt.Sprintf("query %d", i)))
+	}
+	assert.Len(t, h.Queries(), historyLimit)
+	assert.Equal(t, fmt.Sprintf("query %d", historyLimit+4), h.Queries()[historyLimit-1])
+
+	// Without a path the history is kept in memory only
+	mem, err := LoadHistory("")
+	require.NoError(t, err)
+	require.NoError(t, mem.Add("kaigi"))
+	assert.Equal(t, []string{"kaigi"}, mem.Queries())
+}
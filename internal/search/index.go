@@ -0,0 +1,655 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hirotoni/memov2/internal/common"
+	"github.com/hirotoni/memov2/internal/domain"
+	"github.com/hirotoni/memov2/internal/domain/markdown"
+	"github.com/hirotoni/memov2/internal/platform"
+)
+
+const (
+	// IndexDirName is the directory under the base directory holding memov2's caches
+	IndexDirName = ".memov2"
+
+	// indexVersion is bumped whenever the on-disk format changes; older indexes are rebuilt
+	indexVersion = 6
+)
+
+// IndexPath returns the path of the memo search index for baseDir
+func IndexPath(baseDir string) string {
+	return filepath.Join(baseDir, IndexDirName, "memos.idx")
+}
+
+// Loader parses the memo file at path
+type Loader func(path string, info os.FileInfo) (domain.MemoFileInterface, error)
+
+// Index is a persistent inverted index over the memo files under a directory.
+// Documents are keyed by path, modification time and size, so a refresh only
+// re-parses files that changed since the index was last saved.
+//
+// Queries are matched by substring, as in SearchMemo, so the index maps the
+// unigrams and bigrams of each document to the documents holding them. A
+// document can only contain a query if it holds every gram of the query.
+//
+// With WithTodos, the index also keeps the tasks of the daily todo files. They
+// are few and short, so they are stored as they are and searched one by one.
+type Index struct {
+	path     string // where the index is stored
+	root     string // directory holding the memos
+	todoRoot string // directory holding the todo files, "" if they are not searched
+	load     Loader
+
+	mu        sync.Mutex
+	opened    bool
+	refreshed time.Time // when the files were last checked
+	data      indexData
+}
+
+// indexData is the part of the index stored on disk
+type indexData struct {
+	Version  int
+	NextID   uint32
+	Docs     map[uint32]*indexDoc
+	Postings map[string][]uint32 // gram -> sorted document ids
+	Todos    map[string]*todoDoc // relative path -> todo file
+}
+
+// todoDoc is a daily todo file as stored in the index
+type todoDoc struct {
+	ModTime int64
+	Size    int64
+	Tasks   []Task
+}
+
+// indexDoc is a memo as stored in the index
+type indexDoc struct {
+	Path        string // relative to the root
+	ModTime     int64
+	Size        int64
+	Invalid     bool // the file could not be parsed
+	Length      int  // runes of searchable text
+	Date        time.Time
+	Title       string
+	Category    []string
+	Frontmatter string
+	TopLevel    *markdown.HeadingBlock
+	Headings    []*markdown.HeadingBlock
+
+	memo domain.MemoFileInterface // rebuilt on demand, not stored
+}
+
+// NewIndex returns an index over the memos under root, stored at path.
+// Nothing is read until the first Refresh.
+func NewIndex(path, root string, load Loader) *Index {
+	return &Index{path: path, root: root, load: load}
+}
+
+// WithTodos makes the index keep the tasks of the daily todo files under root,
+// so that searches find them as well as memos. It returns idx.
+func (idx *Index) WithTodos(root string) *Index {
+	idx.todoRoot = root
+	return idx
+}
+
+// Refresh brings the index up to date with the files under the root, parsing
+// new and changed files and dropping removed ones. The index is saved if anything changed.
+func (idx *Index) Refresh() error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if !idx.opened {
+		idx.open()
+	}
+
+	reg, err := regexp.Compile(domain.FileNameRegexMemo)
+	if err != nil {
+		return common.Wrap(err, common.ErrorTypeRepository, "invalid regex pattern")
+	}
+
+	byPath := make(map[string]uint32, len(idx.data.Docs))
+	for id, doc := range idx.data.Docs {
+		byPath[doc.Path] = id
+	}
+
+	changed := false
+	seen := make(map[string]bool, len(byPath))
+	if platform.Exists(idx.root) {
+		err = filepath.Walk(idx.root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return common.Wrap(err, common.ErrorTypeFileSystem, "error walking path")
+			}
+			if info.IsDir() || !reg.MatchString(info.Name()) {
+				return nil
+			}
+
+			rel, err := filepath.Rel(idx.root, path)
+			if err != nil {
+				return common.Wrap(err, common.ErrorTypeFileSystem, "error getting relative path")
+			}
+			seen[rel] = true
+
+			id, ok := byPath[rel]
+			if ok {
+				doc := idx.data.Docs[id]
+				if doc.ModTime == info.ModTime().UnixNano() && doc.Size == info.Size() {
+					return nil
+				}
+				idx.remove(id)
+			}
+
+			idx.add(idx.parse(path, rel, info))
+			changed = true
+			return nil
+		})
+		if err != nil {
+			return common.Wrap(err, common.ErrorTypeRepository, "error scanning memos for search index")
+		}
+	}
+
+	for path, id := range byPath {
+		if !seen[path] {
+			idx.remove(id)
+			changed = true
+		}
+	}
+
+	if idx.todoRoot != "" {
+		todosChanged, err := idx.refreshTodos()
+		if err != nil {
+			return err
+		}
+		changed = changed || todosChanged
+	}
+	idx.refreshed = time.Now()
+
+	if changed {
+		return idx.save()
+	}
+	return nil
+}
+
+// todoDateRegex finds the date a todo file name starts with
+var todoDateRegex = regexp.MustCompile(domain.FileNameDateTimeRegexTodo)
+
+// refreshTodos reads the tasks of new and changed todo files and drops removed
+// ones, reporting whether anything changed
+func (idx *Index) refreshTodos() (bool, error) {
+	reg, err := regexp.Compile(domain.FileNameRegexTodo)
+	if err != nil {
+		return false, common.Wrap(err, common.ErrorTypeRepository, "invalid regex pattern")
+	}
+
+	changed := false
+	seen := make(map[string]bool, len(idx.data.Todos))
+	if platform.Exists(idx.todoRoot) {
+		err = filepath.Walk(idx.todoRoot, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return common.Wrap(err, common.ErrorTypeFileSystem, "error walking path")
+			}
+			if info.IsDir() || !reg.MatchString(info.Name()) {
+				return nil
+			}
+
+			rel, err := filepath.Rel(idx.todoRoot, path)
+			if err != nil {
+				return common.Wrap(err, common.ErrorTypeFileSystem, "error getting relative path")
+			}
+			seen[rel] = true
+
+			if doc, ok := idx.data.Todos[rel]; ok && doc.ModTime == info.ModTime().UnixNano() && doc.Size == info.Size() {
+				return nil
+			}
+			idx.data.Todos[rel] = readTodo(path, rel, info)
+			changed = true
+			return nil
+		})
+		if err != nil {
+			return false, common.Wrap(err, common.ErrorTypeRepository, "error scanning todos for search index")
+		}
+	}
+
+	for rel := range idx.data.Todos {
+		if !seen[rel] {
+			delete(idx.data.Todos, rel)
+			changed = true
+		}
+	}
+	return changed, nil
+}
+
+// readTodo reads the tasks of the todo file at path. A file that cannot be
+// read has none until it changes.
+func readTodo(path, rel string, info os.FileInfo) *todoDoc {
+	doc := &todoDoc{
+		ModTime: info.ModTime().UnixNano(),
+		Size:    info.Size(),
+	}
+
+	date, err := time.Parse(domain.FileNameDateLayoutTodo, todoDateRegex.FindString(info.Name()))
+	if err != nil {
+		return doc
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return doc
+	}
+	for _, t := range markdown.ParseTasks(string(b)) {
+		doc.Tasks = append(doc.Tasks, Task{Task: t, Path: rel, Date: date})
+	}
+	return doc
+}
+
+// open loads the stored index. A missing, unreadable or outdated index is
+// discarded and rebuilt by the refresh.
+func (idx *Index) open() {
+	idx.opened = true
+	idx.data = indexData{
+		Version:  indexVersion,
+		Docs:     make(map[uint32]*indexDoc),
+		Postings: make(map[string][]uint32),
+		Todos:    make(map[string]*todoDoc),
+	}
+
+	b, err := os.ReadFile(idx.path)
+	if err != nil {
+		return
+	}
+
+	var data indexData
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&data); err != nil || data.Version != indexVersion {
+		return
+	}
+	if data.Docs != nil {
+		idx.data.NextID = data.NextID
+		idx.data.Docs = data.Docs
+	}
+	if data.Postings != nil {
+		idx.data.Postings = data.Postings
+	}
+	if data.Todos != nil {
+		idx.data.Todos = data.Todos
+	}
+}
+
+func (idx *Index) save() error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&idx.data); err != nil {
+		return common.Wrap(err, common.ErrorTypeRepository, "failed to encode search index")
+	}
+	if err := platform.WriteFileAtomic(idx.path, buf.Bytes()); err != nil {
+		return common.Wrap(err, common.ErrorTypeRepository, fmt.Sprintf("failed to save search index: %s", idx.path))
+	}
+	return nil
+}
+
+// parse reads the memo at path into a document
+func (idx *Index) parse(path, rel string, info os.FileInfo) *indexDoc {
+	doc := &indexDoc{
+		Path:    rel,
+		ModTime: info.ModTime().UnixNano(),
+		Size:    info.Size(),
+	}
+
+	m, err := idx.load(path, info)
+	if err != nil {
+		// Remember the file so it is not parsed again until it changes
+		doc.Invalid = true
+		return doc
+	}
+
+	doc.Date = m.Date()
+	doc.Title = m.Title()
+	doc.Category = m.CategoryTree()
+	doc.TopLevel = m.TopLevelBodyContent()
+	doc.Headings = m.HeadingBlocks()
+	doc.Frontmatter = m.Frontmatter().YAML()
+	doc.Length = len([]rune(doc.text()))
+	doc.memo = m
+	return doc
+}
+
+// add stores doc under a new id and indexes its grams. Ids only grow, so
+// postings stay sorted by appending.
+func (idx *Index) add(doc *indexDoc) {
+	id := idx.data.NextID
+	idx.data.NextID++
+	idx.data.Docs[id] = doc
+
+	for gram := range grams(doc.text()) {
+		idx.data.Postings[gram] = append(idx.data.Postings[gram], id)
+	}
+}
+
+// remove drops the document with id and its grams
+func (idx *Index) remove(id uint32) {
+	doc := idx.data.Docs[id]
+	delete(idx.data.Docs, id)
+
+	for gram := range grams(doc.text()) {
+		ids := idx.data.Postings[gram]
+		i := sort.Search(len(ids), func(i int) bool { return ids[i] >= id })
+		if i == len(ids) || ids[i] != id {
+			continue
+		}
+		ids = append(ids[:i], ids[i+1:]...)
+		if len(ids) == 0 {
+			delete(idx.data.Postings, gram)
+		} else {
+			idx.data.Postings[gram] = ids
+		}
+	}
+}
+
+// Memos returns every indexed memo, oldest first
+func (idx *Index) Memos() []domain.MemoFileInterface {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	var ids []uint32
+	for id := range idx.data.Docs {
+		ids = append(ids, id)
+	}
+	return idx.memos(ids)
+}
+
+// Tasks returns the tasks of every indexed todo file, oldest first and in the
+// order of each file
+func (idx *Index) Tasks() []Task {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	var tasks []Task
+	for _, doc := range idx.data.Todos {
+		tasks = append(tasks, doc.Tasks...)
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		if !tasks[i].Date.Equal(tasks[j].Date) {
+			return tasks[i].Date.Before(tasks[j].Date)
+		}
+		if tasks[i].Path != tasks[j].Path {
+			return tasks[i].Path < tasks[j].Path
+		}
+		return tasks[i].Line < tasks[j].Line
+	})
+	return tasks
+}
+
+// Candidates returns the memos that may match a query, oldest first. words holds,
+// for every word of the query, the variations any of which the memo must contain,
+// as returned by Query.RequiredWords. Without words every memo is a candidate.
+// Candidates still have to be checked with SearchMemos.
+func (idx *Index) Candidates(words [][]string) []domain.MemoFileInterface {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	var ids []uint32
+	if len(words) == 0 {
+		for id := range idx.data.Docs {
+			ids = append(ids, id)
+		}
+		return idx.memos(ids)
+	}
+
+	for i, variations := range words {
+		var union []uint32
+		for _, v := range variations {
+			union = unionIDs(union, idx.lookup(v))
+		}
+		if i == 0 {
+			ids = union
+		} else {
+			ids = intersectIDs(ids, union)
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+	}
+
+	return idx.memos(ids)
+}
+
+// Stats returns the stats of the indexed memos. DocFreq counts the memos holding
+// every gram of a variation, which may include a few that do not contain it.
+func (idx *Index) Stats() Stats {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	stats := Stats{
+		DocFreq: func(variations []string) int {
+			idx.mu.Lock()
+			defer idx.mu.Unlock()
+
+			var ids []uint32
+			for _, v := range variations {
+				ids = unionIDs(ids, idx.lookup(v))
+			}
+			return len(ids)
+		},
+	}
+	total := 0
+	for _, doc := range idx.data.Docs {
+		if !doc.Invalid {
+			stats.Docs++
+			total += doc.Length
+		}
+	}
+	if stats.Docs > 0 {
+		stats.AvgLength = float64(total) / float64(stats.Docs)
+	}
+	return stats
+}
+
+// Search refreshes the index and returns the memos and tasks matching q in
+// order. When nothing matches exactly, everything is searched again fuzzily, so
+// that typos and abbreviations such as "mtng nts" still find "meeting notes".
+// Fuzzy matches are never mixed with exact ones.
+func (idx *Index) Search(q *Query, order Order) ([]SearchResult, error) {
+	if err := idx.Refresh(); err != nil {
+		return nil, err
+	}
+	return idx.SearchContext(context.Background(), q, order)
+}
+
+// SearchContext is Search over the memos as of the last refresh, so searching
+// as you type does not touch the disk. It gives up with the error of ctx once
+// ctx is done.
+func (idx *Index) SearchContext(ctx context.Context, q *Query, order Order) ([]SearchResult, error) {
+	tasks := taskDocuments(idx.Tasks())
+	results, err := searchDocuments(ctx, append(memoDocuments(idx.Candidates(q.RequiredWords())), tasks...), q)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 && !q.Empty() {
+		q = q.Fuzzy()
+		if results, err = searchDocuments(ctx, append(memoDocuments(idx.Memos()), tasks...), q); err != nil {
+			return nil, err
+		}
+	}
+	Score(results, q, idx.Stats(), time.Now())
+	SortResults(results, order)
+	return results, nil
+}
+
+// RefreshStale refreshes the index unless it was refreshed within maxAge
+func (idx *Index) RefreshStale(maxAge time.Duration) error {
+	idx.mu.Lock()
+	fresh := !idx.refreshed.IsZero() && time.Since(idx.refreshed) < maxAge
+	idx.mu.Unlock()
+	if fresh {
+		return nil
+	}
+	return idx.Refresh()
+}
+
+// lookup returns the ids of the documents holding every gram of query
+func (idx *Index) lookup(query string) []uint32 {
+	var ids []uint32
+	first := true
+	for gram := range queryGrams(query) {
+		postings := idx.data.Postings[gram]
+		if first {
+			ids = append([]uint32(nil), postings...)
+			first = false
+		} else {
+			ids = intersectIDs(ids, postings)
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+	}
+	if first {
+		// An empty query matches everything
+		for id := range idx.data.Docs {
+			ids = append(ids, id)
+		}
+		slices.Sort(ids)
+	}
+	return ids
+}
+
+// memos rebuilds the memos for ids, oldest first
+func (idx *Index) memos(ids []uint32) []domain.MemoFileInterface {
+	var docs []*indexDoc
+	for _, id := range ids {
+		if doc := idx.data.Docs[id]; !doc.Invalid {
+			docs = append(docs, doc)
+		}
+	}
+	sort.Slice(docs, func(i, j int) bool {
+		if !docs[i].Date.Equal(docs[j].Date) {
+			return docs[i].Date.Before(docs[j].Date)
+		}
+		return docs[i].Path < docs[j].Path
+	})
+
+	memos := make([]domain.MemoFileInterface, 0, len(docs))
+	for _, doc := range docs {
+		m, err := doc.toMemo()
+		if err != nil {
+			continue
+		}
+		memos = append(memos, m)
+	}
+	return memos
+}
+
+func (doc *indexDoc) toMemo() (domain.MemoFileInterface, error) {
+	if doc.memo != nil {
+		return doc.memo, nil
+	}
+
+	fm, err := markdown.ParseFrontmatter([]byte(doc.Frontmatter))
+	if err != nil {
+		return nil, err
+	}
+	topLevel := doc.TopLevel
+	if topLevel == nil {
+		topLevel = &markdown.HeadingBlock{}
+	}
+	m, err := domain.MemoFileFromParsedData(doc.Date, doc.Title, doc.Category, fm, topLevel, doc.Headings)
+	if err != nil {
+		return nil, err
+	}
+	doc.memo = m
+	return m, nil
+}
+
+// text returns everything SearchMemo looks at, except tags
+func (doc *indexDoc) text() string {
+	return searchableText(doc.Title, doc.Category, doc.TopLevel, doc.Headings)
+}
+
+// memoText returns everything SearchMemo looks at in m, except tags
+func memoText(m domain.MemoFileInterface) string {
+	return searchableText(m.Title(), m.CategoryTree(), m.TopLevelBodyContent(), m.HeadingBlocks())
+}
+
+func searchableText(title string, category []string, topLevel *markdown.HeadingBlock, headings []*markdown.HeadingBlock) string {
+	var sb strings.Builder
+	sb.WriteString(title + "\n")
+	sb.WriteString(strings.Join(category, "/") + "\n")
+	if topLevel != nil {
+		sb.WriteString(topLevel.HeadingText + "\n" + topLevel.ContentText + "\n")
+	}
+	for _, hb := range headings {
+		sb.WriteString(hb.HeadingText + "\n" + hb.ContentText + "\n")
+	}
+	return sb.String()
+}
+
+// grams returns the distinct unigrams and bigrams of normalized text
+func grams(text string) map[string]struct{} {
+	runes := []rune(Normalize(text))
+	set := make(map[string]struct{})
+	for i, r := range runes {
+		set[string(r)] = struct{}{}
+		if i+1 < len(runes) {
+			set[string(runes[i:i+2])] = struct{}{}
+		}
+	}
+	return set
+}
+
+// queryGrams returns the grams a document must hold to contain query
+func queryGrams(query string) map[string]struct{} {
+	runes := []rune(Normalize(query))
+	if len(runes) == 1 {
+		return map[string]struct{}{string(runes): {}}
+	}
+	set := make(map[string]struct{})
+	for i := 0; i+1 < len(runes); i++ {
+		set[string(runes[i:i+2])] = struct{}{}
+	}
+	return set
+}
+
+func intersectIDs(a, b []uint32) []uint32 {
+	var out []uint32
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			out = append(out, a[i])
+			i++
+			j++
+		}
+	}
+	return out
+}
+
+func unionIDs(a, b []uint32) []uint32 {
+	out := make([]uint32, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] < b[j]:
+			out = append(out, a[i])
+			i++
+		case a[i] > b[j]:
+			out = append(out, b[j])
+			j++
+		default:
+			out = append(out, a[i])
+			i++
+			j++
+		}
+	}
+	out = append(out, a[i:]...)
+	return append(out, b[j:]...)
+}
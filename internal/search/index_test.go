@@ -0,0 +1,224 @@
+package search
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hirotoni/memov2/internal/domain"
+	"github.com/hirotoni/memov2/internal/domain/markdown"
+	"github.com/hirotoni/memov2/internal/repositories/memo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingLoader parses memo files and counts how many files were parsed
+func countingLoader(count *int) Loader {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	return func(path string, info os.FileInfo) (domain.MemoFileInterface, error) {
+		*count++
+		return memo.ParseMemoFile(path, info, logger)
+	}
+}
+
+func saveIndexTestMemo(t *testing.T, dir string, date time.Time, title string, category []string, content string) domain.MemoFileInterface {
+	t.Helper()
+
+	m, err := domain.NewMemoFile(date, title, category)
+	require.NoError(t, err)
+	m.SetHeadingBlocks([]*markdown.HeadingBlock{{Level: 2, HeadingText: "Notes", ContentText: content}})
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	require.NoError(t, memo.NewMemo(dir, logger).Save(m, true))
+	return m
+}
+
+func titles(memos []domain.MemoFileInterface) []string {
+	var ts []string
+	for _, m := range memos {
+		ts = append(ts, m.Title())
+	}
+	return ts
+}
+
+func TestIndex_Candidates(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	saveIndexTestMemo(t, dir, base, "golang notes", []string{"dev"}, "goroutines and channels\n")
+	saveIndexTestMemo(t, dir, base.Add(time.Hour), "会議メモ", []string{"work"}, "予定を確認した\n")
+	saveIndexTestMemo(t, dir, base.Add(2*time.Hour), "shopping", nil, "milk and eggs\n")
+
+	var parsed int
+	idx := NewIndex(filepath.Join(t.TempDir(), "memos.idx"), dir, countingLoader(&parsed))
+	require.NoError(t, idx.Refresh())
+	assert.Equal(t, 3, parsed)
+
+	testCases := []struct {
+		name  string
+		words [][]string
+		want  []string
+	}{
+		{name: "content word", words: [][]string{{"Channels"}}, want: []string{"golang-notes"}},
+		{name: "category", words: [][]string{{"work"}}, want: []string{"会議メモ"}},
+		{name: "japanese bigram", words: [][]string{{"確認"}}, want: []string{"会議メモ"}},
+		{name: "single character", words: [][]string{{"予"}}, want: []string{"会議メモ"}},
+		{name: "any variation", words: [][]string{{"milk", "goroutine"}}, want: []string{"golang-notes", "shopping"}},
+		{name: "every word", words: [][]string{{"and"}, {"eggs"}}, want: []string{"shopping"}},
+		{name: "no match", words: [][]string{{"missing"}}, want: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, titles(idx.Candidates(tc.words)))
+		})
+	}
+
+	assert.Len(t, idx.Memos(), 3)
+}
+
+func TestIndex_IncrementalRefresh(t *testing.T) {
+	dir := t.TempDir()
+	indexPath := filepath.Join(t.TempDir(), "memos.idx")
+	base := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	first := saveIndexTestMemo(t, dir, base, "first", nil, "alpha\n")
+	second := saveIndexTestMemo(t, dir, base.Add(time.Hour), "second", []string{"sub"}, "beta\n")
+
+	var parsed int
+	require.NoError(t, NewIndex(indexPath, dir, countingLoader(&parsed)).Refresh())
+	require.Equal(t, 2, parsed)
+	require.FileExists(t, indexPath)
+
+	// A new index loads the saved one and parses nothing
+	parsed = 0
+	idx := NewIndex(indexPath, dir, countingLoader(&parsed))
+	require.NoError(t, idx.Refresh())
+	assert.Equal(t, 0, parsed)
+	assert.Equal(t, []string{"second"}, titles(idx.Candidates([][]string{{"beta"}})))
+
+	// Only the changed file is parsed again
+	saveIndexTestMemo(t, dir, first.Date(), "first", nil, "alpha gamma\n")
+	require.NoError(t, idx.Refresh())
+	assert.Equal(t, 1, parsed)
+	assert.Equal(t, []string{"first"}, titles(idx.Candidates([][]string{{"gamma"}})))
+
+	// Removed files are dropped
+	require.NoError(t, os.Remove(filepath.Join(dir, second.Location(), second.FileName())))
+	require.NoError(t, idx.Refresh())
+	assert.Empty(t, idx.Candidates([][]string{{"beta"}}))
+	assert.Equal(t, []string{"first"}, titles(idx.Memos()))
+}
+
+func TestIndex_CorruptIndexIsRebuilt(t *testing.T) {
+	dir := t.TempDir()
+	indexPath := filepath.Join(t.TempDir(), "memos.idx")
+	saveIndexTestMemo(t, dir, time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC), "memo", nil, "content\n")
+	require.NoError(t, os.WriteFile(indexPath, []byte("not an index"), 0o644))
+
+	var parsed int
+	idx := NewIndex(indexPath, dir, countingLoader(&parsed))
+	require.NoError(t, idx.Refresh())
+	assert.Equal(t, 1, parsed)
+	assert.Equal(t, []string{"memo"}, titles(idx.Candidates([][]string{{"content"}})))
+}
+
+func TestIndex_Search(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	saveIndexTestMemo(t, dir, base, "meeting notes", []string{"work"}, "decided the roadmap\n")
+	saveIndexTestMemo(t, dir, base.Add(time.Hour), "monthly report", []string{"work"}, "meeting minutes attached\n")
+	saveIndexTestMemo(t, dir, base.Add(2*time.Hour), "shopping", nil, "milk and eggs\n")
+
+	var parsed int
+	idx := NewIndex(filepath.Join(t.TempDir(), "memos.idx"), dir, countingLoader(&parsed))
+
+	search := func(query string) []SearchResult {
+		t.Helper()
+		q, err := ParseQuery(query, nil)
+		require.NoError(t, err)
+		results, err := idx.Search(q, OrderRelevance)
+		require.NoError(t, err)
+		return results
+	}
+
+	testCases := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{name: "exact matches only", query: "meeting", want: []string{"meeting-notes", "monthly-report"}},
+		// Every memo has a "Notes" heading; the title match ranks first
+		{name: "abbreviations", query: "mtng nts", want: []string{"meeting-notes", "monthly-report"}},
+		{name: "typo", query: "roadmpa", want: []string{"meeting-notes"}},
+		{name: "negation stays exact", query: "mlk -egs", want: []string{"shopping"}},
+		{name: "no match", query: "zzz", want: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, resultTitles(search(tc.query)))
+		})
+	}
+
+	// Fuzzy matches record the matched characters
+	results := search("mtng")
+	require.Len(t, results, 2)
+	require.NotEmpty(t, results[0].Matches)
+	assert.Equal(t, MatchTitle, results[0].Matches[0].Type)
+	assert.Equal(t, []int{0, 3, 5, 6}, results[0].Matches[0].Positions)
+	assert.Greater(t, results[0].Score, 0.0)
+}
+
+func TestIndex_Todos(t *testing.T) {
+	memosDir, todosDir := t.TempDir(), t.TempDir()
+	base := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	saveIndexTestMemo(t, memosDir, base, "quarterly report", []string{"work"}, "numbers\n")
+	todoPath := filepath.Join(todosDir, "20250102Thu_todos.md")
+	require.NoError(t, os.WriteFile(todoPath, []byte("# 20250102Thu\n\n## todos\n\n- [ ] send the report\n- [x] call the bank\n"), 0o644))
+
+	var parsed int
+	indexPath := filepath.Join(t.TempDir(), "memos.idx")
+	idx := NewIndex(indexPath, memosDir, countingLoader(&parsed)).WithTodos(todosDir)
+
+	search := func(idx *Index, query string) []string {
+		t.Helper()
+		q, err := ParseQuery(query, nil)
+		require.NoError(t, err)
+		results, err := idx.Search(q, OrderDate)
+		require.NoError(t, err)
+		var found []string
+		for _, r := range results {
+			if r.Task != nil {
+				found = append(found, "task: "+r.Task.Text)
+			} else {
+				found = append(found, "memo: "+r.Memo.Title())
+			}
+		}
+		return found
+	}
+
+	// Tasks are searched along with memos, newest first
+	assert.Equal(t, []string{"task: send the report", "memo: quarterly-report"}, search(idx, "report"))
+	assert.Equal(t, []string{"task: call the bank"}, search(idx, "is:done"))
+	assert.Equal(t, []string{"task: send the report"}, search(idx, "is:task rpt"))
+	assert.Equal(t, []string{"5 send the report", "6 call the bank"}, func() []string {
+		var lines []string
+		for _, task := range idx.Tasks() {
+			lines = append(lines, fmt.Sprintf("%d %s", task.Line+1, task.Text))
+		}
+		return lines
+	}())
+
+	// Tasks are saved with the index and follow changes to the todo files
+	idx = NewIndex(indexPath, memosDir, countingLoader(&parsed)).WithTodos(todosDir)
+	assert.Equal(t, []string{"task: call the bank"}, search(idx, "is:done"))
+	require.NoError(t, os.WriteFile(todoPath, []byte("## todos\n\n- [x] send the report\n"), 0o644))
+	require.NoError(t, idx.Refresh())
+	assert.Equal(t, []string{"task: send the report"}, search(idx, "is:done"))
+	require.NoError(t, os.Remove(todoPath))
+	assert.Empty(t, search(idx, "is:task"))
+
+	// Without WithTodos only memos are searched
+	assert.Equal(t, []string{"memo: quarterly-report"}, search(NewIndex(filepath.Join(t.TempDir(), "memos.idx"), memosDir, countingLoader(&parsed)), "report"))
+}
@@ -0,0 +1,104 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+	"golang.org/x/text/width"
+)
+
+// Normalize folds the differences search ignores: case, full-width and
+// half-width forms (ＡＢＣ, ｶﾞ), and katakana and hiragana. Text and queries are
+// compared in normalized form.
+func Normalize(s string) string {
+	if isASCII(s) {
+		return strings.ToLower(s)
+	}
+	// Fold widths, then compose the voiced marks half-width katakana spell apart
+	s = norm.NFC.String(width.Fold.String(s))
+	return strings.Map(func(r rune) rune {
+		if isKatakana(r) {
+			return r - ('ア' - 'あ')
+		}
+		return unicode.ToLower(r)
+	}, s)
+}
+
+// isKatakana reports whether r is a katakana with a hiragana counterpart
+func isKatakana(r rune) bool {
+	return r >= 'ァ' && r <= 'ヶ'
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// hepburn holds the spelling of the kana romajiToHiragana spells several ways
+var hepburn = map[string]string{
+	"し": "shi", "ち": "chi", "つ": "tsu", "ふ": "fu", "じ": "ji", "ん": "n",
+	"しゃ": "sha", "しゅ": "shu", "しょ": "sho",
+	"ちゃ": "cha", "ちゅ": "chu", "ちょ": "cho",
+	"じゃ": "ja", "じゅ": "ju", "じょ": "jo",
+}
+
+// kanaToRomaji maps a kana to its romaji: the Hepburn spelling, else the
+// shortest one
+var kanaToRomaji = func() map[string]string {
+	m := make(map[string]string)
+	for romaji, kana := range romajiToHiragana {
+		if strings.HasPrefix(kana, "っ") || kana == "ー" {
+			continue
+		}
+		current, ok := m[kana]
+		if !ok || len(romaji) < len(current) || len(romaji) == len(current) && romaji < current {
+			m[kana] = romaji
+		}
+	}
+	for kana, romaji := range hepburn {
+		m[kana] = romaji
+	}
+	return m
+}()
+
+// toRomaji spells hiragana in Hepburn romaji. ok is false if it holds anything
+// but hiragana.
+func toRomaji(hiragana string) (romaji string, ok bool) {
+	runes := []rune(hiragana)
+	var sb strings.Builder
+	double := false // a small tsu doubles the next consonant
+	for i := 0; i < len(runes); {
+		if runes[i] == 'っ' {
+			double = true
+			i++
+			continue
+		}
+		var spelled string
+		for j := min(len(runes), i+2); j > i; j-- {
+			if r, found := kanaToRomaji[string(runes[i:j])]; found {
+				spelled = r
+				i = j
+				break
+			}
+		}
+		if spelled == "" {
+			return "", false
+		}
+		if double {
+			if strings.HasPrefix(spelled, "ch") {
+				sb.WriteByte('t')
+			} else if spelled[0] != 'a' && spelled[0] != 'i' && spelled[0] != 'u' && spelled[0] != 'e' && spelled[0] != 'o' {
+				sb.WriteByte(spelled[0])
+			}
+			double = false
+		}
+		sb.WriteString(spelled)
+	}
+	return sb.String(), true
+}
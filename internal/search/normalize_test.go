@@ -0,0 +1,49 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Meeting", "meeting"},
+		{"ＭＥＥＴＩＮＧ　１", "meeting 1"},
+		{"カイギ", "かいぎ"},
+		{"ｶｲｷﾞ", "かいぎ"},
+		{"ﾃﾞｰﾀﾍﾞｰｽ", "でーたべーす"},
+		{"会議メモ", "会議めも"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			assert.Equal(t, tt.want, Normalize(tt.in))
+		})
+	}
+}
+
+func TestToRomaji(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   string
+		wantOK bool
+	}{
+		{"かいぎ", "kaigi", true},
+		{"しゃしん", "shashin", true},
+		{"ちょっと", "chotto", true},
+		{"まっちゃ", "matcha", true},
+		{"ふじさん", "fujisan", true},
+		{"つくえ", "tsukue", true},
+		{"会議", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, ok := toRomaji(tt.in)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
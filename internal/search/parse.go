@@ -0,0 +1,445 @@
+package search
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Query is a parsed search query
+type Query struct {
+	root  node     // nil if the query has no terms
+	fuzzy bool     // text terms match as FuzzyMatch does
+	scope []string // category tree the memos must be in, nil for the whole vault
+}
+
+// Query qualifiers. A term without a qualifier matches the title, category,
+// headings or body.
+const (
+	fieldTitle    = "title"
+	fieldCategory = "cat"
+	fieldHeading  = "heading"
+	fieldBody     = "body"
+	fieldTag      = "tag"
+	fieldAfter    = "after"
+	fieldBefore   = "before"
+	fieldDate     = "date"
+	fieldWeek     = "week"
+	fieldIs       = "is"
+)
+
+// fieldAliases maps every accepted qualifier to its field
+var fieldAliases = map[string]string{
+	"title":    fieldTitle,
+	"cat":      fieldCategory,
+	"category": fieldCategory,
+	"heading":  fieldHeading,
+	"body":     fieldBody,
+	"tag":      fieldTag,
+	"after":    fieldAfter,
+	"before":   fieldBefore,
+	"date":     fieldDate,
+	"week":     fieldWeek,
+	"is":       fieldIs,
+}
+
+const dateLayout = "2006-01-02"
+
+// ParseQuery parses a search query. Terms are separated by whitespace and a memo
+// must match all of them:
+//
+//	word                 the title, category, a heading or the body contains word
+//	"two words"          the same, for a phrase
+//	title:word           only the title; likewise cat:, heading:, body: and tag:
+//	cat:work/projects    the category path contains work/projects
+//	after:2025-01-01     the memo was written on or after the day; likewise before: (exclusive) and date:
+//	week:2025-W07        the memo was written in the ISO week
+//	is:task              only tasks of the daily todo files; is:memo only memos,
+//	                     is:done and is:open tasks checked off or still to do
+//	-term                the term must not match
+//	a OR b               either side matches; binds looser than the implicit AND
+//	(a OR b) c           grouping
+//
+// Text terms are expanded by conv, so romaji also matches Japanese text, and
+// likewise for the other scripts conv knows. A nil conv matches terms as typed.
+// Unknown qualifiers are searched as plain text. A task matches text terms by
+// its text, heading: by the heading it is under and dates by its todo file's day.
+func ParseQuery(input string, conv Transliterator) (*Query, error) {
+	p := &queryParser{tokens: lexQuery(input), conv: conv}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	return &Query{root: root}, nil
+}
+
+// Empty reports whether the query has no terms
+func (q *Query) Empty() bool {
+	return q.root == nil
+}
+
+// Fuzzy returns a copy of the query whose text terms match fuzzily: as
+// subsequences of words ("mtng" finds "meeting") or with typos. Negated terms
+// still match exactly.
+func (q *Query) Fuzzy() *Query {
+	return &Query{root: q.root, fuzzy: true, scope: q.scope}
+}
+
+// Within returns a copy of the query matching only memos in category or its
+// subcategories. Tasks belong to no category, so they never match. An empty
+// category is the whole vault.
+func (q *Query) Within(category []string) *Query {
+	return &Query{root: q.root, fuzzy: q.fuzzy, scope: category}
+}
+
+// Scope returns the category the query is limited to, nil for the whole vault
+func (q *Query) Scope() []string {
+	return q.scope
+}
+
+// inScope reports whether d is in the category the query is limited to
+func (q *Query) inScope(d document) bool {
+	if len(q.scope) == 0 {
+		return true
+	}
+	if d.memo == nil {
+		return false
+	}
+	tree := d.memo.CategoryTree()
+	return len(tree) >= len(q.scope) && slices.Equal(tree[:len(q.scope)], q.scope)
+}
+
+// ParseCategory splits a category path written as work/projects into its
+// tree. Empty segments are dropped, so "" and "/" are the whole vault.
+func ParseCategory(path string) []string {
+	var tree []string
+	for _, segment := range strings.Split(path, "/") {
+		if segment = strings.TrimSpace(segment); segment != "" {
+			tree = append(tree, segment)
+		}
+	}
+	return tree
+}
+
+// RequiredWords returns the variations of the text terms every matching memo
+// must contain. The result is suitable for Index.Candidates.
+func (q *Query) RequiredWords() [][]string {
+	return requiredWords(q.root)
+}
+
+func requiredWords(n node) [][]string {
+	switch n := n.(type) {
+	case *textNode:
+		// Tags are not part of the indexed text
+		if n.field != fieldTag {
+			return [][]string{n.variations}
+		}
+	case andNode:
+		var words [][]string
+		for _, c := range n {
+			words = append(words, requiredWords(c)...)
+		}
+		return words
+	}
+	return nil
+}
+
+// Terms returns the variations of every text term that is not negated, for highlighting
+func (q *Query) Terms() []string {
+	var terms []string
+	seen := make(map[string]bool)
+	for _, t := range q.textTerms() {
+		for _, v := range t.variations {
+			if !seen[v] {
+				seen[v] = true
+				terms = append(terms, v)
+			}
+		}
+	}
+	return terms
+}
+
+// textTerms returns the text terms that are not negated
+func (q *Query) textTerms() []*textNode {
+	var terms []*textNode
+	var walk func(n node)
+	walk = func(n node) {
+		switch n := n.(type) {
+		case *textNode:
+			terms = append(terms, n)
+		case andNode:
+			for _, c := range n {
+				walk(c)
+			}
+		case orNode:
+			for _, c := range n {
+				walk(c)
+			}
+		}
+	}
+	walk(q.root)
+	return terms
+}
+
+type tokenKind int
+
+const (
+	tokTerm tokenKind = iota
+	tokNot
+	tokOr
+	tokOpen
+	tokClose
+)
+
+type token struct {
+	kind  tokenKind
+	field string // qualifier of a term, "" if there is none
+	text  string
+}
+
+// lexQuery splits a query into tokens. Unbalanced quotes and parentheses are
+// tolerated so that a query can be searched while it is being typed.
+func lexQuery(input string) []token {
+	var tokens []token
+	runes := []rune(input)
+	depth := 0
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case r == '(':
+			tokens = append(tokens, token{kind: tokOpen})
+			depth++
+			i++
+			continue
+		case r == ')' && depth > 0:
+			tokens = append(tokens, token{kind: tokClose})
+			depth--
+			i++
+			continue
+		case r == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]):
+			tokens = append(tokens, token{kind: tokNot})
+			i++
+			continue
+		}
+
+		// Qualifier
+		field := ""
+		if j := indexRune(runes[i:], ':'); j > 0 {
+			if f, ok := fieldAliases[strings.ToLower(string(runes[i:i+j]))]; ok {
+				field = f
+				i += j + 1
+			}
+		}
+
+		// Value
+		var text string
+		quoted := i < len(runes) && runes[i] == '"'
+		if quoted {
+			j := slices.Index(runes[i+1:], '"')
+			if j < 0 {
+				j = len(runes) - i - 1
+			}
+			text = string(runes[i+1 : i+1+j])
+			i += j + 2
+		} else {
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !(runes[i] == ')' && depth > 0) {
+				i++
+			}
+			text = string(runes[start:i])
+		}
+
+		if !quoted && field == "" && text == "OR" {
+			tokens = append(tokens, token{kind: tokOr})
+			continue
+		}
+		tokens = append(tokens, token{kind: tokTerm, field: field, text: text})
+	}
+	return tokens
+}
+
+// indexRune returns the index of r in the first word of runes, or -1
+func indexRune(runes []rune, r rune) int {
+	for i, c := range runes {
+		if c == r {
+			return i
+		}
+		if unicode.IsSpace(c) {
+			return -1
+		}
+	}
+	return -1
+}
+
+type queryParser struct {
+	tokens []token
+	pos    int
+	conv   Transliterator
+}
+
+func (p *queryParser) peek() (token, bool) {
+	if p.pos >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+// parseOr parses terms separated by OR
+func (p *queryParser) parseOr() (node, error) {
+	var alternatives orNode
+	for {
+		n, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		if n != nil {
+			alternatives = append(alternatives, n)
+		}
+
+		t, ok := p.peek()
+		if !ok || t.kind != tokOr {
+			break
+		}
+		p.pos++
+	}
+
+	switch len(alternatives) {
+	case 0:
+		return nil, nil
+	case 1:
+		return alternatives[0], nil
+	default:
+		return alternatives, nil
+	}
+}
+
+// parseAnd parses terms up to the next OR, closing parenthesis or the end
+func (p *queryParser) parseAnd() (node, error) {
+	var terms andNode
+	for {
+		t, ok := p.peek()
+		if !ok || t.kind == tokOr || t.kind == tokClose {
+			break
+		}
+		n, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if n != nil {
+			terms = append(terms, n)
+		}
+	}
+
+	switch len(terms) {
+	case 0:
+		return nil, nil
+	case 1:
+		return terms[0], nil
+	default:
+		return terms, nil
+	}
+}
+
+func (p *queryParser) parseUnary() (node, error) {
+	t, _ := p.peek()
+	p.pos++
+
+	switch t.kind {
+	case tokNot:
+		if next, ok := p.peek(); !ok || next.kind == tokOr || next.kind == tokClose {
+			return nil, nil
+		}
+		n, err := p.parseUnary()
+		if err != nil || n == nil {
+			return nil, err
+		}
+		return notNode{n}, nil
+	case tokOpen:
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if next, ok := p.peek(); ok && next.kind == tokClose {
+			p.pos++
+		}
+		return n, nil
+	default:
+		return p.term(t)
+	}
+}
+
+// term builds the node matching a single term
+func (p *queryParser) term(t token) (node, error) {
+	if t.text == "" {
+		// A qualifier still being typed
+		return nil, nil
+	}
+
+	switch t.field {
+	case fieldAfter, fieldBefore, fieldDate:
+		day, err := time.Parse(dateLayout, t.text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date in %s:%s, want YYYY-MM-DD", t.field, t.text)
+		}
+		switch t.field {
+		case fieldAfter:
+			return &dateNode{from: day}, nil
+		case fieldBefore:
+			return &dateNode{to: day}, nil
+		default:
+			return &dateNode{from: day, to: day.AddDate(0, 0, 1)}, nil
+		}
+	case fieldWeek:
+		monday, err := parseISOWeek(t.text)
+		if err != nil {
+			return nil, err
+		}
+		return &dateNode{from: monday, to: monday.AddDate(0, 0, 7)}, nil
+	case fieldIs:
+		kind, ok := isAliases[strings.ToLower(t.text)]
+		if !ok {
+			return nil, fmt.Errorf("invalid value in is:%s, want memo, task, done or open", t.text)
+		}
+		return isNode{kind: kind}, nil
+	}
+
+	variations := []string{t.text}
+	if p.conv != nil {
+		variations = p.conv.Convert(t.text)
+	}
+	return &textNode{field: t.field, variations: variations}, nil
+}
+
+// parseISOWeek returns the Monday starting an ISO week written as 2025-W07
+func parseISOWeek(s string) (time.Time, error) {
+	invalid := fmt.Errorf("invalid week in week:%s, want YYYY-Www", s)
+
+	year, week, ok := strings.Cut(strings.ToUpper(s), "-W")
+	if !ok {
+		return time.Time{}, invalid
+	}
+	y, err := strconv.Atoi(year)
+	if err != nil || len(year) != 4 {
+		return time.Time{}, invalid
+	}
+	w, err := strconv.Atoi(week)
+	if err != nil || w < 1 || w > 53 {
+		return time.Time{}, invalid
+	}
+
+	// January 4th is always in the first ISO week
+	jan4 := time.Date(y, time.January, 4, 0, 0, 0, 0, time.UTC)
+	monday := jan4.AddDate(0, 0, -((int(jan4.Weekday()) + 6) % 7))
+	monday = monday.AddDate(0, 0, (w-1)*7)
+	if _, got := monday.ISOWeek(); got != w {
+		return time.Time{}, invalid
+	}
+	return monday, nil
+}
@@ -0,0 +1,145 @@
+package search
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// PinyinConverter handles conversion between toneless pinyin and Chinese using
+// the embedded reading table
+type PinyinConverter struct {
+	dict     map[string][]string // pinyin -> hanzi mapping
+	readings map[string]string   // hanzi -> pinyin mapping, the first reading listed
+	trie     *readingTrie        // readings of dict, for completing and segmenting input
+}
+
+// minPinyinCompletion is the number of letters partial pinyin is completed
+// from; shorter prefixes start too many syllables to be useful
+const minPinyinCompletion = 3
+
+// NewPinyinConverter creates a new PinyinConverter instance and loads the
+// embedded reading table
+func NewPinyinConverter() (*PinyinConverter, error) {
+	pc := &PinyinConverter{
+		dict:     make(map[string][]string),
+		readings: make(map[string]string),
+		trie:     newReadingTrie(),
+	}
+	if err := pc.loadTable(pinyinTableData); err != nil {
+		return nil, fmt.Errorf("failed to load pinyin table: %w", err)
+	}
+	return pc, nil
+}
+
+// loadTable loads a reading table, written like an SKK dictionary:
+// pinyin /hanzi1/hanzi2/...
+func (pc *PinyinConverter) loadTable(data []byte) error {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, ";") || line == "" {
+			continue
+		}
+		pinyin, field, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+
+		candidates := parseCandidates(field)
+		pc.dict[pinyin] = mergeCandidates(pc.dict[pinyin], candidates)
+		pc.trie.insert(pinyin)
+		for _, c := range candidates {
+			if _, ok := pc.readings[c]; !ok {
+				pc.readings[c] = pinyin
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// Convert converts a pinyin query to possible Chinese candidates. Tone marks
+// and numbers are ignored ("huìyì", "hui4yi4"), pinyin still being typed
+// ("huiy") is completed, and pinyin missing from the table is split into
+// known syllables. Chinese goes the other way: 会议 also finds notes that say
+// huiyi.
+func (pc *PinyinConverter) Convert(query string) []string {
+	results := []string{query}
+
+	if containsHan(query) {
+		if pinyin, ok := pc.toPinyin(query); ok {
+			results = append(results, pinyin)
+		}
+		return results
+	}
+
+	pinyin := toneless(Normalize(query))
+	if pinyin == "" || strings.IndexFunc(pinyin, func(r rune) bool { return r < 'a' || r > 'z' }) >= 0 {
+		return results
+	}
+	results = append(results, pinyin)
+
+	candidates, found := pc.dict[pinyin]
+	results = append(results, candidates...)
+	if !found {
+		if len(pinyin) >= minPinyinCompletion {
+			for _, reading := range pc.trie.withPrefix(pinyin, maxCompletionReadings) {
+				results = append(results, pc.dict[reading]...)
+			}
+		}
+		// Every syllable must be known, so that English words are left alone
+		results = append(results, segmentations(pc.trie, pc.dict, pinyin, true)...)
+	}
+
+	return removeDuplicates(results)
+}
+
+// toPinyin spells Chinese in toneless pinyin, a word of the table at a time,
+// longest first. ok is false if some character has no reading.
+func (pc *PinyinConverter) toPinyin(hanzi string) (pinyin string, ok bool) {
+	runes := []rune(hanzi)
+	var sb strings.Builder
+	for i := 0; i < len(runes); {
+		j := len(runes)
+		for ; j > i; j-- {
+			if reading, found := pc.readings[string(runes[i:j])]; found {
+				sb.WriteString(reading)
+				break
+			}
+		}
+		if j == i {
+			return "", false
+		}
+		i = j
+	}
+	return sb.String(), true
+}
+
+// toneless removes the tone marks and numbers of pinyin and spells ü as v
+func toneless(pinyin string) string {
+	var out []rune
+	for _, r := range norm.NFD.String(pinyin) {
+		switch {
+		case r == '̈' && len(out) > 0 && out[len(out)-1] == 'u':
+			out[len(out)-1] = 'v'
+		case unicode.Is(unicode.Mn, r), r >= '1' && r <= '5':
+		default:
+			out = append(out, r)
+		}
+	}
+	return string(out)
+}
+
+// containsHan checks if the string contains Chinese characters
+func containsHan(s string) bool {
+	for _, r := range s {
+		if unicode.Is(unicode.Han, r) {
+			return true
+		}
+	}
+	return false
+}
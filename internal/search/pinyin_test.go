@@ -0,0 +1,43 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPinyinConverter_Convert(t *testing.T) {
+	pc, err := NewPinyinConverter()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		query    string
+		contains []string
+		excludes []string
+	}{
+		{name: "word", query: "huiyi", contains: []string{"会议", "回忆"}},
+		{name: "tone marks", query: "huìyì", contains: []string{"huiyi", "会议"}},
+		{name: "tone numbers", query: "hui4yi4", contains: []string{"会议"}},
+		{name: "syllable", query: "zhong", contains: []string{"中"}},
+		{name: "partial", query: "xiangm", contains: []string{"项目"}},
+		{name: "compound", query: "huiyishi", contains: []string{"会议是"}},
+		{name: "u with umlaut", query: "lü", contains: []string{"绿"}},
+		{name: "hanzi to pinyin", query: "会议", contains: []string{"会议", "huiyi"}},
+		{name: "hanzi of several words", query: "今天会议", contains: []string{"jintianhuiyi"}},
+		{name: "english word is left alone", query: "test", excludes: []string{"特"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pc.Convert(tt.query)
+			assert.Equal(t, tt.query, got[0])
+			for _, want := range tt.contains {
+				assert.Contains(t, got, want)
+			}
+			for _, unwanted := range tt.excludes {
+				assert.NotContains(t, got, unwanted)
+			}
+		})
+	}
+}
@@ -0,0 +1,447 @@
+package search
+
+import (
+	"context"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hirotoni/memov2/internal/domain"
+	"github.com/hirotoni/memov2/internal/domain/markdown"
+)
+
+// String returns the name of the match type as used in search output
+func (t MatchType) String() string {
+	switch t {
+	case MatchTitle:
+		return "title"
+	case MatchCategory:
+		return "category"
+	case MatchContent:
+		return "content"
+	case MatchHeading:
+		return "heading"
+	case MatchTag:
+		return "tag"
+	case MatchTask:
+		return "task"
+	default:
+		return "unknown"
+	}
+}
+
+// document is what a query is matched against: a memo or a task
+type document struct {
+	memo domain.MemoFileInterface // nil for a task
+	task *Task                    // nil for a memo
+}
+
+// date returns the date of the memo or of the todo file holding the task
+func (d document) date() time.Time {
+	if d.task != nil {
+		return d.task.Date
+	}
+	return d.memo.Date()
+}
+
+// node is a parsed query expression
+type node interface {
+	// eval reports whether d matches, and the matches that show why. With
+	// fuzzy, text terms match as FuzzyMatch does.
+	eval(d document, fuzzy bool) (bool, []Match)
+}
+
+// andNode matches if every child matches
+type andNode []node
+
+func (n andNode) eval(d document, fuzzy bool) (bool, []Match) {
+	var matches []Match
+	for _, c := range n {
+		ok, ms := c.eval(d, fuzzy)
+		if !ok {
+			return false, nil
+		}
+		matches = append(matches, ms...)
+	}
+	return true, matches
+}
+
+// orNode matches if any child matches
+type orNode []node
+
+func (n orNode) eval(d document, fuzzy bool) (bool, []Match) {
+	found := false
+	var matches []Match
+	for _, c := range n {
+		if ok, ms := c.eval(d, fuzzy); ok {
+			found = true
+			matches = append(matches, ms...)
+		}
+	}
+	return found, matches
+}
+
+// notNode matches if its child does not. The child always matches exactly, so
+// that -draft does not exclude memos about a drift.
+type notNode struct {
+	node
+}
+
+func (n notNode) eval(d document, fuzzy bool) (bool, []Match) {
+	ok, _ := n.node.eval(d, false)
+	return !ok, nil
+}
+
+// textNode matches if a field contains any of the variations of a term
+type textNode struct {
+	field      string
+	variations []string
+}
+
+// fieldMatchTypes maps a qualifier to the match types it searches
+var fieldMatchTypes = map[string][]MatchType{
+	"":            {MatchTitle, MatchCategory, MatchHeading, MatchContent},
+	fieldTitle:    {MatchTitle},
+	fieldCategory: {MatchCategory},
+	fieldHeading:  {MatchHeading},
+	fieldBody:     {MatchContent},
+	fieldTag:      {MatchTag},
+}
+
+func (n *textNode) eval(d document, fuzzy bool) (bool, []Match) {
+	if d.task != nil {
+		return n.evalTask(d.task, fuzzy)
+	}
+
+	search := SearchMemo
+	if fuzzy {
+		search = FuzzySearchMemo
+	}
+	var matches []Match
+	for _, v := range n.variations {
+		for _, t := range fieldMatchTypes[n.field] {
+			matches = append(matches, search(d.memo, v, t).Matches...)
+		}
+	}
+	return len(matches) > 0, matches
+}
+
+// evalTask matches the text of a task, or with heading: the heading it is under.
+// Tasks have no title, category or tags.
+func (n *textNode) evalTask(task *Task, fuzzy bool) (bool, []Match) {
+	matchType, text := MatchTask, task.Text
+	switch n.field {
+	case "", fieldBody:
+	case fieldHeading:
+		matchType, text = MatchHeading, task.Section
+	default:
+		return false, nil
+	}
+
+	var matches []Match
+	for _, v := range n.variations {
+		if positions, ok := matchText(v, text, fuzzy); ok {
+			matches = append(matches, Match{
+				Type:      matchType,
+				Line:      task.Line + 1,
+				Content:   text,
+				Heading:   task.Section,
+				Positions: positions,
+			})
+		}
+	}
+	return len(matches) > 0, matches
+}
+
+// matchText reports whether text contains query, ignoring the differences
+// Normalize folds, or with fuzzy whether it matches as FuzzyMatch does, and the
+// positions to highlight
+func matchText(query, text string, fuzzy bool) ([]int, bool) {
+	if fuzzy {
+		f, ok := FuzzyMatch(query, text)
+		return f.Positions, ok
+	}
+	return nil, strings.Contains(Normalize(text), Normalize(strings.TrimSpace(query)))
+}
+
+// dateNode matches memos written, and tasks of todo files dated, in [from, to).
+// A zero bound is open.
+type dateNode struct {
+	from, to time.Time
+}
+
+func (n *dateNode) eval(doc document, fuzzy bool) (bool, []Match) {
+	// Compare calendar days, whatever the location of the memo's date
+	y, m, d := doc.date().Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	if !n.from.IsZero() && day.Before(n.from) {
+		return false, nil
+	}
+	if !n.to.IsZero() && !day.Before(n.to) {
+		return false, nil
+	}
+	return true, nil
+}
+
+// Values of the is: qualifier
+const (
+	isMemo = "memo" // memos only
+	isTask = "task" // tasks only
+	isDone = "done" // tasks checked off
+	isOpen = "open" // tasks still to do
+)
+
+// isAliases maps every accepted value of is: to its kind
+var isAliases = map[string]string{
+	"memo":   isMemo,
+	"task":   isTask,
+	"todo":   isTask,
+	"done":   isDone,
+	"open":   isOpen,
+	"undone": isOpen,
+}
+
+// isNode matches documents of a kind, or tasks in a state
+type isNode struct {
+	kind string
+}
+
+func (n isNode) eval(d document, fuzzy bool) (bool, []Match) {
+	switch n.kind {
+	case isMemo:
+		return d.memo != nil, nil
+	case isTask:
+		return d.task != nil, nil
+	case isDone:
+		return d.task != nil && d.task.Done, nil
+	case isOpen:
+		return d.task != nil && !d.task.Done, nil
+	}
+	return false, nil
+}
+
+// SearchMemos returns the memos matching q, newest first. Matches within a
+// result are ordered by type and position. A memo matched only by dates or
+// negated terms has no matches.
+func SearchMemos(memos []domain.MemoFileInterface, q *Query) []SearchResult {
+	results, _ := SearchMemosContext(context.Background(), memos, q)
+	return results
+}
+
+// memosPerWorker is the number of memos or tasks worth a worker goroutine of their own
+const memosPerWorker = 64
+
+// SearchMemosContext is SearchMemos, matching the memos on worker goroutines.
+// It gives up with the error of ctx once ctx is done.
+func SearchMemosContext(ctx context.Context, memos []domain.MemoFileInterface, q *Query) ([]SearchResult, error) {
+	return searchDocuments(ctx, memoDocuments(memos), q)
+}
+
+// SearchTasks returns the tasks matching q, newest first, each with the match
+// of its text. Memo qualifiers such as title: never match a task.
+func SearchTasks(tasks []Task, q *Query) []SearchResult {
+	results, _ := searchDocuments(context.Background(), taskDocuments(tasks), q)
+	return results
+}
+
+func memoDocuments(memos []domain.MemoFileInterface) []document {
+	docs := make([]document, len(memos))
+	for i, m := range memos {
+		docs[i] = document{memo: m}
+	}
+	return docs
+}
+
+func taskDocuments(tasks []Task) []document {
+	docs := make([]document, len(tasks))
+	for i := range tasks {
+		docs[i] = document{task: &tasks[i]}
+	}
+	return docs
+}
+
+// searchDocuments matches docs against q on worker goroutines
+func searchDocuments(ctx context.Context, docs []document, q *Query) ([]SearchResult, error) {
+	results := []SearchResult{}
+	if q.Empty() {
+		return results, nil
+	}
+
+	// Each worker takes the next document and writes its result to the document's slot
+	found := make([]*SearchResult, len(docs))
+	var next atomic.Int64
+	work := func() {
+		for {
+			i := int(next.Add(1) - 1)
+			if i >= len(docs) || ctx.Err() != nil {
+				return
+			}
+			if !q.inScope(docs[i]) {
+				continue
+			}
+			ok, matches := q.root.eval(docs[i], q.fuzzy)
+			if !ok {
+				continue
+			}
+			matches = uniqueMatches(matches)
+			sortMatches(matches)
+			found[i] = &SearchResult{Memo: docs[i].memo, Task: docs[i].task, Matches: matches}
+		}
+	}
+
+	workers := min(runtime.GOMAXPROCS(0), len(docs)/memosPerWorker)
+	var wg sync.WaitGroup
+	for range workers - 1 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			work()
+		}()
+	}
+	work()
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, r := range found {
+		if r != nil {
+			results = append(results, *r)
+		}
+	}
+	SortResults(results, OrderDate)
+	return results, nil
+}
+
+// uniqueMatches drops matches found by more than one term
+func uniqueMatches(matches []Match) []Match {
+	type key struct {
+		t       MatchType
+		heading int
+		line    int
+		content string
+	}
+	seen := make(map[key]bool, len(matches))
+	unique := make([]Match, 0, len(matches))
+	for _, m := range matches {
+		k := key{m.Type, m.HeadingOrder, m.Line, m.Content}
+		if !seen[k] {
+			seen[k] = true
+			unique = append(unique, m)
+		}
+	}
+	return unique
+}
+
+// sortMatches orders matches by type, heading and line
+func sortMatches(matches []Match) {
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Type != matches[j].Type {
+			return matches[i].Type < matches[j].Type
+		}
+		if matches[i].HeadingOrder != matches[j].HeadingOrder {
+			return matches[i].HeadingOrder < matches[j].HeadingOrder
+		}
+		if matches[i].Type == MatchContent {
+			return matches[i].Line < matches[j].Line
+		}
+		// For heading matches at the same position (should be rare), sort by content for stability
+		return matches[i].Content < matches[j].Content
+	})
+}
+
+// FileLine returns the line of the memo file holding match, or 1 if it is not known
+func FileLine(memo domain.MemoFileInterface, match Match) int {
+	var block *markdown.HeadingBlock
+	switch {
+	case match.HeadingOrder == -1:
+		block = memo.TopLevelBodyContent()
+	case match.HeadingOrder >= 0 && match.HeadingOrder < len(memo.HeadingBlocks()):
+		block = memo.HeadingBlocks()[match.HeadingOrder]
+	}
+
+	switch match.Type {
+	case MatchTitle:
+		if tl := memo.TopLevelBodyContent(); tl != nil && tl.LineNumber > 0 {
+			return tl.LineNumber
+		}
+	case MatchHeading:
+		if block != nil && block.LineNumber > 0 {
+			return block.LineNumber
+		}
+	case MatchContent:
+		if block != nil && block.ContentLineNumber > 0 {
+			return block.ContentLineNumber + match.Line - 1
+		}
+	}
+	return 1
+}
+
+// FileColumn returns the 1-based column, in characters, of the start of match
+// on its file line: the first matched character of a fuzzy match, otherwise the
+// first occurrence of any of terms. It is 1 if the column is not known.
+func FileColumn(memo domain.MemoFileInterface, match Match, terms []string) int {
+	// Markers before the text on the file line
+	prefix := 0
+	switch match.Type {
+	case MatchTitle:
+		prefix = len("# ")
+	case MatchHeading:
+		if match.HeadingOrder >= 0 && match.HeadingOrder < len(memo.HeadingBlocks()) {
+			prefix = memo.HeadingBlocks()[match.HeadingOrder].Level + 1
+		}
+	case MatchContent:
+	default:
+		return 1
+	}
+
+	offset := matchOffset(match, terms)
+	if offset < 0 {
+		return 1
+	}
+	return prefix + utf8.RuneCountInString(match.Content[:offset]) + 1
+}
+
+// matchOffset returns the byte offset in the content of match of its first
+// matched character, or of the first occurrence of any of terms; -1 if unknown
+func matchOffset(match Match, terms []string) int {
+	offset := -1
+	if len(match.Positions) > 0 {
+		offset = match.Positions[0]
+	} else {
+		// Searched in the content itself, as lower-casing may change its length
+		for _, t := range terms {
+			if t == "" {
+				continue
+			}
+			if i := indexFold(match.Content, t); i >= 0 && (offset < 0 || i < offset) {
+				offset = i
+			}
+		}
+	}
+	if offset > len(match.Content) {
+		return -1
+	}
+	return offset
+}
+
+// Position returns the 1-based line and column of the file of r holding match,
+// as FileLine and FileColumn do for a memo. For a task it is the task's line, at
+// the match in its text, or at its text if the match is elsewhere.
+func (r SearchResult) Position(match Match, terms []string) (line, column int) {
+	if r.Task == nil {
+		return FileLine(r.Memo, match), FileColumn(r.Memo, match, terms)
+	}
+
+	line, column = r.Task.Line+1, r.Task.Column+1
+	if match.Type == MatchTask {
+		if offset := matchOffset(match, terms); offset >= 0 {
+			column += utf8.RuneCountInString(match.Content[:offset])
+		}
+	}
+	return line, column
+}
@@ -0,0 +1,354 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hirotoni/memov2/internal/domain"
+	"github.com/hirotoni/memov2/internal/domain/markdown"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeConverter map[string][]string
+
+func (c fakeConverter) Convert(word string) []string {
+	if v, ok := c[word]; ok {
+		return v
+	}
+	return []string{word}
+}
+
+func searchTestMemos(t *testing.T) []domain.MemoFileInterface {
+	t.Helper()
+	base := time.Date(2025, 2, 10, 9, 0, 0, 0, time.UTC) // Monday of 2025-W07
+
+	goNotes, err := domain.NewMemoFile(base, "Go notes", []string{"dev"})
+	require.NoError(t, err)
+	goNotes.SetHeadingBlocks([]*markdown.HeadingBlock{
+		{Level: 2, HeadingText: "Setup", ContentText: "install go\nrun go test\n"},
+	})
+
+	meeting, err := domain.NewMemoFile(base.AddDate(0, 0, 7), "Meeting", []string{"work", "projects"})
+	require.NoError(t, err)
+	meeting.SetHeadingBlocks([]*markdown.HeadingBlock{
+		{Level: 2, HeadingText: "会議 go", ContentText: "go live next week\nunrelated\n"},
+	})
+	meeting.Frontmatter().SetStrings(domain.MetaKeyTags, []string{"client", "draft"})
+
+	shopping, err := domain.NewMemoFile(base.AddDate(0, 0, 8), "Shopping", nil)
+	require.NoError(t, err)
+	shopping.SetHeadingBlocks([]*markdown.HeadingBlock{
+		{Level: 2, HeadingText: "List", ContentText: "milk and eggs\n"},
+	})
+
+	return []domain.MemoFileInterface{goNotes, meeting, shopping}
+}
+
+func resultTitles(results []SearchResult) []string {
+	var ts []string
+	for _, r := range results {
+		ts = append(ts, r.Memo.Title())
+	}
+	return ts
+}
+
+func TestSearchMemos(t *testing.T) {
+	memos := searchTestMemos(t)
+	conv := fakeConverter{"kaigi": {"kaigi", "会議"}}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"go", []string{"Meeting", "Go notes"}},
+		{"go test", []string{"Go notes"}},
+		{"GO TEST", []string{"Go notes"}},
+		{"kaigi", []string{"Meeting"}},
+		{"title:go", []string{"Go notes"}},
+		{"heading:go", []string{"Meeting"}},
+		{"body:milk", []string{"Shopping"}},
+		{"cat:work/projects", []string{"Meeting"}},
+		{"category:dev", []string{"Go notes"}},
+		{"tag:client", []string{"Meeting"}},
+		{"go -live", []string{"Go notes"}},
+		{"go -tag:draft", []string{"Go notes"}},
+		{"-go", []string{"Shopping"}},
+		{`"go live"`, []string{"Meeting"}},
+		{`"live go"`, nil},
+		{`body:"run go"`, []string{"Go notes"}},
+		{"milk OR install", []string{"Shopping", "Go notes"}},
+		{"(milk OR install) eggs", []string{"Shopping"}},
+		{"-(milk OR install)", []string{"Meeting"}},
+		{"after:2025-02-17", []string{"Shopping", "Meeting"}},
+		{"before:2025-02-17", []string{"Go notes"}},
+		{"date:2025-02-18", []string{"Shopping"}},
+		{"week:2025-W07", []string{"Go notes"}},
+		{"week:2025-w08 go", []string{"Meeting"}},
+		{"unknown:go", nil},
+		{"title:", nil},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := ParseQuery(tt.query, conv)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, resultTitles(SearchMemos(memos, q)))
+		})
+	}
+}
+
+func TestSearchMemos_Matches(t *testing.T) {
+	memos := searchTestMemos(t)
+
+	q, err := ParseQuery("go -milk", nil)
+	require.NoError(t, err)
+	results := SearchMemos(memos, q)
+	require.Len(t, results, 2)
+
+	// Title before content, content in line order
+	var types []MatchType
+	var lines []int
+	for _, m := range results[1].Matches {
+		types = append(types, m.Type)
+		lines = append(lines, m.Line)
+	}
+	assert.Equal(t, []MatchType{MatchTitle, MatchContent, MatchContent}, types)
+	assert.Equal(t, []int{0, 1, 2}, lines)
+
+	// Memos matched only by dates have no matches
+	q, err = ParseQuery("date:2025-02-18", nil)
+	require.NoError(t, err)
+	results = SearchMemos(memos, q)
+	require.Len(t, results, 1)
+	assert.Empty(t, results[0].Matches)
+}
+
+func TestSearchMemosContext_Parallel(t *testing.T) {
+	// Enough memos for several workers; every third one matches
+	base := time.Date(2025, 2, 10, 9, 0, 0, 0, time.UTC)
+	var memos []domain.MemoFileInterface
+	for i := range 10 * memosPerWorker {
+		title := fmt.Sprintf("memo %d", i)
+		if i%3 == 0 {
+			title += " meeting"
+		}
+		m, err := domain.NewMemoFile(base.Add(time.Duration(i)*time.Minute), title, nil)
+		require.NoError(t, err)
+		memos = append(memos, m)
+	}
+	q, err := ParseQuery("meeting", nil)
+	require.NoError(t, err)
+
+	results, err := SearchMemosContext(context.Background(), memos, q)
+	require.NoError(t, err)
+	require.Len(t, results, len(memos)/3+1)
+	assert.Equal(t, fmt.Sprintf("memo %d meeting", len(memos)-1-(len(memos)-1)%3), results[0].Memo.Title(), "newest first")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = SearchMemosContext(ctx, memos, q)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func searchTestTasks() []Task {
+	day := time.Date(2025, 2, 10, 0, 0, 0, 0, time.UTC)
+	return []Task{
+		{Task: markdown.Task{Text: "write the go report", Section: "todos", Line: 4, Column: 6}, Path: "20250210Mon_todos.md", Date: day},
+		{Task: markdown.Task{Text: "book flights", Done: true, Section: "todos", Line: 5, Column: 6}, Path: "20250210Mon_todos.md", Date: day},
+		{Task: markdown.Task{Text: "read the report", Section: "wanttodos", Line: 8, Column: 8}, Path: "20250211Tue_todos.md", Date: day.AddDate(0, 0, 1)},
+	}
+}
+
+func TestSearchTasks(t *testing.T) {
+	tasks := searchTestTasks()
+
+	testCases := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{name: "text", query: "report", want: []string{"read the report", "write the go report"}},
+		{name: "done", query: "is:done", want: []string{"book flights"}},
+		{name: "open", query: "is:open report", want: []string{"read the report", "write the go report"}},
+		{name: "not done", query: "-is:done -report", want: nil},
+		{name: "section", query: "heading:wanttodos", want: []string{"read the report"}},
+		{name: "body", query: "body:flights", want: []string{"book flights"}},
+		{name: "memo fields", query: "title:report", want: nil},
+		{name: "memos only", query: "is:memo report", want: nil},
+		{name: "date of the todo file", query: "date:2025-02-10 report", want: []string{"write the go report"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q, err := ParseQuery(tc.query, nil)
+			require.NoError(t, err)
+			var texts []string
+			for _, r := range SearchTasks(tasks, q) {
+				require.Nil(t, r.Memo)
+				texts = append(texts, r.Task.Text)
+			}
+			assert.Equal(t, tc.want, texts)
+		})
+	}
+
+	// is: tells memos and tasks apart
+	q, err := ParseQuery("is:task go", nil)
+	require.NoError(t, err)
+	assert.Empty(t, SearchMemos(searchTestMemos(t), q))
+	q, err = ParseQuery("is:memo go", nil)
+	require.NoError(t, err)
+	assert.Len(t, SearchMemos(searchTestMemos(t), q), 2)
+}
+
+func TestQuery_Within(t *testing.T) {
+	memos := searchTestMemos(t)
+
+	tests := []struct {
+		name     string
+		query    string
+		category string
+		want     []string
+	}{
+		{name: "whole vault", query: "go", category: "", want: []string{"Meeting", "Go notes"}},
+		{name: "category", query: "go", category: "work", want: []string{"Meeting"}},
+		{name: "subcategory", query: "go", category: "work/projects", want: []string{"Meeting"}},
+		{name: "prefix of a name", query: "go", category: "wor", want: nil},
+		{name: "deeper than the memo", query: "go", category: "work/projects/q1", want: nil},
+		{name: "fuzzy", query: "mtng", category: "dev", want: nil},
+		{name: "slashes", query: "go", category: "/dev/", want: []string{"Go notes"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := ParseQuery(tt.query, nil)
+			require.NoError(t, err)
+			q = q.Within(ParseCategory(tt.category))
+			if tt.name == "fuzzy" {
+				q = q.Fuzzy()
+			}
+			assert.Equal(t, tt.want, resultTitles(SearchMemos(memos, q)))
+		})
+	}
+
+	// Tasks are in no category
+	q, err := ParseQuery("report", nil)
+	require.NoError(t, err)
+	assert.Len(t, SearchTasks(searchTestTasks(), q), 2)
+	assert.Empty(t, SearchTasks(searchTestTasks(), q.Within([]string{"work"})))
+}
+
+func TestSearchResult_Position(t *testing.T) {
+	q, err := ParseQuery("report", nil)
+	require.NoError(t, err)
+	results := SearchTasks(searchTestTasks(), q)
+	require.Len(t, results, 2)
+
+	// Line 9 of the file, "  - [ ] read the report"
+	r := results[0]
+	require.Len(t, r.Matches, 1)
+	assert.Equal(t, MatchTask, r.Matches[0].Type)
+	assert.Equal(t, "20250211Tue_todos.md", r.Path())
+	line, column := r.Position(r.Matches[0], q.Terms())
+	assert.Equal(t, 9, line)
+	assert.Equal(t, 9+len("read the "), column)
+
+	// A match in the section opens at the task's text
+	q, err = ParseQuery("heading:want", nil)
+	require.NoError(t, err)
+	results = SearchTasks(searchTestTasks(), q)
+	require.Len(t, results, 1)
+	line, column = results[0].Position(results[0].Matches[0], q.Terms())
+	assert.Equal(t, 9, line)
+	assert.Equal(t, 9, column)
+}
+
+func TestParseQuery_Errors(t *testing.T) {
+	for _, query := range []string{"after:yesterday", "date:2025-13-01", "week:2025-07", "week:2025-W54", "before:2025/01/01", "is:later"} {
+		t.Run(query, func(t *testing.T) {
+			_, err := ParseQuery(query, nil)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestQuery_RequiredWordsAndTerms(t *testing.T) {
+	conv := fakeConverter{"kaigi": {"kaigi", "会議"}}
+
+	tests := []struct {
+		query    string
+		required [][]string
+		terms    []string
+	}{
+		{"kaigi notes", [][]string{{"kaigi", "会議"}, {"notes"}}, []string{"kaigi", "会議", "notes"}},
+		{"title:a -b tag:c", [][]string{{"a"}}, []string{"a", "c"}},
+		{"a OR b", nil, []string{"a", "b"}},
+		{"(a OR b) c after:2025-01-01", [][]string{{"c"}}, []string{"a", "b", "c"}},
+		{"", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := ParseQuery(tt.query, conv)
+			require.NoError(t, err)
+			assert.Equal(t, tt.required, q.RequiredWords())
+			assert.Equal(t, tt.terms, q.Terms())
+		})
+	}
+}
+
+func TestFileLine(t *testing.T) {
+	memo, err := domain.NewMemoFile(time.Now(), "Notes", nil)
+	require.NoError(t, err)
+	memo.SetTopLevelBodyContent(&markdown.HeadingBlock{Level: 1, HeadingText: "Notes", ContentText: "intro", LineNumber: 4, ContentLineNumber: 6})
+	memo.SetHeadingBlocks([]*markdown.HeadingBlock{
+		{Level: 2, HeadingText: "first", ContentText: "a\nb\n", LineNumber: 8, ContentLineNumber: 10},
+	})
+
+	tests := []struct {
+		name  string
+		match Match
+		want  int
+	}{
+		{"title", Match{Type: MatchTitle}, 4},
+		{"category", Match{Type: MatchCategory}, 1},
+		{"top level content", Match{Type: MatchContent, HeadingOrder: -1, Line: 1}, 6},
+		{"heading", Match{Type: MatchHeading, HeadingOrder: 0}, 8},
+		{"content", Match{Type: MatchContent, HeadingOrder: 0, Line: 2}, 11},
+		{"unknown heading", Match{Type: MatchContent, HeadingOrder: 3, Line: 2}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, FileLine(memo, tt.match))
+		})
+	}
+}
+
+func TestFileColumn(t *testing.T) {
+	memo, err := domain.NewMemoFile(time.Now(), "Notes", nil)
+	require.NoError(t, err)
+	memo.SetHeadingBlocks([]*markdown.HeadingBlock{
+		{Level: 3, HeadingText: "weekly sync", ContentText: "会議 agenda\n"},
+	})
+
+	tests := []struct {
+		name  string
+		match Match
+		terms []string
+		want  int
+	}{
+		{"title", Match{Type: MatchTitle, Content: "Notes"}, []string{"not"}, 3},
+		{"heading", Match{Type: MatchHeading, HeadingOrder: 0, Content: "weekly sync"}, []string{"SYNC"}, 12},
+		{"content", Match{Type: MatchContent, HeadingOrder: 0, Line: 1, Content: "会議 agenda"}, []string{"agenda"}, 4},
+		{"earliest term", Match{Type: MatchContent, HeadingOrder: 0, Line: 1, Content: "会議 agenda"}, []string{"agenda", "会議"}, 1},
+		{"fuzzy positions", Match{Type: MatchContent, HeadingOrder: 0, Line: 1, Content: "会議 agenda", Positions: []int{3, 9}}, nil, 2},
+		{"term not found", Match{Type: MatchContent, Content: "agenda"}, []string{"missing"}, 1},
+		{"category", Match{Type: MatchCategory, Content: "work"}, []string{"work"}, 1},
+		{"lower-casing changes length", Match{Type: MatchContent, HeadingOrder: 0, Line: 1, Content: "İİ agenda"}, []string{"AGENDA"}, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, FileColumn(memo, tt.match, tt.terms))
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package search
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hirotoni/memov2/internal/domain"
+)
+
+// Order is the order of search results
+type Order string
+
+const (
+	// OrderRelevance puts the best matching memos first
+	OrderRelevance Order = "relevance"
+	// OrderDate puts the newest memos first
+	OrderDate Order = "date"
+)
+
+// ParseOrder parses the name of an order
+func ParseOrder(s string) (Order, error) {
+	switch o := Order(strings.ToLower(s)); o {
+	case OrderRelevance, OrderDate:
+		return o, nil
+	default:
+		return "", fmt.Errorf("unknown order: %s (want relevance or date)", s)
+	}
+}
+
+// Next returns the other order, for toggling
+func (o Order) Next() Order {
+	if o == OrderDate {
+		return OrderRelevance
+	}
+	return OrderDate
+}
+
+// BM25 parameters and field weights. A match in the title counts three times
+// as much as one in the body.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+
+	weightTitle    = 3.0
+	weightHeading  = 2.0
+	weightCategory = 1.5
+	weightTag      = 1.5
+	weightBody     = 1.0
+	fuzzyWeight    = 0.5
+
+	// A memo written today scores up to recencyBoost times more than an old
+	// one; the boost halves every recencyHalfLife.
+	recencyBoost    = 0.5
+	recencyHalfLife = 30 * 24 * time.Hour
+)
+
+// Stats describes the searched memos as a whole, for weighting terms by how rare they are
+type Stats struct {
+	Docs      int     // number of memos
+	AvgLength float64 // average length of a memo, in runes of searchable text
+
+	// DocFreq returns the number of memos containing any of the variations of a term
+	DocFreq func(variations []string) int
+}
+
+// NewStats computes the stats of memos
+func NewStats(memos []domain.MemoFileInterface) Stats {
+	texts := make([]string, len(memos))
+	total := 0
+	for i, m := range memos {
+		texts[i] = Normalize(memoText(m))
+		total += len([]rune(texts[i]))
+	}
+
+	stats := Stats{
+		Docs: len(memos),
+		DocFreq: func(variations []string) int {
+			n := 0
+			for _, text := range texts {
+				for _, v := range variations {
+					if strings.Contains(text, Normalize(v)) {
+						n++
+						break
+					}
+				}
+			}
+			return n
+		},
+	}
+	if len(memos) > 0 {
+		stats.AvgLength = float64(total) / float64(len(memos))
+	}
+	return stats
+}
+
+// Score sets the relevance score of every result: BM25 over the terms of q, with
+// matches weighted by the field they are in and recent memos boosted.
+func Score(results []SearchResult, q *Query, stats Stats, now time.Time) {
+	terms := q.textTerms()
+
+	idf := make([]float64, len(terms))
+	for i, t := range terms {
+		idf[i] = 1
+		if stats.Docs > 0 && stats.DocFreq != nil {
+			df := float64(stats.DocFreq(t.variations))
+			idf[i] = math.Log(1 + (float64(stats.Docs)-df+0.5)/(df+0.5))
+		}
+	}
+
+	for i := range results {
+		// Tasks are a line long, so they are not normalized by the length of memos
+		var fields []rankField
+		norm := 1.0
+		if task := results[i].Task; task != nil {
+			fields = []rankField{{fieldBody, weightBody, task.Text}}
+		} else {
+			m := results[i].Memo
+			fields = memoFields(m)
+			if stats.AvgLength > 0 {
+				length := float64(len([]rune(memoText(m))))
+				norm = 1 - bm25B + bm25B*length/stats.AvgLength
+			}
+		}
+
+		score := 0.0
+		for j, t := range terms {
+			tf := 0.0
+			for _, f := range fields {
+				// Unqualified terms do not search tags, as in SearchMemo
+				if t.field == f.name || (t.field == "" && f.name != fieldTag) {
+					n := countVariations(f.text, t.variations)
+					if n == 0 && q.fuzzy {
+						// A fuzzy match counts half as much as an exact one
+						if _, ok := FuzzyMatchAny(t.variations, f.text); ok {
+							tf += f.weight * fuzzyWeight
+						}
+					}
+					tf += f.weight * float64(n)
+				}
+			}
+			if tf > 0 {
+				score += idf[j] * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+			}
+		}
+
+		age := max(now.Sub(results[i].Date()), 0)
+		score *= 1 + recencyBoost*math.Pow(0.5, float64(age)/float64(recencyHalfLife))
+
+		results[i].Score = score
+	}
+}
+
+// SortResults orders results; by relevance, ties keep the newest memo first.
+// Tasks of the same todo file keep the order of the file.
+func SortResults(results []SearchResult, order Order) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if order == OrderRelevance && results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		dateI, dateJ := results[i].Date(), results[j].Date()
+		if !dateI.Equal(dateJ) {
+			return dateI.After(dateJ)
+		}
+		if pathI, pathJ := results[i].Path(), results[j].Path(); pathI != pathJ {
+			return pathI < pathJ
+		}
+		if results[i].Task != nil && results[j].Task != nil {
+			return results[i].Task.Line < results[j].Task.Line
+		}
+		return false
+	})
+}
+
+// rankField is the text of a memo field with the weight of matches in it
+type rankField struct {
+	name   string // qualifier searching the field
+	weight float64
+	text   string
+}
+
+func memoFields(m domain.MemoFileInterface) []rankField {
+	var headings, body []string
+	if tl := m.TopLevelBodyContent(); tl != nil {
+		body = append(body, tl.ContentText)
+	}
+	for _, hb := range m.HeadingBlocks() {
+		headings = append(headings, hb.HeadingText)
+		body = append(body, hb.ContentText)
+	}
+	tags := m.Tags()
+
+	return []rankField{
+		{fieldTitle, weightTitle, m.Title()},
+		{fieldHeading, weightHeading, strings.Join(headings, "\n")},
+		{fieldCategory, weightCategory, strings.Join(m.CategoryTree(), "/")},
+		{fieldTag, weightTag, strings.Join(tags, "\n")},
+		{fieldBody, weightBody, strings.Join(body, "\n")},
+	}
+}
+
+// countVariations counts the occurrences of the variations of a term in text
+func countVariations(text string, variations []string) int {
+	text = Normalize(text)
+	n := 0
+	for _, v := range variations {
+		if v = Normalize(v); v != "" {
+			n += strings.Count(text, v)
+		}
+	}
+	return n
+}
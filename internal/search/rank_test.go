@@ -0,0 +1,110 @@
+package search
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hirotoni/memov2/internal/domain"
+	"github.com/hirotoni/memov2/internal/domain/markdown"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rankTestMemo(t *testing.T, date time.Time, title string, content string) domain.MemoFileInterface {
+	t.Helper()
+	m, err := domain.NewMemoFile(date, title, nil)
+	require.NoError(t, err)
+	m.SetHeadingBlocks([]*markdown.HeadingBlock{{Level: 2, HeadingText: "Notes", ContentText: content}})
+	return m
+}
+
+func rank(t *testing.T, memos []domain.MemoFileInterface, query string, order Order, now time.Time) []SearchResult {
+	t.Helper()
+	q, err := ParseQuery(query, nil)
+	require.NoError(t, err)
+	results := SearchMemos(memos, q)
+	Score(results, q, NewStats(memos), now)
+	SortResults(results, order)
+	return results
+}
+
+func TestScore_TitleOutranksIncidentalBodyMatches(t *testing.T) {
+	base := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	filler := strings.Repeat("lorem ipsum dolor sit amet\n", 20)
+
+	memos := []domain.MemoFileInterface{
+		rankTestMemo(t, base, "deploy", "how we ship\n"),
+	}
+	for i := 1; i <= 5; i++ {
+		memos = append(memos, rankTestMemo(t, base.AddDate(0, 0, i), "daily", filler+"then deploy again\n"))
+	}
+	now := base.AddDate(1, 0, 0)
+
+	byRelevance := rank(t, memos, "deploy", OrderRelevance, now)
+	require.Len(t, byRelevance, 6)
+	assert.Equal(t, "deploy", byRelevance[0].Memo.Title())
+	assert.Greater(t, byRelevance[0].Score, byRelevance[1].Score)
+
+	byDate := rank(t, memos, "deploy", OrderDate, now)
+	assert.Equal(t, "deploy", byDate[5].Memo.Title())
+	assert.True(t, byDate[0].Memo.Date().After(byDate[1].Memo.Date()))
+}
+
+func TestScore_RareTermsWeighMore(t *testing.T) {
+	base := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	memos := []domain.MemoFileInterface{
+		rankTestMemo(t, base, "a", "common\n"),
+		rankTestMemo(t, base, "b", "common\n"),
+		rankTestMemo(t, base, "c", "common\n"),
+		rankTestMemo(t, base, "d", "rare\n"),
+	}
+
+	results := rank(t, memos, "common OR rare", OrderRelevance, base)
+	require.Len(t, results, 4)
+	assert.Equal(t, "d", results[0].Memo.Title())
+}
+
+func TestScore_BoostsRecentMemos(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	memos := []domain.MemoFileInterface{
+		rankTestMemo(t, now.AddDate(-1, 0, 0), "old", "topic\n"),
+		rankTestMemo(t, now.AddDate(0, 0, -1), "new", "topic\n"),
+	}
+
+	results := rank(t, memos, "topic", OrderRelevance, now)
+	require.Len(t, results, 2)
+	assert.Equal(t, "new", results[0].Memo.Title())
+	assert.Greater(t, results[0].Score, results[1].Score)
+}
+
+func TestIndex_Stats(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	saveIndexTestMemo(t, dir, base, "first", nil, "alpha beta\n")
+	saveIndexTestMemo(t, dir, base.Add(time.Hour), "second", nil, "beta\n")
+
+	var parsed int
+	idx := NewIndex(filepath.Join(t.TempDir(), "memos.idx"), dir, countingLoader(&parsed))
+	require.NoError(t, idx.Refresh())
+
+	stats := idx.Stats()
+	assert.Equal(t, 2, stats.Docs)
+	assert.Greater(t, stats.AvgLength, 0.0)
+	assert.Equal(t, 1, stats.DocFreq([]string{"alpha"}))
+	assert.Equal(t, 2, stats.DocFreq([]string{"beta"}))
+	assert.Equal(t, 2, stats.DocFreq([]string{"alpha", "second"}))
+	assert.Equal(t, 0, stats.DocFreq([]string{"gamma"}))
+}
+
+func TestParseOrder(t *testing.T) {
+	o, err := ParseOrder("Date")
+	require.NoError(t, err)
+	assert.Equal(t, OrderDate, o)
+	assert.Equal(t, OrderRelevance, o.Next())
+	assert.Equal(t, OrderDate, OrderRelevance.Next())
+
+	_, err = ParseOrder("size")
+	assert.Error(t, err)
+}
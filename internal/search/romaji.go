@@ -3,18 +3,31 @@ package search
 import (
 	"bufio"
 	"bytes"
+	"fmt"
 	"strings"
+	"unicode/utf8"
 )
 
-type IRomajiConverter interface {
-	Convert(query string) []string
-}
-
 // RomajiConverter handles conversion from romaji to Japanese using SKK dictionary
 type RomajiConverter struct {
-	dict map[string][]string // hiragana -> kanji mapping
+	dict     map[string][]string // hiragana -> kanji mapping
+	readings map[string][]string // kanji -> hiragana mapping, dict in reverse
+	trie     *readingTrie        // readings of dict, for completing and segmenting input
 }
 
+// Limits keeping the variations of a word few enough to search as you type
+const (
+	// Partial input is completed once it has this many kana; shorter prefixes
+	// start too many readings to be useful
+	minCompletionKana = 2
+	// maxCompletionReadings is the number of readings a partial input completes to
+	maxCompletionReadings = 10
+	// maxSegmentCandidates is the number of candidates used per segment of a reading
+	maxSegmentCandidates = 3
+	// maxSegmentations is the number of variations built from the segments of a reading
+	maxSegmentations = 10
+)
+
 // Initialize katakana mapping
 var hiraganaToKatakana = map[rune]rune{
 	'あ': 'ア', 'い': 'イ', 'う': 'ウ', 'え': 'エ', 'お': 'オ',
@@ -106,20 +119,34 @@ var romajiToHiragana = map[string]string{
 	"-": "ー",
 }
 
-// NewRomajiConverter creates a new RomajiConverter instance and loads the dictionary
-func NewRomajiConverter() (*RomajiConverter, error) {
+// NewRomajiConverter creates a new RomajiConverter instance and loads the
+// dictionaries, highest precedence first, then the embedded one. The candidates
+// of a reading found in several dictionaries are merged in that order. Missing
+// dictionary files are skipped.
+func NewRomajiConverter(dictionaries ...string) (*RomajiConverter, error) {
 	rc := &RomajiConverter{
-		dict: make(map[string][]string),
+		dict:     make(map[string][]string),
+		readings: make(map[string][]string),
+		trie:     newReadingTrie(),
+	}
+	for _, path := range dictionaries {
+		data, err := readDictionary(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := rc.loadDictionary(data); err != nil {
+			return nil, fmt.Errorf("failed to load dictionary %s: %w", path, err)
+		}
 	}
-	if err := rc.loadDictionary(); err != nil {
+	if err := rc.loadDictionary(skkDictData); err != nil {
 		return nil, err
 	}
 	return rc, nil
 }
 
-// loadDictionary loads the SKK dictionary from embedded data
-func (rc *RomajiConverter) loadDictionary() error {
-	reader := bytes.NewReader(skkDictData)
+// loadDictionary loads an SKK dictionary
+func (rc *RomajiConverter) loadDictionary(data []byte) error {
+	reader := bytes.NewReader(data)
 	scanner := bufio.NewScanner(reader)
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -135,23 +162,29 @@ func (rc *RomajiConverter) loadDictionary() error {
 		}
 
 		hiragana := parts[0]
-		// Split by slash and remove comments
-		rawCandidates := strings.Split(strings.Trim(parts[1], "/"), "/")
-		candidates := make([]string, 0, len(rawCandidates))
-		for _, c := range rawCandidates {
-			if semicolonIdx := strings.Index(c, ";"); semicolonIdx != -1 {
-				candidates = append(candidates, c[:semicolonIdx])
-			} else {
-				candidates = append(candidates, c)
+		// Candidates already loaded from a dictionary of higher precedence come first
+		candidates := parseCandidates(parts[1])
+		rc.dict[hiragana] = mergeCandidates(rc.dict[hiragana], candidates)
+
+		// Readings with okurigana ("おくr") end in a romaji letter and cannot
+		// be completed, segmented or searched for
+		if last, _ := utf8.DecodeLastRuneInString(hiragana); last >= utf8.RuneSelf {
+			rc.trie.insert(hiragana)
+			for _, c := range candidates {
+				rc.readings[c] = mergeCandidates(rc.readings[c], []string{hiragana})
 			}
 		}
-		rc.dict[hiragana] = candidates
 	}
 
 	return scanner.Err()
 }
 
-// Convert converts romaji query to possible Japanese candidates
+// Convert converts romaji query to possible Japanese candidates. Besides the
+// dictionary entries of the whole reading, a word still being typed ("kaig")
+// gets the entries of the readings it starts (会議), and a reading missing from
+// the dictionary ("kaigishitsu") is split into known readings (会議+室).
+// Japanese goes the other way: a word expands to its readings and kana to
+// romaji, so 会議 also finds notes that say かいぎ or kaigi.
 func (rc *RomajiConverter) Convert(query string) []string {
 	// If query is already in Japanese, still try to get variations
 	var results []string
@@ -159,12 +192,22 @@ func (rc *RomajiConverter) Convert(query string) []string {
 	// Add original query
 	results = append(results, query)
 
-	// Convert romaji to hiragana if input is not Japanese
-	var hiragana string
+	// Convert romaji to hiragana if input is not Japanese. Only input that is
+	// entirely romaji is completed or segmented, so that English words do not
+	// pick up unrelated kanji.
+	var hiragana, pending string
+	kana := true
 	if containsJapanese(query) {
-		hiragana = query
+		// Kana also match romaji, and words their readings
+		hiragana = Normalize(query)
+		results = append(results, hiragana)
+		for _, reading := range append([]string{hiragana}, rc.readings[query]...) {
+			if romaji, ok := toRomaji(reading); ok {
+				results = append(results, reading, romaji)
+			}
+		}
 	} else {
-		hiragana = rc.toHiragana(query)
+		hiragana, pending, kana = rc.splitRomaji(Normalize(query))
 		if hiragana != "" {
 			results = append(results, hiragana)
 		}
@@ -176,35 +219,109 @@ func (rc *RomajiConverter) Convert(query string) []string {
 	}
 
 	// Look up kanji in SKK dictionary
-	if candidates, ok := rc.dict[hiragana]; ok {
-		results = append(results, candidates...)
+	candidates, found := rc.dict[hiragana]
+	results = append(results, candidates...)
+
+	if kana && rc.trie != nil {
+		if pending != "" || !found {
+			results = append(results, rc.complete(hiragana, pending)...)
+		}
+		if pending == "" && !found {
+			results = append(results, rc.segmentations(hiragana)...)
+		}
 	}
 
 	// Remove duplicates while preserving order
-	return rc.removeDuplicates(results)
+	return removeDuplicates(results)
 }
 
 // toHiragana converts romaji string to hiragana
 func (rc *RomajiConverter) toHiragana(romaji string) string {
+	hiragana, _, _ := rc.splitRomaji(romaji)
+	return hiragana
+}
+
+// splitRomaji converts romaji to hiragana. pending is the romaji at the end
+// that starts a kana but does not complete one yet, as "g" in "kaig". ok is
+// false if characters that are not romaji had to be skipped.
+func (rc *RomajiConverter) splitRomaji(romaji string) (hiragana, pending string, ok bool) {
 	romaji = strings.ToLower(romaji)
+	ok = true
 	var result strings.Builder
 	for i := 0; i < len(romaji); {
 		// Try to match longest possible romaji sequence
 		matched := false
 		for j := min(len(romaji), i+4); j > i; j-- {
-			if hiragana, ok := romajiToHiragana[romaji[i:j]]; ok {
-				result.WriteString(hiragana)
+			if kana, found := romajiToHiragana[romaji[i:j]]; found {
+				result.WriteString(kana)
 				i = j
 				matched = true
 				break
 			}
 		}
 		if !matched {
+			if isRomajiPrefix(romaji[i:]) {
+				return result.String(), romaji[i:], ok
+			}
 			// If no match found, skip this character
+			ok = false
 			i++
 		}
 	}
-	return result.String()
+	return result.String(), "", ok
+}
+
+// isRomajiPrefix reports whether s starts some romaji without being one
+func isRomajiPrefix(s string) bool {
+	for romaji := range romajiToHiragana {
+		if len(romaji) > len(s) && strings.HasPrefix(romaji, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// pendingKana returns the kana that romaji starting with pending can complete to
+func pendingKana(pending string) []string {
+	seen := make(map[string]bool)
+	var kana []string
+	for romaji, k := range romajiToHiragana {
+		if strings.HasPrefix(romaji, pending) && !seen[k] {
+			seen[k] = true
+			kana = append(kana, k)
+		}
+	}
+	return kana
+}
+
+// complete returns the dictionary candidates of the readings starting with
+// hiragana followed by the kana pending romaji may become
+func (rc *RomajiConverter) complete(hiragana, pending string) []string {
+	prefixes := []string{hiragana}
+	if pending != "" {
+		prefixes = prefixes[:0]
+		for _, k := range pendingKana(pending) {
+			prefixes = append(prefixes, hiragana+k)
+		}
+	}
+
+	var results []string
+	for _, prefix := range prefixes {
+		if utf8.RuneCountInString(prefix) < minCompletionKana {
+			continue
+		}
+		for _, reading := range rc.trie.withPrefix(prefix, maxCompletionReadings) {
+			results = append(results, rc.dict[reading]...)
+		}
+	}
+	return results
+}
+
+// segmentations splits a reading missing from the dictionary into dictionary
+// readings and returns the words their candidates spell. Kana starting no
+// reading are kept as they are.
+func (rc *RomajiConverter) segmentations(reading string) []string {
+	return segmentations(rc.trie, rc.dict, reading, false)
 }
 
 // toKatakana converts hiragana to katakana
@@ -221,7 +338,7 @@ func (rc *RomajiConverter) toKatakana(hiragana string) string {
 }
 
 // removeDuplicates removes duplicate strings while preserving order
-func (rc *RomajiConverter) removeDuplicates(strs []string) []string {
+func removeDuplicates(strs []string) []string {
 	seen := make(map[string]bool)
 	result := make([]string, 0, len(strs))
 	for _, str := range strs {
@@ -238,7 +355,8 @@ func containsJapanese(s string) bool {
 	for _, r := range s {
 		if (r >= 0x3040 && r <= 0x309F) || // Hiragana
 			(r >= 0x30A0 && r <= 0x30FF) || // Katakana
-			(r >= 0x4E00 && r <= 0x9FFF) { // Kanji
+			(r >= 0x4E00 && r <= 0x9FFF) || // Kanji
+			(r >= 0xFF66 && r <= 0xFF9F) { // Half-width katakana
 			return true
 		}
 	}
@@ -0,0 +1,130 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSKKDict = `;; okuri-ari entries.
+おくr /送/
+;; okuri-nasi entries.
+かい /会/回/貝/
+かいぎ /会議/懐疑;doubt/
+かいぎろく /会議録/
+しつ /室/質/
+てつ /鉄/
+よてい /予定/
+`
+
+func newTestRomajiConverter(t *testing.T) *RomajiConverter {
+	t.Helper()
+	rc := &RomajiConverter{dict: make(map[string][]string), readings: make(map[string][]string), trie: newReadingTrie()}
+	require.NoError(t, rc.loadDictionary([]byte(testSKKDict)))
+	return rc
+}
+
+func TestRomajiConverter_Convert(t *testing.T) {
+	rc := newTestRomajiConverter(t)
+
+	tests := []struct {
+		name       string
+		query      string
+		contains   []string
+		excludes   []string
+		wantLength int // 0 to skip
+	}{
+		{name: "whole reading", query: "kaigi", contains: []string{"kaigi", "かいぎ", "カイギ", "会議", "懐疑"}, excludes: []string{"会議録"}},
+		{name: "pending consonant", query: "kaig", contains: []string{"かい", "会議", "懐疑", "会議録"}},
+		{name: "pending digraph", query: "yot", contains: []string{"予定"}},
+		{name: "partial kana", query: "kaigiro", contains: []string{"会議録"}},
+		{name: "compound", query: "kaigishitsu", contains: []string{"かいぎしつ", "会議室", "会議質", "懐疑室"}},
+		{name: "japanese compound", query: "かいぎしつ", contains: []string{"会議室"}},
+		{name: "unknown kana kept in compound", query: "kaiginoshitsu", contains: []string{"会議の室"}},
+		{name: "english word is not completed", query: "test", excludes: []string{"鉄"}},
+		{name: "single kana is not completed", query: "ka", excludes: []string{"会"}},
+		{name: "no candidates", query: "xyz", wantLength: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rc.Convert(tt.query)
+			for _, want := range tt.contains {
+				assert.Contains(t, got, want)
+			}
+			for _, unwanted := range tt.excludes {
+				assert.NotContains(t, got, unwanted)
+			}
+			if tt.wantLength > 0 {
+				assert.Len(t, got, tt.wantLength)
+			}
+		})
+	}
+}
+
+func TestRomajiConverter_SplitRomaji(t *testing.T) {
+	rc := newTestRomajiConverter(t)
+
+	tests := []struct {
+		romaji      string
+		wantKana    string
+		wantPending string
+		wantOK      bool
+	}{
+		{"kaigi", "かいぎ", "", true},
+		{"kaig", "かい", "g", true},
+		{"kaish", "かい", "sh", true},
+		{"kak", "か", "k", true},
+		{"golang", "ごあん", "g", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.romaji, func(t *testing.T) {
+			kana, pending, ok := rc.splitRomaji(tt.romaji)
+			assert.Equal(t, tt.wantKana, kana)
+			assert.Equal(t, tt.wantPending, pending)
+			assert.Equal(t, tt.wantOK, ok)
+		})
+	}
+}
+
+func TestReadingTrie(t *testing.T) {
+	trie := newReadingTrie()
+	for _, r := range []string{"かいぎ", "かい", "かいぎろく", "しつ"} {
+		trie.insert(r)
+	}
+	trie.insert("かい") // duplicates are ignored
+
+	assert.NotZero(t, trie.nodes[trie.find("かいぎ")].reading)
+	assert.Zero(t, trie.nodes[trie.find("かいぎろ")].reading)
+	assert.Equal(t, int32(-1), trie.find("き"))
+	assert.Equal(t, []string{"かい", "かいぎ", "かいぎろく"}, trie.withPrefix("か", 10))
+	assert.Equal(t, []string{"かい", "かいぎ"}, trie.withPrefix("か", 2))
+	assert.Equal(t, []string{"かいぎろく"}, trie.withPrefix("かいぎろ", 10))
+	assert.Empty(t, trie.withPrefix("き", 10))
+	assert.Len(t, trie.readings, 4)
+}
+
+func TestRomajiConverter_ConvertJapanese(t *testing.T) {
+	rc := newTestRomajiConverter(t)
+
+	tests := []struct {
+		name     string
+		query    string
+		contains []string
+	}{
+		{name: "kanji expands to readings", query: "会議", contains: []string{"会議", "かいぎ", "kaigi"}},
+		{name: "word of several readings", query: "会", contains: []string{"かい", "kai"}},
+		{name: "hiragana", query: "よてい", contains: []string{"yotei", "予定"}},
+		{name: "katakana", query: "カイギ", contains: []string{"かいぎ", "kaigi", "会議"}},
+		{name: "half-width katakana", query: "ｶｲｷﾞ", contains: []string{"かいぎ", "kaigi", "会議"}},
+		{name: "full-width romaji", query: "ｋａｉｇｉ", contains: []string{"かいぎ", "会議"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rc.Convert(tt.query)
+			for _, want := range tt.contains {
+				assert.Contains(t, got, want)
+			}
+		})
+	}
+}
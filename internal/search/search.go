@@ -1,9 +1,12 @@
 package search
 
 import (
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/hirotoni/memov2/internal/domain"
+	"github.com/hirotoni/memov2/internal/domain/markdown"
 )
 
 type MatchType int
@@ -13,11 +16,40 @@ const (
 	MatchCategory
 	MatchContent
 	MatchHeading
+	MatchTag
+	MatchTask
 )
 
+// SearchResult is a memo or a task matching a query
 type SearchResult struct {
-	Memo    domain.MemoFileInterface
+	Memo    domain.MemoFileInterface // nil for a task
+	Task    *Task                    // nil for a memo
 	Matches []Match
+	Score   float64 // relevance, set by Score
+}
+
+// Task is a checkbox item of a daily todo file
+type Task struct {
+	markdown.Task
+	Path string    // of the todo file, relative to the todos directory
+	Date time.Time // of the todo file
+}
+
+// Date returns the date of the memo, or of the todo file holding the task
+func (r SearchResult) Date() time.Time {
+	if r.Task != nil {
+		return r.Task.Date
+	}
+	return r.Memo.Date()
+}
+
+// Path returns the path of the memo relative to the memos directory, or of the
+// todo file holding the task relative to the todos directory
+func (r SearchResult) Path() string {
+	if r.Task != nil {
+		return r.Task.Path
+	}
+	return filepath.Join(r.Memo.Location(), r.Memo.FileName())
 }
 
 type Match struct {
@@ -28,33 +60,68 @@ type Match struct {
 	PrevLineContext string
 	NextLineContext string
 	Heading         string // If match is under a heading
+	Positions       []int  // Byte offsets of the matched characters in Content, for fuzzy matches
 }
 
+// SearchMemo returns the matches of matchType in memo containing query, ignoring
+// the differences Normalize folds
 func SearchMemo(memo domain.MemoFileInterface, query string, matchType MatchType) SearchResult {
+	normalized := Normalize(strings.TrimSpace(query))
+	return searchMemo(memo, matchType, func(text string) ([]int, bool) {
+		return nil, strings.Contains(Normalize(text), normalized)
+	})
+}
+
+// FuzzySearchMemo returns the matches of matchType in memo fuzzily matching
+// query, as FuzzyMatch does. Matches record the matched positions.
+func FuzzySearchMemo(memo domain.MemoFileInterface, query string, matchType MatchType) SearchResult {
+	return searchMemo(memo, matchType, func(text string) ([]int, bool) {
+		f, ok := FuzzyMatch(query, text)
+		return f.Positions, ok
+	})
+}
+
+// searchMemo returns the matches of matchType in memo; match reports whether a
+// text matches and the positions to highlight
+func searchMemo(memo domain.MemoFileInterface, matchType MatchType, match func(text string) ([]int, bool)) SearchResult {
 	result := SearchResult{
 		Memo:    memo,
 		Matches: []Match{},
 	}
 
-	queryLower := strings.ToLower(strings.TrimSpace(query))
-
 	// For title matches
-	if matchType == MatchTitle && strings.Contains(strings.ToLower(memo.Title()), queryLower) {
-		result.Matches = append(result.Matches, Match{
-			Type:    MatchTitle,
-			Content: memo.Title(),
-		})
+	if matchType == MatchTitle {
+		if positions, ok := match(memo.Title()); ok {
+			result.Matches = append(result.Matches, Match{
+				Type:      MatchTitle,
+				Content:   memo.Title(),
+				Positions: positions,
+			})
+		}
+	}
+
+	// For category matches; the query may span levels, as in work/projects
+	if matchType == MatchCategory && len(memo.CategoryTree()) > 0 {
+		path := strings.Join(memo.CategoryTree(), "/")
+		if positions, ok := match(path); ok {
+			result.Matches = append(result.Matches, Match{
+				Type:      MatchCategory,
+				Content:   path,
+				Positions: positions,
+			})
+		}
 	}
 
-	// For category matches
-	if matchType == MatchCategory {
-		for _, category := range memo.CategoryTree() {
-			if strings.Contains(strings.ToLower(category), queryLower) {
+	// For tag matches
+	if matchType == MatchTag {
+		tags := memo.Tags()
+		for _, tag := range tags {
+			if positions, ok := match(tag); ok {
 				result.Matches = append(result.Matches, Match{
-					Type:    MatchCategory,
-					Content: strings.Join(memo.CategoryTree(), "/"),
+					Type:      MatchTag,
+					Content:   tag,
+					Positions: positions,
 				})
-				break
 			}
 		}
 	}
@@ -64,7 +131,10 @@ func SearchMemo(memo domain.MemoFileInterface, query string, matchType MatchType
 		// Top level body content
 		lines := strings.Split(memo.TopLevelBodyContent().ContentText, "\n")
 		for i, line := range lines {
-			if strings.Contains(strings.ToLower(line), queryLower) {
+			if matchType != MatchContent {
+				break
+			}
+			if positions, ok := match(line); ok {
 				var prevLineContext string
 				var nextLineContext string
 
@@ -84,24 +154,28 @@ func SearchMemo(memo domain.MemoFileInterface, query string, matchType MatchType
 					PrevLineContext: prevLineContext,
 					NextLineContext: nextLineContext,
 					Heading:         memo.TopLevelBodyContent().HeadingText,
+					Positions:       positions,
 				})
 			}
 		}
 
 		// Heading blocks
 		for i, block := range memo.HeadingBlocks() {
-			if matchType == MatchHeading && strings.Contains(strings.ToLower(block.HeadingText), queryLower) {
-				result.Matches = append(result.Matches, Match{
-					Type:         MatchHeading,
-					Content:      block.HeadingText,
-					HeadingOrder: i,
-					Line:         i,
-				})
+			if matchType == MatchHeading {
+				if positions, ok := match(block.HeadingText); ok {
+					result.Matches = append(result.Matches, Match{
+						Type:         MatchHeading,
+						Content:      block.HeadingText,
+						HeadingOrder: i,
+						Line:         i,
+						Positions:    positions,
+					})
+				}
 			} else if matchType == MatchContent {
 				// Split content into lines for line number tracking
 				lines := strings.Split(block.ContentText, "\n")
 				for j, line := range lines {
-					if strings.Contains(strings.ToLower(line), queryLower) {
+					if positions, ok := match(line); ok {
 						// Get context lines
 						var prevLineContext string
 						var nextLineContext string
@@ -124,6 +198,7 @@ func SearchMemo(memo domain.MemoFileInterface, query string, matchType MatchType
 							PrevLineContext: prevLineContext,
 							NextLineContext: nextLineContext,
 							Heading:         block.HeadingText,
+							Positions:       positions,
 						})
 					}
 				}
@@ -87,6 +87,27 @@ func TestSearchMemo(t *testing.T) {
 			wantMatch:     true,
 			wantMatchText: "Meeting Notes 会議メモ",
 		},
+		{
+			name:          "Heading match hiragana for katakana",
+			query:         "りふぁれんす",
+			matchType:     MatchHeading,
+			wantMatch:     true,
+			wantMatchText: "References リファレンス",
+		},
+		{
+			name:          "Heading match half-width katakana",
+			query:         "ﾒﾓ",
+			matchType:     MatchHeading,
+			wantMatch:     true,
+			wantMatchText: "Meeting Notes 会議メモ",
+		},
+		{
+			name:          "Heading match full-width latin",
+			query:         "ＮＯＴＥＳ",
+			matchType:     MatchHeading,
+			wantMatch:     true,
+			wantMatchText: "Meeting Notes 会議メモ",
+		},
 		{
 			name:      "Heading no match",
 			query:     "NonExistent",
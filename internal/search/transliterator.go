@@ -0,0 +1,59 @@
+package search
+
+import "fmt"
+
+// Transliterator expands a search word into the spellings memos may use for
+// it, in other scripts, the word itself first
+type Transliterator interface {
+	Convert(query string) []string
+}
+
+// Names of the built-in transliterators, as listed in search_transliterators
+const (
+	TransliteratorRomaji = "romaji" // Japanese, with the SKK dictionaries
+	TransliteratorPinyin = "pinyin" // Chinese
+	TransliteratorHangul = "hangul" // Korean
+)
+
+// Transliterators lists the names of the built-in transliterators
+var Transliterators = []string{TransliteratorRomaji, TransliteratorPinyin, TransliteratorHangul}
+
+// NewTransliterator returns the built-in transliterators named in names as one,
+// merging their spellings in the order named. dictionaries are the SKK
+// dictionaries the romaji transliterator loads besides the embedded one.
+func NewTransliterator(names []string, dictionaries []string) (Transliterator, error) {
+	var ts transliterators
+	for _, name := range names {
+		switch name {
+		case TransliteratorRomaji:
+			rc, err := NewRomajiConverter(dictionaries...)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load SKK dictionary: %w", err)
+			}
+			ts = append(ts, rc)
+		case TransliteratorPinyin:
+			pc, err := NewPinyinConverter()
+			if err != nil {
+				return nil, err
+			}
+			ts = append(ts, pc)
+		case TransliteratorHangul:
+			ts = append(ts, NewHangulConverter())
+		default:
+			return nil, fmt.Errorf("unknown transliterator %q (want one of %v)", name, Transliterators)
+		}
+	}
+	return ts, nil
+}
+
+// transliterators runs several transliterators as one
+type transliterators []Transliterator
+
+// Convert returns the spellings of all transliterators, without duplicates
+func (ts transliterators) Convert(query string) []string {
+	results := []string{query}
+	for _, t := range ts {
+		results = append(results, t.Convert(query)...)
+	}
+	return removeDuplicates(results)
+}
@@ -0,0 +1,26 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTransliterator(t *testing.T) {
+	tr, err := NewTransliterator([]string{TransliteratorPinyin, TransliteratorHangul}, nil)
+	require.NoError(t, err)
+	got := tr.Convert("hanguk")
+	assert.Equal(t, "hanguk", got[0])
+	assert.Contains(t, got, "한국")
+
+	got = tr.Convert("会议")
+	assert.Contains(t, got, "huiyi")
+
+	none, err := NewTransliterator(nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"kaigi"}, none.Convert("kaigi"))
+
+	_, err = NewTransliterator([]string{"klingon"}, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,165 @@
+package search
+
+import "math"
+
+// readingTrie is a prefix tree over the readings of the SKK dictionary. Nodes
+// are kept in a single slice and link to their first child and next sibling,
+// which keeps the ~170k readings of SKK-JISYO.L small in memory.
+type readingTrie struct {
+	nodes    []trieNode // nodes[0] is the root
+	readings []string
+}
+
+type trieNode struct {
+	r       rune
+	child   int32 // index of the first child, 0 if none
+	next    int32 // index of the next sibling, 0 if none
+	reading int32 // index+1 of the reading ending here, 0 if none
+}
+
+func newReadingTrie() *readingTrie {
+	return &readingTrie{nodes: []trieNode{{}}}
+}
+
+// insert adds a reading to the trie
+func (t *readingTrie) insert(reading string) {
+	n := int32(0)
+	for _, r := range reading {
+		c := t.child(n, r)
+		if c == 0 {
+			c = int32(len(t.nodes))
+			t.nodes = append(t.nodes, trieNode{r: r, next: t.nodes[n].child})
+			t.nodes[n].child = c
+		}
+		n = c
+	}
+	if t.nodes[n].reading == 0 {
+		t.readings = append(t.readings, reading)
+		t.nodes[n].reading = int32(len(t.readings))
+	}
+}
+
+// child returns the child of node n for r, or 0
+func (t *readingTrie) child(n int32, r rune) int32 {
+	for c := t.nodes[n].child; c != 0; c = t.nodes[c].next {
+		if t.nodes[c].r == r {
+			return c
+		}
+	}
+	return 0
+}
+
+// find returns the node reached by prefix, or -1
+func (t *readingTrie) find(prefix string) int32 {
+	n := int32(0)
+	for _, r := range prefix {
+		if n = t.child(n, r); n == 0 {
+			return -1
+		}
+	}
+	return n
+}
+
+// withPrefix returns up to limit readings starting with prefix, shortest first.
+// prefix itself is included if it is a reading.
+func (t *readingTrie) withPrefix(prefix string, limit int) []string {
+	start := t.find(prefix)
+	if start < 0 || limit <= 0 {
+		return nil
+	}
+
+	var readings []string
+	queue := []int32{start}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		if r := t.nodes[n].reading; r != 0 {
+			readings = append(readings, t.readings[r-1])
+			if len(readings) == limit {
+				break
+			}
+		}
+		for c := t.nodes[n].child; c != 0; c = t.nodes[c].next {
+			queue = append(queue, c)
+		}
+	}
+	return readings
+}
+
+// segmentations splits reading into readings of t, using as few as possible,
+// and returns the words the dict candidates of the segments spell. Unless
+// strict, characters starting no reading are kept as they are. It returns nil
+// unless the reading splits into two or more segments, one of them a reading.
+func segmentations(t *readingTrie, dict map[string][]string, reading string, strict bool) []string {
+	runes := []rune(reading)
+	n := len(runes)
+	if n < 2 {
+		return nil
+	}
+
+	// cost[i] is the cost of the best split of runes[:i], ending with runes[from[i]:i].
+	// A character left as is costs more than a reading, so readings are preferred.
+	const unknownCost = 2
+	cost := make([]int, n+1)
+	from := make([]int, n+1)
+	known := make([]bool, n+1) // runes[from[i]:i] is a reading
+	for i := 1; i <= n; i++ {
+		cost[i] = math.MaxInt
+	}
+	relax := func(i, j, c int, isReading bool) {
+		if c < cost[j] {
+			cost[j], from[j], known[j] = c, i, isReading
+		}
+	}
+	for i := 0; i < n; i++ {
+		if cost[i] == math.MaxInt {
+			continue // no split reaches i
+		}
+		if !strict {
+			relax(i, i+1, cost[i]+unknownCost, false)
+		}
+		node := int32(0)
+		for j := i; j < n; j++ {
+			if node = t.child(node, runes[j]); node == 0 {
+				break
+			}
+			if t.nodes[node].reading != 0 {
+				relax(i, j+1, cost[i]+1, true)
+			}
+		}
+	}
+
+	if cost[n] == math.MaxInt {
+		return nil
+	}
+
+	// Walk the best split back into segments, each with its spellings
+	var segments [][]string
+	anyKnown := false
+	for j := n; j > 0; j = from[j] {
+		segment := string(runes[from[j]:j])
+		spellings := []string{segment}
+		if candidates := dict[segment]; known[j] && len(candidates) > 0 {
+			anyKnown = true
+			spellings = candidates[:min(len(candidates), maxSegmentCandidates)]
+		}
+		segments = append([][]string{spellings}, segments...)
+	}
+	if len(segments) < 2 || !anyKnown {
+		return nil
+	}
+
+	words := []string{""}
+	for _, spellings := range segments {
+		var next []string
+		for _, w := range words {
+			for _, s := range spellings {
+				if len(next) < maxSegmentations {
+					next = append(next, w+s)
+				}
+			}
+		}
+		words = next
+	}
+	return words
+}
@@ -5,4 +5,8 @@ func (uc config) Show() {
 	uc.logger.Info("Configuration", "todos_dir", uc.config.TodosDir())
 	uc.logger.Info("Configuration", "memos_dir", uc.config.MemosDir())
 	uc.logger.Info("Configuration", "todos_daystoseek", uc.config.TodosDaysToSeek())
+	uc.logger.Info("Configuration", "search_dictionaries", uc.config.SearchDictionaries())
+	uc.logger.Info("Configuration", "search_transliterators", uc.config.SearchTransliterators())
+	uc.logger.Info("Configuration", "title_source", uc.config.TitleSource())
+	uc.logger.Info("Configuration", "title_sync", uc.config.TitleSync())
 }
@@ -0,0 +1,327 @@
+package doctor
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/hirotoni/memov2/internal/config"
+	"github.com/hirotoni/memov2/internal/domain"
+	"github.com/hirotoni/memov2/internal/domain/markdown"
+	"github.com/hirotoni/memov2/internal/interfaces"
+	"github.com/hirotoni/memov2/internal/platform"
+	repoCommon "github.com/hirotoni/memov2/internal/repositories/common"
+	memoRepo "github.com/hirotoni/memov2/internal/repositories/memo"
+)
+
+// Finding codes
+const (
+	codeFrontmatterMalformed  = "frontmatter-malformed"
+	codeMemoUnparseable       = "memo-unparseable"
+	codeTitleMissing          = "title-missing"
+	codeTitleMismatch         = "title-mismatch"
+	codeDuplicateTimestamp    = "duplicate-timestamp"
+	codeFilenameNonconforming = "filename-nonconforming"
+	codeDirectoryEmpty        = "directory-empty"
+	codeLinkBroken            = "link-broken"
+	codeTodoHeadingMismatch   = "todo-heading-mismatch"
+)
+
+var (
+	memoFileNameRegex = regexp.MustCompile(domain.FileNameRegexMemo)
+	memoDateTimeRegex = regexp.MustCompile(domain.FileNameDateTimeRegexMemo)
+	todoFileNameRegex = regexp.MustCompile(domain.FileNameRegexTodo)
+)
+
+// generatedMemoFiles are the files memos index and memos weekly write at the
+// top of the memos directory
+var generatedMemoFiles = []string{"index.md", "weekly_report.md"}
+
+// checkMemos checks every memo file, the names of the markdown files and the
+// directories under the memos directory
+func (d doctor) checkMemos() ([]finding, error) {
+	memosDir := d.config.MemosDir()
+	if !platform.Exists(memosDir) {
+		return nil, nil
+	}
+
+	var findings []finding
+	var dirs []string
+	nonEmpty := make(map[string]bool)   // directories with a file somewhere under them
+	stamps := make(map[string][]string) // memo paths by the date and time in their names
+	err := filepath.WalkDir(memosDir, func(path string, de fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if de.IsDir() {
+			if path != memosDir {
+				dirs = append(dirs, path)
+			}
+			return nil
+		}
+		for dir := filepath.Dir(path); dir != memosDir && !nonEmpty[dir]; dir = filepath.Dir(dir) {
+			nonEmpty[dir] = true
+		}
+
+		name := de.Name()
+		if filepath.Ext(name) != domain.FileExtension {
+			return nil
+		}
+		if !memoFileNameRegex.MatchString(name) {
+			if filepath.Dir(path) == memosDir && slices.Contains(generatedMemoFiles, name) {
+				return nil
+			}
+			findings = append(findings, finding{
+				Code:     codeFilenameNonconforming,
+				Severity: severityWarning,
+				Path:     d.relPath(path),
+				Message:  "file name is not a memo file name (YYYYMMDDDowhhmmss_memo_title.md)",
+			})
+			return nil
+		}
+
+		stamp := memoDateTimeRegex.FindString(name)
+		stamps[stamp] = append(stamps[stamp], path)
+		memoFindings, err := d.checkMemo(path, de)
+		if err != nil {
+			return err
+		}
+		findings = append(findings, memoFindings...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for stamp, paths := range stamps {
+		if len(paths) < 2 {
+			continue
+		}
+		for _, path := range paths {
+			var others []string
+			for _, other := range paths {
+				if other != path {
+					others = append(others, d.relPath(other))
+				}
+			}
+			findings = append(findings, finding{
+				Code:     codeDuplicateTimestamp,
+				Severity: severityWarning,
+				Path:     d.relPath(path),
+				Message:  fmt.Sprintf("timestamp %s is shared with %s", stamp, strings.Join(others, ", ")),
+			})
+		}
+	}
+
+	for _, dir := range dirs {
+		// Report only the topmost of nested empty directories
+		if nonEmpty[dir] || (filepath.Dir(dir) != memosDir && !nonEmpty[filepath.Dir(dir)]) {
+			continue
+		}
+		findings = append(findings, finding{
+			Code:     codeDirectoryEmpty,
+			Severity: severityInfo,
+			Path:     d.relPath(dir),
+			Message:  "category directory holds no files",
+			Fixable:  true,
+			fix:      func() error { return removeEmptyDirectories(dir) },
+		})
+	}
+
+	return findings, nil
+}
+
+// checkMemo checks the frontmatter, title and links of the memo file at path
+func (d doctor) checkMemo(path string, de fs.DirEntry) ([]finding, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rel := d.relPath(path)
+	findings := d.checkLinks(path, b)
+
+	if yamlText, _, ok := markdown.SplitFrontmatter(b); ok {
+		if _, err := markdown.ParseFrontmatter(yamlText); err != nil {
+			return append(findings, finding{
+				Code:     codeFrontmatterMalformed,
+				Severity: severityError,
+				Path:     rel,
+				Line:     1,
+				Message:  fmt.Sprintf("frontmatter cannot be parsed, the memo is skipped by every command: %v", err),
+			}), nil
+		}
+	}
+
+	parser := repoCommon.AcquireMarkdownParser()
+	heading := parser.TopLevelBodyContent(b)
+	repoCommon.ReleaseMarkdownParser(parser)
+	if heading == nil {
+		return append(findings, finding{
+			Code:     codeTitleMissing,
+			Severity: severityError,
+			Path:     rel,
+			Message:  "memo does not start with an H1 title, it is skipped by every command",
+		}), nil
+	}
+
+	info, err := de.Info()
+	if err != nil {
+		return nil, err
+	}
+	m, err := memoRepo.ParseMemoFile(path, info, d.logger)
+	if err != nil {
+		return append(findings, finding{
+			Code:     codeMemoUnparseable,
+			Severity: severityError,
+			Path:     rel,
+			Message:  fmt.Sprintf("memo cannot be parsed, it is skipped by every command: %v", err),
+		}), nil
+	}
+
+	title, ok := domain.SyncedTitle(m, d.config.TitleSource() == config.TitleSourceHeading)
+	if ok {
+		// Renaming finds the memo where its category puts it, so only a memo
+		// already there can be renamed
+		inPlace := filepath.Join(d.config.MemosDir(), m.Location(), m.FileName()) == path
+		f := finding{
+			Code:     codeTitleMismatch,
+			Severity: severityWarning,
+			Path:     rel,
+			Line:     heading.LineNumber,
+			Message:  fmt.Sprintf("H1 %q does not match the title %q in the file name", heading.HeadingText, m.Title()),
+			Fixable:  inPlace,
+		}
+		if inPlace {
+			f.rename = &interfaces.MemoRename{File: m, NewTitle: title}
+		}
+		findings = append(findings, f)
+	}
+
+	return findings, nil
+}
+
+// checkLinks reports the relative links in the file at path whose target does
+// not exist
+func (d doctor) checkLinks(path string, content []byte) []finding {
+	var findings []finding
+	for _, link := range markdown.LinkDestinations(string(content)) {
+		target, ok := link.RelativePath()
+		if !ok || platform.Exists(filepath.Join(filepath.Dir(path), filepath.FromSlash(target))) {
+			continue
+		}
+		findings = append(findings, finding{
+			Code:     codeLinkBroken,
+			Severity: severityWarning,
+			Path:     d.relPath(path),
+			Line:     link.Line + 1,
+			Message:  fmt.Sprintf("link target %q does not exist", link.Dest),
+		})
+	}
+	return findings
+}
+
+// checkTodos checks the H1 and links of every daily todo file
+func (d doctor) checkTodos() ([]finding, error) {
+	todosDir := d.config.TodosDir()
+	if !platform.Exists(todosDir) {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(todosDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []finding
+	for _, entry := range entries {
+		if entry.IsDir() || !todoFileNameRegex.MatchString(entry.Name()) {
+			continue
+		}
+		path := filepath.Join(todosDir, entry.Name())
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, d.checkLinks(path, b)...)
+
+		date, err := repoCommon.ParseDateFromFilename(entry.Name(), repoCommon.DateParserConfig{
+			DateTimeRegex: domain.FileNameDateTimeRegexTodo,
+			DateLayout:    domain.FileNameDateLayoutTodo,
+		})
+		if err != nil {
+			findings = append(findings, finding{
+				Code:     codeFilenameNonconforming,
+				Severity: severityWarning,
+				Path:     d.relPath(path),
+				Message:  fmt.Sprintf("file name has no valid date: %v", err),
+			})
+			continue
+		}
+
+		want := date.Format(domain.FileNameDateLayoutTodo)
+		parser := repoCommon.AcquireMarkdownParser()
+		heading := parser.TopLevelBodyContent(b)
+		repoCommon.ReleaseMarkdownParser(parser)
+		if heading != nil && heading.HeadingText == want {
+			continue
+		}
+
+		f := finding{
+			Code:     codeTodoHeadingMismatch,
+			Severity: severityWarning,
+			Path:     d.relPath(path),
+			Message:  fmt.Sprintf("todo file does not start with the H1 %q", want),
+		}
+		if heading != nil {
+			f.Line = heading.LineNumber
+			f.Message = fmt.Sprintf("H1 %q does not match the date %q", heading.HeadingText, want)
+			// Saving a todo file keeps only its sections, so text under the H1 would be lost
+			if heading.ContentText == "" {
+				f.Fixable = true
+				f.fix = func() error {
+					todos, err := d.repos.Todo().FindTodosFileByDate(date)
+					if err != nil {
+						return err
+					}
+					return d.repos.Todo().Save(todos, true)
+				}
+			}
+		}
+		findings = append(findings, f)
+	}
+	return findings, nil
+}
+
+// removeEmptyDirectories removes dir and the directories under it, deepest
+// first. A directory that is no longer empty is left in place.
+func removeEmptyDirectories(dir string) error {
+	var dirs []string
+	err := filepath.WalkDir(dir, func(path string, de fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if de.IsDir() {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, path := range slices.Backward(dirs) {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return err
+		}
+		if len(entries) > 0 {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package doctor
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/hirotoni/memov2/internal/common"
+	"github.com/hirotoni/memov2/internal/interfaces"
+)
+
+type doctor struct {
+	config interfaces.ConfigProvider
+	repos  interfaces.Repositories
+	logger *slog.Logger
+}
+
+func NewDoctor(c interfaces.ConfigProvider, r interfaces.Repositories, logger *slog.Logger) interfaces.DoctorService {
+	return doctor{
+		config: c,
+		repos:  r,
+		logger: logger,
+	}
+}
+
+// Output formats of Check
+const (
+	formatText = "text"
+	formatJSON = "json"
+)
+
+// Severities of findings. Errors and warnings fail the check; infos do not.
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+	severityInfo    = "info"
+)
+
+// finding is a problem found in the vault. Codes are stable, so that hooks and
+// scripts can match them.
+type finding struct {
+	Code     string `json:"code"`
+	Severity string `json:"severity"`
+	Path     string `json:"path"` // relative to the base directory
+	Line     int    `json:"line,omitempty"`
+	Message  string `json:"message"`
+	Fixable  bool   `json:"fixable"`
+	Fixed    bool   `json:"fixed"`
+
+	fix    func() error           // nil if the finding cannot be fixed
+	rename *interfaces.MemoRename // fixed by renaming, together with the others
+}
+
+// failing reports whether the finding fails the check
+func (f finding) failing() bool {
+	return f.Severity != severityInfo && !f.Fixed
+}
+
+// Check looks for problems in the memos and todos and prints them in format:
+// text or json. With fix it repairs those that can be repaired with the
+// repository operations. It returns an error if errors or warnings remain, so
+// it can be run in hooks. Backs the `doctor` command.
+func (d doctor) Check(fix bool, format string) error {
+	if format != formatText && format != formatJSON {
+		return common.New(common.ErrorTypeValidation, fmt.Sprintf("unknown output format: %s (want text or json)", format))
+	}
+
+	findings, err := d.findings()
+	if err != nil {
+		return err
+	}
+	if fix {
+		d.fix(findings)
+	}
+
+	if err := writeFindings(os.Stdout, findings, format); err != nil {
+		return err
+	}
+
+	failing := 0
+	for _, f := range findings {
+		if f.failing() {
+			failing++
+		}
+	}
+	if failing > 0 {
+		return common.New(common.ErrorTypeValidation, fmt.Sprintf("%d problems found", failing))
+	}
+	return nil
+}
+
+// findings runs every check, ordering the findings by path and line
+func (d doctor) findings() ([]finding, error) {
+	memoFindings, err := d.checkMemos()
+	if err != nil {
+		return nil, common.Wrap(err, common.ErrorTypeService, "error checking memos")
+	}
+	todoFindings, err := d.checkTodos()
+	if err != nil {
+		return nil, common.Wrap(err, common.ErrorTypeService, "error checking todos")
+	}
+
+	findings := append(memoFindings, todoFindings...)
+	slices.SortStableFunc(findings, func(a, b finding) int {
+		if c := strings.Compare(a.Path, b.Path); c != 0 {
+			return c
+		}
+		return a.Line - b.Line
+	})
+	return findings, nil
+}
+
+// fix repairs the fixable findings. One that cannot be repaired is logged and
+// left unfixed.
+func (d doctor) fix(findings []finding) {
+	var renames []*finding
+	for i := range findings {
+		f := &findings[i]
+		if f.rename != nil {
+			renames = append(renames, f)
+			continue
+		}
+		if f.fix == nil {
+			continue
+		}
+		if err := f.fix(); err != nil {
+			d.logger.Warn("Could not fix", "code", f.Code, "path", f.Path, "error", err)
+			continue
+		}
+		f.Fixed = true
+	}
+	d.fixRenames(renames)
+}
+
+// fixRenames makes the renames of the findings in one batch, so the memos
+// the wiki links resolve against are read once rather than per rename. After
+// a rename that fails the batch goes on with the next.
+func (d doctor) fixRenames(findings []*finding) {
+	for len(findings) > 0 {
+		renames := make([]interfaces.MemoRename, len(findings))
+		for i, f := range findings {
+			renames[i] = *f.rename
+		}
+		n, err := d.repos.Memo().RenameAll(renames)
+		for _, f := range findings[:n] {
+			f.Fixed = true
+		}
+		if err == nil {
+			return
+		}
+		d.logger.Warn("Could not fix", "code", findings[n].Code, "path", findings[n].Path, "error", err)
+		findings = findings[n+1:]
+	}
+}
+
+// relPath returns path relative to the base directory, in slash form
+func (d doctor) relPath(path string) string {
+	rel, err := filepath.Rel(d.config.BaseDir(), path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+func writeFindings(w io.Writer, findings []finding, format string) error {
+	switch format {
+	case formatJSON:
+		if findings == nil {
+			findings = []finding{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(findings); err != nil {
+			return common.Wrap(err, common.ErrorTypeService, "error encoding findings")
+		}
+	case formatText:
+		for _, f := range findings {
+			location := f.Path
+			if f.Line > 0 {
+				location = fmt.Sprintf("%s:%d", f.Path, f.Line)
+			}
+			note := ""
+			if f.Fixed {
+				note = " (fixed)"
+			} else if f.Fixable {
+				note = " (fixable with --fix)"
+			}
+			fmt.Fprintf(w, "%s: %s %s: %s%s\n", location, f.Severity, f.Code, f.Message, note)
+		}
+	}
+	return nil
+}
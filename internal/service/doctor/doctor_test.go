@@ -0,0 +1,174 @@
+package doctor
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hirotoni/memov2/internal/config"
+	"github.com/hirotoni/memov2/internal/config/toml"
+	"github.com/hirotoni/memov2/internal/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFile writes content at rel under dir, creating its directories
+func writeFile(t *testing.T, dir, rel, content string) {
+	t.Helper()
+	path := filepath.Join(dir, rel)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func TestCheck(t *testing.T) {
+	// Setup
+	tmpDir := t.TempDir()
+	cfg, err := toml.NewConfig(toml.Option{BaseDir: tmpDir, TitleSource: config.TitleSourceFileName})
+	require.NoError(t, err)
+
+	configProvider := toml.NewProvider(cfg)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	d := NewDoctor(configProvider, repositories.NewRepositories(configProvider, logger), logger).(doctor)
+
+	memosDir, todosDir := cfg.MemosDir(), cfg.TodosDir()
+	stamp := "20250214Fri090000"
+	writeFile(t, memosDir, "work/"+stamp+"_memo_plan.md", "---\ncategory: [\"work\"]\n---\n\n# plan\n\n[ok](../"+stamp+"_memo_new-title.md) [gone](missing.md#sec) [web](https://example.com)\n")
+	writeFile(t, memosDir, stamp+"_memo_new-title.md", "# old title\n\n## notes\n\nkept\n")
+	writeFile(t, memosDir, "misc/20250215Sat090000_memo_broken.md", "---\ncategory: [unterminated\n---\n\n# broken\n")
+	writeFile(t, memosDir, "misc/20250216Sun090000_memo_untitled.md", "no heading\n")
+	writeFile(t, memosDir, "misc/README.md", "# readme\n")
+	writeFile(t, memosDir, "index.md", "# index\n")
+	require.NoError(t, os.MkdirAll(filepath.Join(memosDir, "old", "sub"), 0o755))
+	writeFile(t, todosDir, "20250214Fri_todos.md", "# wrong\n\n## todos\n\n- [ ] a\n")
+	writeFile(t, todosDir, "20250215Sat_todos.md", "# 20250215Sat\n\n## todos\n")
+
+	type result struct{ code, path string }
+	collect := func() []result {
+		findings, err := d.findings()
+		require.NoError(t, err)
+		var results []result
+		for _, f := range findings {
+			results = append(results, result{f.Code, f.Path})
+		}
+		return results
+	}
+
+	// Execute
+	assert.Equal(t, []result{
+		{codeDuplicateTimestamp, "memos/" + stamp + "_memo_new-title.md"},
+		{codeTitleMismatch, "memos/" + stamp + "_memo_new-title.md"},
+		{codeFrontmatterMalformed, "memos/misc/20250215Sat090000_memo_broken.md"},
+		{codeTitleMissing, "memos/misc/20250216Sun090000_memo_untitled.md"},
+		{codeFilenameNonconforming, "memos/misc/README.md"},
+		{codeDirectoryEmpty, "memos/old"},
+		{codeDuplicateTimestamp, "memos/work/" + stamp + "_memo_plan.md"},
+		{codeLinkBroken, "memos/work/" + stamp + "_memo_plan.md"},
+		{codeTodoHeadingMismatch, "todos/20250214Fri_todos.md"},
+	}, collect())
+
+	// Execute - fix
+	var out bytes.Buffer
+	findings, err := d.findings()
+	require.NoError(t, err)
+	d.fix(findings)
+	require.NoError(t, writeFindings(&out, findings, formatText))
+
+	// Assert
+	assert.Contains(t, out.String(), "memos/"+stamp+"_memo_new-title.md:1: warning title-mismatch: H1 \"old title\" does not match the title \"new-title\" in the file name (fixed)\n")
+	assert.Contains(t, out.String(), "memos/work/"+stamp+"_memo_plan.md:7: warning link-broken: link target \"missing.md#sec\" does not exist\n")
+	assert.Contains(t, out.String(), "memos/old: info directory-empty: category directory holds no files (fixed)\n")
+
+	b, err := os.ReadFile(filepath.Join(memosDir, stamp+"_memo_new-title.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "# new title\n")
+	assert.Contains(t, string(b), "kept")
+	b, err = os.ReadFile(filepath.Join(todosDir, "20250214Fri_todos.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "# 20250214Fri\n")
+	assert.Contains(t, string(b), "- [ ] a")
+	assert.NoDirExists(t, filepath.Join(memosDir, "old"))
+
+	assert.Equal(t, []result{
+		{codeDuplicateTimestamp, "memos/" + stamp + "_memo_new-title.md"},
+		{codeFrontmatterMalformed, "memos/misc/20250215Sat090000_memo_broken.md"},
+		{codeTitleMissing, "memos/misc/20250216Sun090000_memo_untitled.md"},
+		{codeFilenameNonconforming, "memos/misc/README.md"},
+		{codeDuplicateTimestamp, "memos/work/" + stamp + "_memo_plan.md"},
+		{codeLinkBroken, "memos/work/" + stamp + "_memo_plan.md"},
+	}, collect())
+}
+
+func TestFix_RenamesAfterFailure(t *testing.T) {
+	// Setup
+	tmpDir := t.TempDir()
+	cfg, err := toml.NewConfig(toml.Option{BaseDir: tmpDir, TitleSource: config.TitleSourceHeading})
+	require.NoError(t, err)
+
+	configProvider := toml.NewProvider(cfg)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	d := NewDoctor(configProvider, repositories.NewRepositories(configProvider, logger), logger).(doctor)
+
+	memosDir := cfg.MemosDir()
+	writeFile(t, memosDir, "20250214Fri090000_memo_a.md", "# taken\n")
+	writeFile(t, memosDir, "20250214Fri090000_memo_taken.md", "# taken\n")
+	writeFile(t, memosDir, "20250215Sat090000_memo_b.md", "# bee\n")
+
+	// Execute
+	findings, err := d.findings()
+	require.NoError(t, err)
+	d.fix(findings)
+
+	// Assert - the rename onto an existing file fails, the next one is made
+	fixed := map[string]bool{}
+	for _, f := range findings {
+		if f.Code == codeTitleMismatch {
+			fixed[f.Path] = f.Fixed
+		}
+	}
+	assert.Equal(t, map[string]bool{
+		"memos/20250214Fri090000_memo_a.md": false,
+		"memos/20250215Sat090000_memo_b.md": true,
+	}, fixed)
+	assert.FileExists(t, filepath.Join(memosDir, "20250214Fri090000_memo_a.md"))
+	assert.FileExists(t, filepath.Join(memosDir, "20250215Sat090000_memo_bee.md"))
+}
+
+func TestCheck_Format(t *testing.T) {
+	// Setup
+	tmpDir := t.TempDir()
+	cfg, err := toml.NewConfig(toml.Option{BaseDir: tmpDir})
+	require.NoError(t, err)
+
+	configProvider := toml.NewProvider(cfg)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	d := NewDoctor(configProvider, repositories.NewRepositories(configProvider, logger), logger)
+
+	// Execute & Assert
+	assert.Error(t, d.Check(false, "yaml"))
+	assert.NoError(t, d.Check(false, formatJSON))
+
+	var out bytes.Buffer
+	require.NoError(t, writeFindings(&out, nil, formatJSON))
+	assert.Equal(t, "[]\n", out.String())
+
+	out.Reset()
+	require.NoError(t, writeFindings(&out, []finding{{Code: codeLinkBroken, Severity: severityWarning, Path: "memos/a.md", Line: 3, Message: "m"}}, formatJSON))
+	assert.JSONEq(t, `[{"code":"link-broken","severity":"warning","path":"memos/a.md","line":3,"message":"m","fixable":false,"fixed":false}]`, out.String())
+}
+
+func TestRemoveEmptyDirectories(t *testing.T) {
+	// Setup - a file was written under old/b since the check
+	tmpDir := t.TempDir()
+	old := filepath.Join(tmpDir, "old")
+	require.NoError(t, os.MkdirAll(filepath.Join(old, "a", "deep"), 0o755))
+	writeFile(t, old, "b/new.md", "# New\n")
+
+	// Execute
+	require.NoError(t, removeEmptyDirectories(old))
+
+	// Assert - only the directories still empty are removed
+	assert.NoDirExists(t, filepath.Join(old, "a"))
+	assert.FileExists(t, filepath.Join(old, "b", "new.md"))
+}
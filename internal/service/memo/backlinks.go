@@ -0,0 +1,42 @@
+package memo
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hirotoni/memov2/internal/domain"
+	"github.com/mattn/go-runewidth"
+)
+
+// Backlinks prints every memo that links to the memo at path with a [[wiki link]]
+func (uc memo) Backlinks(path string) error {
+	target, err := uc.memoByPath(path)
+	if err != nil {
+		return err
+	}
+
+	backlinks, err := uc.repos.Memo().Backlinks(target)
+	if err != nil {
+		return err
+	}
+
+	maxWidth := 0
+	for _, bl := range backlinks {
+		if w := runewidth.StringWidth(bl.Source.Title()); w > maxWidth {
+			maxWidth = w
+		}
+	}
+
+	for _, bl := range backlinks {
+		title := bl.Source.Title()
+		padding := maxWidth - runewidth.StringWidth(title)
+		location := domain.MemoPath(bl.Source)
+		if bl.Section != "" {
+			location += "#" + bl.Section
+		}
+		fmt.Fprintf(os.Stdout, "%s%s\t%s\t%s\n", title, strings.Repeat(" ", padding), location, bl.Link.Raw)
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package memo
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hirotoni/memov2/internal/config/toml"
+	"github.com/hirotoni/memov2/internal/domain"
+	"github.com/hirotoni/memov2/internal/domain/markdown"
+	"github.com/hirotoni/memov2/internal/repositories"
+	"github.com/hirotoni/memov2/internal/repositories/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBacklinks_Success(t *testing.T) {
+	// Setup
+	tmpDir := t.TempDir()
+	opts := toml.Option{BaseDir: tmpDir}
+	cfg, err := toml.NewConfig(opts)
+	require.NoError(t, err)
+
+	configProvider := toml.NewProvider(cfg)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	repos := repositories.NewRepositories(configProvider, logger)
+	mockEditor := mock.NewMockEditor()
+	uc := NewMemo(configProvider, repos, mockEditor, logger)
+
+	now := time.Now()
+	target, err := domain.NewMemoFile(now, "Design Doc", []string{"work"})
+	require.NoError(t, err)
+	source, err := domain.NewMemoFile(now.Add(time.Second), "Weekly Sync", []string{"meetings"})
+	require.NoError(t, err)
+	source.SetHeadingBlocks([]*markdown.HeadingBlock{
+		{Level: 2, HeadingText: "Notes", ContentText: "discussed [[design doc|the doc]]\n"},
+	})
+	require.NoError(t, repos.Memo().Save(target, false))
+	require.NoError(t, repos.Memo().Save(source, false))
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	os.Stdout = w
+
+	// Execute
+	err = uc.Backlinks(filepath.Join("work", target.FileName()))
+
+	// Read captured output
+	w.Close()
+	out, _ := io.ReadAll(r)
+	os.Stdout = oldStdout
+	output := string(out)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, output, "Weekly-Sync")
+	assert.Contains(t, output, filepath.Join("meetings", source.FileName())+"#Notes")
+	assert.Contains(t, output, "[[design doc|the doc]]")
+}
+
+func TestBacklinks_NotFound(t *testing.T) {
+	// Setup
+	tmpDir := t.TempDir()
+	opts := toml.Option{BaseDir: tmpDir}
+	cfg, err := toml.NewConfig(opts)
+	require.NoError(t, err)
+
+	configProvider := toml.NewProvider(cfg)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	repos := repositories.NewRepositories(configProvider, logger)
+	mockEditor := mock.NewMockEditor()
+	uc := NewMemo(configProvider, repos, mockEditor, logger)
+
+	// Execute
+	err = uc.Backlinks("missing.md")
+
+	// Assert
+	assert.Error(t, err)
+}
@@ -0,0 +1,105 @@
+package memo
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hirotoni/memov2/internal/config/toml"
+	"github.com/hirotoni/memov2/internal/domain"
+	"github.com/hirotoni/memov2/internal/interfaces"
+	"github.com/hirotoni/memov2/internal/repositories"
+	"github.com/hirotoni/memov2/internal/repositories/mock"
+)
+
+// benchVaultSize is the number of memos in the vault the benchmarks run on
+const benchVaultSize = 10000
+
+// newBenchService writes a vault of benchVaultSize memos, a few a day across
+// nested categories, and returns a memo service over it. Compare worker counts
+// with -cpu, e.g. go test -bench . -cpu 1,4 ./internal/service/memo
+func newBenchService(b *testing.B) interfaces.MemoService {
+	b.Helper()
+
+	cfg, err := toml.NewConfig(toml.Option{BaseDir: b.TempDir()})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	start := time.Date(2020, 1, 1, 9, 0, 0, 0, time.UTC)
+	for i := range benchVaultSize {
+		date := start.Add(time.Duration(i) * 5 * time.Hour)
+		category := []string{fmt.Sprintf("area%d", i%7), fmt.Sprintf("topic%d", i%23)}
+		dir := filepath.Join(append([]string{cfg.MemosDir()}, category...)...)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			b.Fatal(err)
+		}
+
+		title := fmt.Sprintf("memo-%05d", i)
+		name := date.Format(domain.FileNameDateLayoutMemo) + "_memo_" + title + ".md"
+		content := fmt.Sprintf("---\ncategory: [%q, %q]\n---\n\n# %s\n\nSummary of %s.\n\n## Notes\n\n- first point\n- second point\n\n## Next steps\n\nFollow up next week.\n",
+			category[0], category[1], title, title)
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	provider := toml.NewProvider(cfg)
+	return NewMemo(provider, repositories.NewRepositories(provider, logger), mock.NewMockEditor(), logger)
+}
+
+// discardStdout sends what the commands print to /dev/null until the benchmark ends
+func discardStdout(b *testing.B) {
+	b.Helper()
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		b.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = devNull
+	b.Cleanup(func() {
+		os.Stdout = stdout
+		devNull.Close()
+	})
+}
+
+func BenchmarkList(b *testing.B) {
+	uc := newBenchService(b)
+	discardStdout(b)
+
+	b.ResetTimer()
+	for b.Loop() {
+		if err := uc.List(false, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBuildWeeklyReportMemos(b *testing.B) {
+	uc := newBenchService(b)
+	discardStdout(b)
+
+	b.ResetTimer()
+	for b.Loop() {
+		if err := uc.BuildWeeklyReportMemos(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGenerateMemoIndex(b *testing.B) {
+	uc := newBenchService(b)
+	discardStdout(b)
+
+	b.ResetTimer()
+	for b.Loop() {
+		if err := uc.GenerateMemoIndex(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
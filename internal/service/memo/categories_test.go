@@ -26,11 +26,11 @@ func TestListCategories_Success(t *testing.T) {
 	uc := NewMemo(configProvider, repos, mockEditor, logger)
 
 	// Create memos with categories to populate category list
-	err = uc.GenerateMemoFile("Memo A", []string{"work"})
+	err = uc.GenerateMemoFile("Memo A", []string{"work"}, nil)
 	require.NoError(t, err)
-	err = uc.GenerateMemoFile("Memo B", []string{"work", "projects"})
+	err = uc.GenerateMemoFile("Memo B", []string{"work", "projects"}, nil)
 	require.NoError(t, err)
-	err = uc.GenerateMemoFile("Memo C", []string{"personal"})
+	err = uc.GenerateMemoFile("Memo C", []string{"personal"}, nil)
 	require.NoError(t, err)
 
 	// Capture stdout
@@ -70,7 +70,7 @@ func TestListCategories_Empty(t *testing.T) {
 	uc := NewMemo(configProvider, repos, mockEditor, logger)
 
 	// Create a memo at root (no category) so memos dir exists
-	err = uc.GenerateMemoFile("Root Memo", []string{})
+	err = uc.GenerateMemoFile("Root Memo", []string{}, nil)
 	require.NoError(t, err)
 
 	// Capture stdout
@@ -0,0 +1,20 @@
+package memo
+
+import (
+	"github.com/hirotoni/memov2/internal/common"
+	"github.com/hirotoni/memov2/internal/search"
+)
+
+// AddDictionaryWord adds word with its reading to the personal SKK dictionary,
+// so that search converts the reading to word. Backs `memos search dict add`.
+func (uc memo) AddDictionaryWord(reading, word string) error {
+	path := uc.config.SearchUserDictionary()
+	if path == "" {
+		return common.New(common.ErrorTypeConfig, "search_user_dictionary is not set")
+	}
+	if err := search.AddDictionaryEntry(path, reading, word); err != nil {
+		return common.Wrap(err, common.ErrorTypeService, "error adding dictionary entry")
+	}
+	uc.logger.Info("Dictionary entry added", "reading", reading, "word", word, "path", path)
+	return nil
+}
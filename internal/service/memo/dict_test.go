@@ -0,0 +1,60 @@
+package memo
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hirotoni/memov2/internal/config/toml"
+	"github.com/hirotoni/memov2/internal/domain"
+	"github.com/hirotoni/memov2/internal/domain/markdown"
+	"github.com/hirotoni/memov2/internal/repositories"
+	"github.com/hirotoni/memov2/internal/repositories/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddDictionaryWord(t *testing.T) {
+	// Setup
+	tmpDir := t.TempDir()
+	dictPath := filepath.Join(tmpDir, "SKK-JISYO.user")
+	cfg, err := toml.NewConfig(toml.Option{BaseDir: tmpDir, SearchUserDictionary: dictPath})
+	require.NoError(t, err)
+
+	configProvider := toml.NewProvider(cfg)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	repos := repositories.NewRepositories(configProvider, logger)
+	uc := NewMemo(configProvider, repos, mock.NewMockEditor(), logger)
+
+	m, err := domain.NewMemoFile(time.Now(), "1on1", nil)
+	require.NoError(t, err)
+	m.SetHeadingBlocks([]*markdown.HeadingBlock{
+		{Level: 2, HeadingText: "Notes", ContentText: "山ノ内さんと話した\n"},
+	})
+	require.NoError(t, repos.Memo().Save(m, false))
+
+	search := func(query string) string {
+		t.Helper()
+		oldStdout := os.Stdout
+		r, w, err := os.Pipe()
+		require.NoError(t, err)
+		os.Stdout = w
+		err = uc.Search(query, "", "grep", "relevance")
+		w.Close()
+		out, _ := io.ReadAll(r)
+		os.Stdout = oldStdout
+		require.NoError(t, err)
+		return strings.TrimSpace(string(out))
+	}
+
+	// Execute & Assert
+	require.NoError(t, uc.AddDictionaryWord("yamanouchi", "山ノ内"))
+	assert.FileExists(t, dictPath)
+	assert.Contains(t, search("yamanouchi"), "山ノ内さんと話した")
+
+	assert.Error(t, uc.AddDictionaryWord("yamanouchi", "山 ノ内"))
+}
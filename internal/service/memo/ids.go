@@ -0,0 +1,33 @@
+package memo
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hirotoni/memov2/internal/common"
+	"github.com/hirotoni/memov2/internal/domain"
+)
+
+// BackfillIDs writes a stable ID into the frontmatter of every memo that has
+// none, printing each as "id\tpath". The IDs sort by the memos' dates. Backs
+// the `memos backfill-ids` command.
+func (uc memo) BackfillIDs() error {
+	headers, err := uc.repos.Memo().MemoHeaders()
+	if err != nil {
+		return err
+	}
+
+	for _, header := range headers {
+		if header.ID() != "" {
+			continue
+		}
+		// Only the id key is added: the rest of the file is left as written
+		id := domain.NewMemoID(header.Date())
+		if err := uc.repos.Memo().SetMetadata(header, domain.MetaKeyID, id); err != nil {
+			return common.Wrap(err, common.ErrorTypeService, fmt.Sprintf("error saving memo %s", domain.MemoPath(header)))
+		}
+		fmt.Fprintf(os.Stdout, "%s\t%s\n", id, domain.MemoPath(header))
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package memo
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hirotoni/memov2/internal/config/toml"
+	"github.com/hirotoni/memov2/internal/domain"
+	"github.com/hirotoni/memov2/internal/domain/markdown"
+	"github.com/hirotoni/memov2/internal/repositories"
+	"github.com/hirotoni/memov2/internal/repositories/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackfillIDs(t *testing.T) {
+	// Setup
+	tmpDir := t.TempDir()
+	opts := toml.Option{BaseDir: tmpDir}
+	cfg, err := toml.NewConfig(opts)
+	require.NoError(t, err)
+
+	configProvider := toml.NewProvider(cfg)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	repos := repositories.NewRepositories(configProvider, logger)
+	mockEditor := mock.NewMockEditor()
+	uc := NewMemo(configProvider, repos, mockEditor, logger)
+
+	// A new memo gets an ID, an older one has none
+	require.NoError(t, uc.GenerateMemoFile("Fresh", []string{"work"}, nil))
+	fresh, err := repos.Memo().MemoHeaders()
+	require.NoError(t, err)
+	require.Len(t, fresh, 1)
+	freshID := fresh[0].ID()
+	require.NotEmpty(t, freshID)
+
+	old, err := domain.NewMemoFile(time.Date(2024, 5, 1, 9, 0, 0, 0, time.Local), "Old", []string{"archive"})
+	require.NoError(t, err)
+	old.SetHeadingBlocks([]*markdown.HeadingBlock{{Level: 2, HeadingText: "Notes", ContentText: "kept\n"}})
+	require.NoError(t, repos.Memo().Save(old, false))
+
+	// Execute
+	output := captureStdout(t, uc.BackfillIDs)
+
+	// Assert - only the memo without an ID is given one, and its body is kept
+	backfilled, err := repos.Memo().Memo(old)
+	require.NoError(t, err)
+	oldID := backfilled.ID()
+	require.NotEmpty(t, oldID)
+	assert.Equal(t, oldID+"\t"+domain.MemoPath(old)+"\n", output)
+	assert.Less(t, oldID, freshID, "IDs sort by the memos' dates")
+	require.Len(t, backfilled.HeadingBlocks(), 1)
+	assert.Equal(t, "kept\n", backfilled.HeadingBlocks()[0].ContentText)
+
+	fresh, err = repos.Memo().MemoHeaders()
+	require.NoError(t, err)
+	for _, m := range fresh {
+		if m.Title() == "Fresh" {
+			assert.Equal(t, freshID, m.ID())
+		}
+	}
+
+	// Running it again changes nothing
+	assert.Empty(t, captureStdout(t, uc.BackfillIDs))
+
+	// The ID opens the memo, and keeps doing so after a rename
+	require.NoError(t, uc.Rename("id:"+oldID, "Renamed"))
+	require.NoError(t, uc.Open("id:"+oldID))
+	call := mockEditor.Calls[len(mockEditor.Calls)-1]
+	assert.Equal(t, filepath.Join(cfg.MemosDir(), "archive", "20240501Wed090000_memo_Renamed.md"), call.Path)
+
+	assert.Error(t, uc.Open("id:"+domain.NewMemoID(time.Now())))
+}
+
+func TestBackfillIDs_KeepsFileBytes(t *testing.T) {
+	// Setup
+	tmpDir := t.TempDir()
+	cfg, err := toml.NewConfig(toml.Option{BaseDir: tmpDir})
+	require.NoError(t, err)
+
+	configProvider := toml.NewProvider(cfg)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	repos := repositories.NewRepositories(configProvider, logger)
+	uc := NewMemo(configProvider, repos, mock.NewMockEditor(), logger)
+
+	body := "# meeting notes\n\nsome  text\t\n\n## Next steps\n- call Bob  \n"
+	withFrontmatter := filepath.Join(cfg.MemosDir(), "work", "20240501Wed090000_memo_meeting-notes.md")
+	require.NoError(t, os.MkdirAll(filepath.Dir(withFrontmatter), 0o755))
+	require.NoError(t, os.WriteFile(withFrontmatter, []byte("---\ncategory: [work]\n# reviewed\ntags: [team]\n---\n"+body), 0o644))
+	bare := filepath.Join(cfg.MemosDir(), "20240502Thu090000_memo_loose-ends.md")
+	require.NoError(t, os.WriteFile(bare, []byte(body), 0o644))
+
+	// Execute
+	output := captureStdout(t, uc.BackfillIDs)
+
+	// Assert - the id key is the only change to either file
+	lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+	require.Len(t, lines, 2)
+	ids := map[string]string{}
+	for _, line := range lines {
+		id, path, ok := strings.Cut(line, "\t")
+		require.True(t, ok)
+		ids[path] = id
+	}
+
+	got, err := os.ReadFile(withFrontmatter)
+	require.NoError(t, err)
+	assert.Equal(t, "---\ncategory: [work]\n# reviewed\ntags: [team]\nid: "+ids["work/20240501Wed090000_memo_meeting-notes.md"]+"\n---\n"+body, string(got))
+
+	got, err = os.ReadFile(bare)
+	require.NoError(t, err)
+	assert.Equal(t, "---\nid: "+ids["20240502Thu090000_memo_loose-ends.md"]+"\n---\n"+body, string(got))
+}
@@ -4,22 +4,29 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/hirotoni/memov2/internal/common"
 	"github.com/hirotoni/memov2/internal/config/toml"
+	memsearch "github.com/hirotoni/memov2/internal/search"
 	"github.com/hirotoni/memov2/internal/ui/tui/memos/picker"
 	"github.com/hirotoni/memov2/internal/ui/tui/memos/search"
 )
 
-// SearchInteractive launches the standalone search TUI (romaji-aware). Selecting
-// a result opens it in the configured editor. Backs the `memos search` command.
-func (uc memo) SearchInteractive() error {
+// SearchInteractive launches the standalone search TUI (romaji-aware), limited
+// to category if it is not "". Selecting a result opens it in the configured
+// editor at the matched line. Backs the `memos search` command.
+func (uc memo) SearchInteractive(category string) error {
 	cfg := uc.config.GetTomlConfig().(*toml.Config)
 	if err := cfg.EnsureDirectories(); err != nil {
 		return common.Wrap(err, common.ErrorTypeService, "failed to ensure directories")
 	}
+	scope, err := uc.searchScope(category)
+	if err != nil {
+		return err
+	}
 
 	m, err := search.NewStandalone(cfg, uc.editor)
 	if err != nil {
 		return common.Wrap(err, common.ErrorTypeService, "error creating search model")
 	}
+	m.SetScope(scope)
 	final, err := tea.NewProgram(m, tea.WithAltScreen()).Run()
 	if err != nil {
 		return common.Wrap(err, common.ErrorTypeService, "error running search TUI")
@@ -32,16 +39,18 @@ func (uc memo) SearchInteractive() error {
 	if !ok || sm.SelectedPath() == "" {
 		return nil // nothing selected / cancelled
 	}
-	if err := uc.editor.Open(cfg.BaseDir(), sm.SelectedPath()); err != nil {
+	if err := uc.editor.OpenAt(cfg.BaseDir(), sm.SelectedPath(), sm.SelectedPosition()); err != nil {
 		return common.Wrap(err, common.ErrorTypeService, "error opening editor")
 	}
-	return nil
+	return uc.syncTitleAfterEdit(sm.SelectedPath())
 }
 
 // RenameInteractive lets the user pick a memo and enter a new title in a TUI,
 // then renames it. Backs the `memos rename` command.
 func (uc memo) RenameInteractive() error {
-	relPath, newTitle, ok, err := picker.SelectMemoForRename(uc.repos.Memo())
+	// Transliteration is best-effort; on failure fall back to matching as typed.
+	tr, _ := memsearch.NewTransliterator(uc.config.SearchTransliterators(), uc.config.SearchDictionaries())
+	relPath, newTitle, ok, err := picker.SelectMemoForRename(uc.repos.Memo(), tr)
 	if err != nil {
 		return common.Wrap(err, common.ErrorTypeService, "error selecting memo to rename")
 	}
@@ -51,8 +60,8 @@ func (uc memo) RenameInteractive() error {
 	return uc.Rename(relPath, newTitle)
 }
 
-// NewInteractive lets the user pick a category and enter a title in a TUI, then
-// creates the memo. Backs the `memos new` command.
+// NewInteractive lets the user pick a category, enter a title and pick tags in
+// a TUI, then creates the memo. Backs the `memos new` command.
 func (uc memo) NewInteractive() error {
 	tree, title, ok, err := picker.SelectCategoryForNew(uc.repos.Memo())
 	if err != nil {
@@ -61,5 +70,12 @@ func (uc memo) NewInteractive() error {
 	if !ok {
 		return nil // cancelled
 	}
-	return uc.GenerateMemoFile(title, tree)
+	tags, ok, err := picker.SelectTagsForNew(uc.repos.Memo())
+	if err != nil {
+		return common.Wrap(err, common.ErrorTypeService, "error selecting tags")
+	}
+	if !ok {
+		return nil // cancelled
+	}
+	return uc.GenerateMemoFile(title, tree, tags)
 }
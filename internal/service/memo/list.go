@@ -4,16 +4,26 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
+	"github.com/hirotoni/memov2/internal/domain"
+	"github.com/hirotoni/memov2/internal/interfaces"
 	"github.com/mattn/go-runewidth"
 )
 
-func (uc memo) List(showFullPath bool) error {
-	entries, err := uc.repos.Memo().MemoEntries()
+// List prints every memo in "title\tpath" format. If tags are given, only
+// memos tagged with all of them are listed.
+func (uc memo) List(showFullPath bool, tags []string) error {
+	entries, err := uc.repos.Memo().MemoHeaders()
 	if err != nil {
 		return err
 	}
+	if tags = domain.NormalizeTags(tags); len(tags) > 0 {
+		entries = slices.DeleteFunc(entries, func(m interfaces.MemoFileInterface) bool {
+			return !hasTags(m, tags)
+		})
+	}
 
 	memosDir := uc.config.MemosDir()
 
@@ -45,3 +55,14 @@ func (uc memo) List(showFullPath bool) error {
 
 	return nil
 }
+
+// hasTags reports whether m is tagged with all of tags
+func hasTags(m interfaces.MemoFileInterface, tags []string) bool {
+	memoTags := m.Tags()
+	for _, tag := range tags {
+		if !slices.Contains(memoTags, tag) {
+			return false
+		}
+	}
+	return true
+}
@@ -9,7 +9,7 @@ import (
 	"github.com/hirotoni/memov2/internal/platform"
 )
 
-func (uc memo) GenerateMemoFile(title string, categoryTree []string) error {
+func (uc memo) GenerateMemoFile(title string, categoryTree []string, tags []string) error {
 	if title == "" {
 		var err error
 		title, err = platform.ReadLine("Title: ")
@@ -24,6 +24,8 @@ func (uc memo) GenerateMemoFile(title string, categoryTree []string) error {
 	if err != nil {
 		return err
 	}
+	memoFile.SetID(domain.NewMemoID(today))
+	memoFile.SetTags(tags)
 
 	// Save the memo file to the base directory
 	err = uc.repos.Memo().Save(memoFile, false)
@@ -38,5 +40,5 @@ func (uc memo) GenerateMemoFile(title string, categoryTree []string) error {
 		return common.Wrap(err, common.ErrorTypeService, "error opening editor")
 	}
 
-	return nil
+	return uc.syncTitleAfterEdit(fpath)
 }
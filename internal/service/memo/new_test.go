@@ -26,7 +26,7 @@ func TestGenerateMemoFile_Success(t *testing.T) {
 	uc := NewMemo(configProvider, repos, mockEditor, logger)
 
 	// Execute
-	err = uc.GenerateMemoFile("Test Memo Title", []string{})
+	err = uc.GenerateMemoFile("Test Memo Title", []string{}, nil)
 
 	// Assert
 	require.NoError(t, err)
@@ -53,7 +53,7 @@ func TestGenerateMemoFile_WithCategory(t *testing.T) {
 	uc := NewMemo(configProvider, repos, mockEditor, logger)
 
 	// Execute
-	err = uc.GenerateMemoFile("Categorized Memo", []string{"work", "projects"})
+	err = uc.GenerateMemoFile("Categorized Memo", []string{"work", "projects"}, nil)
 
 	// Assert
 	require.NoError(t, err)
@@ -91,7 +91,7 @@ func TestGenerateMemoFile_EditorError(t *testing.T) {
 	uc := NewMemo(configProvider, repos, mockEditor, logger)
 
 	// Execute
-	err = uc.GenerateMemoFile("Test Memo", []string{})
+	err = uc.GenerateMemoFile("Test Memo", []string{}, nil)
 
 	// Assert
 	require.Error(t, err)
@@ -1,6 +1,27 @@
 package memo
 
+import (
+	"path/filepath"
+
+	"github.com/hirotoni/memov2/internal/domain"
+)
+
+// Open opens the memo at path, or the memo with the ID of a path written as
+// id:<id>, in the editor. Once the editor closes, the memo's file name and H1
+// are brought in line as title_sync says.
 func (uc memo) Open(path string) error {
-	path = resolveToMemosDir(uc.config.MemosDir(), path)
-	return uc.editor.Open(uc.config.BaseDir(), path)
+	if _, ok := domain.ParseMemoIDRef(path); ok {
+		m, err := uc.memoByPath(path)
+		if err != nil {
+			return err
+		}
+		path = filepath.Join(uc.config.MemosDir(), domain.MemoPath(m))
+	} else {
+		path = resolveToMemosDir(uc.config.MemosDir(), path)
+	}
+
+	if err := uc.editor.Open(uc.config.BaseDir(), path); err != nil {
+		return err
+	}
+	return uc.syncTitleAfterEdit(path)
 }
@@ -1,8 +1,13 @@
 package memo
 
 import (
+	"fmt"
 	"path/filepath"
 	"strings"
+
+	"github.com/hirotoni/memov2/internal/common"
+	"github.com/hirotoni/memov2/internal/domain"
+	"github.com/hirotoni/memov2/internal/interfaces"
 )
 
 // resolveToMemosDir resolves a user-provided path to an absolute path under memosDir.
@@ -26,3 +31,51 @@ func resolveToMemosDir(memosDir, path string) string {
 
 	return filepath.Join(memosDir, path)
 }
+
+// memoByPath finds the memo at a user-provided path (see resolveToMemosDir),
+// or with the ID of a path written as id:<id>
+func (uc memo) memoByPath(path string) (interfaces.MemoFileInterface, error) {
+	if id, ok := domain.ParseMemoIDRef(path); ok {
+		return uc.repos.Memo().MemoByID(id)
+	}
+
+	memosDir := uc.config.MemosDir()
+
+	// Resolve path
+	path = resolveToMemosDir(memosDir, path)
+
+	// Extract filename and category from path
+	fileName := filepath.Base(path)
+
+	// Validate filename matches memo pattern
+	if domain.MemoTitle(fileName) == fileName {
+		return nil, common.New(common.ErrorTypeValidation, fmt.Sprintf("invalid memo file path: %s", path))
+	}
+
+	// Get category tree from the path relative to memosDir
+	dir := filepath.Dir(path)
+	relDir, err := filepath.Rel(memosDir, dir)
+	if err != nil {
+		return nil, common.Wrap(err, common.ErrorTypeFileSystem, "failed to compute relative path")
+	}
+
+	var categoryTree []string
+	if relDir != "." {
+		categoryTree = strings.Split(relDir, string(filepath.Separator))
+	}
+
+	// Find the memo by scanning entries
+	entries, err := uc.repos.Memo().MemoHeaders()
+	if err != nil {
+		return nil, err
+	}
+
+	location := filepath.Join(categoryTree...)
+	for _, m := range entries {
+		if m.FileName() == fileName && m.Location() == location {
+			return m, nil
+		}
+	}
+
+	return nil, common.New(common.ErrorTypeValidation, fmt.Sprintf("memo not found: %s", path))
+}
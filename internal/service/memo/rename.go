@@ -2,51 +2,48 @@ package memo
 
 import (
 	"fmt"
-	"path/filepath"
+	"os"
 	"strings"
 
-	"github.com/hirotoni/memov2/internal/common"
-	"github.com/hirotoni/memov2/internal/domain"
+	"github.com/hirotoni/memov2/internal/interfaces"
+	"github.com/hirotoni/memov2/internal/platform"
+	"github.com/hirotoni/memov2/internal/utils"
 )
 
+// Rename renames the memo at path. If other files link to it, the link rewrites
+// are shown as a diff and applied together with the rename once confirmed.
 func (uc memo) Rename(path string, newTitle string) error {
-	memosDir := uc.config.MemosDir()
-
-	// Resolve path
-	path = resolveToMemosDir(memosDir, path)
-
-	// Extract filename and category from path
-	fileName := filepath.Base(path)
-
-	// Validate filename matches memo pattern
-	if domain.MemoTitle(fileName) == fileName {
-		return common.New(common.ErrorTypeValidation, fmt.Sprintf("invalid memo file path: %s", path))
-	}
-
-	// Get category tree from the path relative to memosDir
-	dir := filepath.Dir(path)
-	relDir, err := filepath.Rel(memosDir, dir)
+	m, err := uc.memoByPath(path)
 	if err != nil {
-		return common.Wrap(err, common.ErrorTypeFileSystem, "failed to compute relative path")
-	}
-
-	var categoryTree []string
-	if relDir != "." {
-		categoryTree = strings.Split(relDir, string(filepath.Separator))
+		return err
 	}
 
-	// Find the memo by scanning entries
-	entries, err := uc.repos.Memo().MemoEntries()
+	plan, err := uc.repos.Memo().PlanRename(m, newTitle)
 	if err != nil {
 		return err
 	}
+	rewrites := plan.LinkRewrites()
 
-	location := filepath.Join(categoryTree...)
-	for _, m := range entries {
-		if m.FileName() == fileName && m.Location() == location {
-			return uc.repos.Memo().Rename(m, newTitle)
+	if len(rewrites) > 0 {
+		fmt.Fprint(os.Stdout, linkRewritesDiff(rewrites))
+		answer, err := platform.ReadLine(fmt.Sprintf("Rename and rewrite links in %d file(s)? [y/N]: ", len(rewrites)))
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(strings.TrimSpace(answer), "y") {
+			fmt.Fprintln(os.Stdout, "Rename canceled")
+			return nil
 		}
 	}
 
-	return common.New(common.ErrorTypeValidation, fmt.Sprintf("memo not found: %s", path))
+	return uc.repos.Memo().ApplyRelocation(plan)
+}
+
+// linkRewritesDiff returns the unified diffs of rewrites
+func linkRewritesDiff(rewrites []interfaces.LinkRewrite) string {
+	var sb strings.Builder
+	for _, rw := range rewrites {
+		sb.WriteString(utils.UnifiedDiff(rw.Path, rw.Before, rw.Path, rw.After))
+	}
+	return sb.String()
 }
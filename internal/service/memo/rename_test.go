@@ -0,0 +1,23 @@
+package memo
+
+import (
+	"testing"
+
+	"github.com/hirotoni/memov2/internal/interfaces"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinkRewritesDiff(t *testing.T) {
+	rewrites := []interfaces.LinkRewrite{
+		{Path: "index.md", Before: "- [a](old.md)\n- [b](b.md)\n", After: "- [a](new.md)\n- [b](b.md)\n"},
+		{Path: "notes/x.md", Before: "[[Old]]\n", After: "[[New]]\n"},
+	}
+
+	diff := linkRewritesDiff(rewrites)
+
+	assert.Contains(t, diff, "--- index.md\n+++ index.md\n")
+	assert.Contains(t, diff, "-- [a](old.md)\n+- [a](new.md)\n")
+	assert.Contains(t, diff, "--- notes/x.md\n")
+	assert.Contains(t, diff, "-[[Old]]\n+[[New]]\n")
+	assert.Empty(t, linkRewritesDiff(nil))
+}
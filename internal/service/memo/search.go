@@ -0,0 +1,201 @@
+package memo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hirotoni/memov2/internal/common"
+	"github.com/hirotoni/memov2/internal/domain"
+	memoRepo "github.com/hirotoni/memov2/internal/repositories/memo"
+	"github.com/hirotoni/memov2/internal/search"
+)
+
+// Output formats of Search
+const (
+	searchFormatJSON = "json"
+	searchFormatTSV  = "tsv"
+	searchFormatGrep = "grep"
+)
+
+// searchRecord is a single match as printed by Search
+type searchRecord struct {
+	Path        string   `json:"path"`
+	Title       string   `json:"title"`
+	Date        string   `json:"date"`
+	Category    []string `json:"category"`
+	Score       float64  `json:"score"`
+	Type        string   `json:"type"`
+	Heading     string   `json:"heading"`
+	Line        int      `json:"line"`
+	Content     string   `json:"content"`
+	PrevContext string   `json:"prev_context"`
+	NextContext string   `json:"next_context"`
+	Done        *bool    `json:"done,omitempty"` // whether a task is checked off; unset for memos
+}
+
+// Search runs the romaji-aware search of memos and todo tasks for query without
+// the TUI and prints one record per match in format: json, tsv or grep
+// (path:line:text). Results are ordered by relevance or date. A category such
+// as work/projects limits the search to the memos in it and its subcategories.
+func (uc memo) Search(query, category, format, order string) error {
+	if format != searchFormatJSON && format != searchFormatTSV && format != searchFormatGrep {
+		return common.New(common.ErrorTypeValidation, fmt.Sprintf("unknown output format: %s (want json, tsv or grep)", format))
+	}
+	o, err := search.ParseOrder(order)
+	if err != nil {
+		return common.Wrap(err, common.ErrorTypeValidation, "invalid order")
+	}
+
+	conv, err := search.NewTransliterator(uc.config.SearchTransliterators(), uc.config.SearchDictionaries())
+	if err != nil {
+		return common.Wrap(err, common.ErrorTypeService, "failed to load transliterators")
+	}
+	q, err := search.ParseQuery(query, conv)
+	if err != nil {
+		return common.Wrap(err, common.ErrorTypeValidation, "invalid query")
+	}
+	scope, err := uc.searchScope(category)
+	if err != nil {
+		return err
+	}
+	q = q.Within(scope)
+
+	index := search.NewIndex(search.IndexPath(uc.config.BaseDir()), uc.config.MemosDir(), func(path string, info os.FileInfo) (domain.MemoFileInterface, error) {
+		return memoRepo.ParseMemoFile(path, info, uc.logger)
+	}).WithTodos(uc.config.TodosDir())
+	results, err := index.Search(q, o)
+	if err != nil {
+		return common.Wrap(err, common.ErrorTypeService, "error refreshing search index")
+	}
+	return writeSearchResults(os.Stdout, uc.config.MemosDir(), uc.config.TodosDir(), results, format)
+}
+
+// SearchSaved runs the saved search named name as Search does
+func (uc memo) SearchSaved(name, category, format, order string) error {
+	saved, ok := uc.config.SavedSearch(name)
+	if !ok {
+		var names []string
+		for _, s := range uc.config.SavedSearches() {
+			names = append(names, s.Name)
+		}
+		return common.New(common.ErrorTypeValidation, fmt.Sprintf("unknown saved search: %s (saved: %s)", name, strings.Join(names, ", ")))
+	}
+	return uc.Search(saved.Query, category, format, order)
+}
+
+// searchScope returns the category tree of a category path such as
+// work/projects, nil for "". The category must exist.
+func (uc memo) searchScope(category string) ([]string, error) {
+	scope := search.ParseCategory(category)
+	if len(scope) == 0 {
+		return nil, nil
+	}
+	info, err := os.Stat(filepath.Join(append([]string{uc.config.MemosDir()}, scope...)...))
+	if err != nil || !info.IsDir() {
+		return nil, common.New(common.ErrorTypeValidation, fmt.Sprintf("unknown category: %s", category))
+	}
+	return scope, nil
+}
+
+// taskRecords returns the records of a task result: one per match, each with
+// the task's text as content
+func taskRecords(todosDir string, result search.SearchResult) []searchRecord {
+	task := result.Task
+	record := searchRecord{
+		Path:     filepath.Join(todosDir, task.Path),
+		Title:    task.Text,
+		Date:     task.Date.Format(time.RFC3339),
+		Category: []string{},
+		Score:    result.Score,
+		Heading:  task.Section,
+		Content:  task.Text,
+		Done:     &task.Done,
+	}
+
+	var records []searchRecord
+	for _, match := range result.Matches {
+		r := record
+		r.Type = match.Type.String()
+		r.Line, _ = result.Position(match, nil)
+		records = append(records, r)
+	}
+	if len(records) == 0 {
+		// Matched by dates or is: only
+		record.Type, record.Line = search.MatchTask.String(), task.Line+1
+		records = append(records, record)
+	}
+	return records
+}
+
+// tsvEscaper escapes backslashes, tabs and line breaks in TSV fields, so that
+// every record is a single line of tab separated fields
+var tsvEscaper = strings.NewReplacer("\\", "\\\\", "\t", "\\t", "\n", "\\n", "\r", "\\r")
+
+// grepLineBreaks keeps a match on the one line grep output gives it
+var grepLineBreaks = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ")
+
+func writeSearchResults(w io.Writer, memosDir, todosDir string, results []search.SearchResult, format string) error {
+	records := []searchRecord{}
+	for _, result := range results {
+		if result.Task != nil {
+			records = append(records, taskRecords(todosDir, result)...)
+			continue
+		}
+
+		m := result.Memo
+		memoRecord := searchRecord{
+			Path:     filepath.Join(memosDir, m.Location(), m.FileName()),
+			Title:    m.Title(),
+			Date:     m.Date().Format(time.RFC3339),
+			Category: m.CategoryTree(),
+			Score:    result.Score,
+		}
+		if memoRecord.Category == nil {
+			memoRecord.Category = []string{}
+		}
+
+		if len(result.Matches) == 0 {
+			// Matched by dates or negated terms only
+			r := memoRecord
+			r.Type, r.Line, r.Content = "memo", 1, m.Title()
+			records = append(records, r)
+		}
+		for _, match := range result.Matches {
+			r := memoRecord
+			r.Type = match.Type.String()
+			r.Heading = match.Heading
+			r.Line = search.FileLine(m, match)
+			r.Content = match.Content
+			r.PrevContext = match.PrevLineContext
+			r.NextContext = match.NextLineContext
+			records = append(records, r)
+		}
+	}
+
+	switch format {
+	case searchFormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(records); err != nil {
+			return common.Wrap(err, common.ErrorTypeService, "error encoding search results")
+		}
+	case searchFormatTSV:
+		for _, r := range records {
+			fields := []string{r.Path, fmt.Sprint(r.Line), r.Type, r.Heading, r.Content, r.PrevContext, r.NextContext}
+			for i, f := range fields {
+				fields[i] = tsvEscaper.Replace(f)
+			}
+			fmt.Fprintln(w, strings.Join(fields, "\t"))
+		}
+	case searchFormatGrep:
+		for _, r := range records {
+			fmt.Fprintf(w, "%s:%d:%s\n", r.Path, r.Line, grepLineBreaks.Replace(r.Content))
+		}
+	}
+	return nil
+}
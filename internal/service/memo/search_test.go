@@ -0,0 +1,223 @@
+package memo
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hirotoni/memov2/internal/config"
+	"github.com/hirotoni/memov2/internal/config/toml"
+	"github.com/hirotoni/memov2/internal/domain"
+	"github.com/hirotoni/memov2/internal/domain/markdown"
+	"github.com/hirotoni/memov2/internal/repositories"
+	"github.com/hirotoni/memov2/internal/repositories/mock"
+	"github.com/hirotoni/memov2/internal/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteSearchResults(t *testing.T) {
+	date := time.Date(2025, 3, 4, 5, 6, 7, 0, time.UTC)
+	m, err := domain.NewMemoFile(date, "Deploy", []string{"ops"})
+	require.NoError(t, err)
+	m.SetHeadingBlocks([]*markdown.HeadingBlock{
+		{Level: 2, HeadingText: "Steps", ContentText: "build\nrun\tdeploy\ncheck\n", LineNumber: 5, ContentLineNumber: 7},
+	})
+	results := []search.SearchResult{{
+		Memo: m,
+		Matches: []search.Match{{
+			Type:            search.MatchContent,
+			HeadingOrder:    0,
+			Line:            2,
+			Content:         "run\tdeploy",
+			PrevLineContext: "build",
+			NextLineContext: "check",
+			Heading:         "Steps",
+		}},
+	}}
+	path := filepath.Join("/memos", "ops", m.FileName())
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"grep", path + ":8:run\tdeploy\n"},
+		{"tsv", path + "\t8\tcontent\tSteps\trun\\tdeploy\tbuild\tcheck\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, writeSearchResults(&buf, "/memos", "/todos", results, tt.format))
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+
+	t.Run("escaping", func(t *testing.T) {
+		escaped := []search.SearchResult{{
+			Memo:    m,
+			Matches: []search.Match{{Type: search.MatchContent, Line: 2, Content: "a\tb\\c\nd", Heading: "Steps"}},
+		}}
+
+		var buf bytes.Buffer
+		require.NoError(t, writeSearchResults(&buf, "/memos", "/todos", escaped, "tsv"))
+		assert.Equal(t, path+"\t8\tcontent\tSteps\ta\\tb\\\\c\\nd\t\t\n", buf.String())
+		assert.Len(t, strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\t"), 7)
+
+		buf.Reset()
+		require.NoError(t, writeSearchResults(&buf, "/memos", "/todos", escaped, "grep"))
+		assert.Equal(t, path+":8:a\tb\\c d\n", buf.String())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeSearchResults(&buf, "/memos", "/todos", results, "json"))
+
+		var records []searchRecord
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &records))
+		assert.Equal(t, []searchRecord{{
+			Path:        path,
+			Title:       "Deploy",
+			Date:        "2025-03-04T05:06:07Z",
+			Category:    []string{"ops"},
+			Type:        "content",
+			Heading:     "Steps",
+			Line:        8,
+			Content:     "run\tdeploy",
+			PrevContext: "build",
+			NextContext: "check",
+		}}, records)
+	})
+
+	t.Run("task", func(t *testing.T) {
+		task := &search.Task{
+			Task: markdown.Task{Text: "run deploy", Done: true, Section: "todos", Line: 4, Column: 6},
+			Path: "20250304Tue_todos.md",
+			Date: time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC),
+		}
+		taskResults := []search.SearchResult{{
+			Task:    task,
+			Matches: []search.Match{{Type: search.MatchTask, Line: 5, Content: "run deploy", Heading: "todos"}},
+		}}
+		taskPath := filepath.Join("/todos", "20250304Tue_todos.md")
+
+		var buf bytes.Buffer
+		require.NoError(t, writeSearchResults(&buf, "/memos", "/todos", taskResults, "grep"))
+		assert.Equal(t, taskPath+":5:run deploy\n", buf.String())
+
+		buf.Reset()
+		require.NoError(t, writeSearchResults(&buf, "/memos", "/todos", taskResults, "json"))
+		var records []searchRecord
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &records))
+		done := true
+		assert.Equal(t, []searchRecord{{
+			Path:     taskPath,
+			Title:    "run deploy",
+			Date:     "2025-03-04T00:00:00Z",
+			Category: []string{},
+			Type:     "task",
+			Heading:  "todos",
+			Line:     5,
+			Content:  "run deploy",
+			Done:     &done,
+		}}, records)
+	})
+
+	t.Run("json without results", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeSearchResults(&buf, "/memos", "/todos", nil, "json"))
+		assert.Equal(t, "[]\n", buf.String())
+	})
+}
+
+func TestSearch(t *testing.T) {
+	// Setup
+	tmpDir := t.TempDir()
+	cfg, err := toml.NewConfig(toml.Option{
+		BaseDir:       tmpDir,
+		SavedSearches: []config.SavedSearch{{Name: "open deploys", Query: "is:open deploy"}},
+	})
+	require.NoError(t, err)
+
+	configProvider := toml.NewProvider(cfg)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	repos := repositories.NewRepositories(configProvider, logger)
+	uc := NewMemo(configProvider, repos, mock.NewMockEditor(), logger)
+
+	m, err := domain.NewMemoFile(time.Now(), "Release", []string{"ops"})
+	require.NoError(t, err)
+	m.SetHeadingBlocks([]*markdown.HeadingBlock{
+		{Level: 2, HeadingText: "Steps", ContentText: "tag the release\nrun the deploy script\n"},
+	})
+	require.NoError(t, repos.Memo().Save(m, false))
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	os.Stdout = w
+
+	// Execute
+	err = uc.Search("deploy script", "", "grep", "relevance")
+
+	w.Close()
+	out, _ := io.ReadAll(r)
+	os.Stdout = oldStdout
+
+	// Assert
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	require.Len(t, lines, 1)
+	assert.Equal(t, filepath.Join(cfg.MemosDir(), "ops", m.FileName())+":10:run the deploy script", lines[0])
+
+	// The file line points at the matched text
+	b, err := os.ReadFile(filepath.Join(cfg.MemosDir(), "ops", m.FileName()))
+	require.NoError(t, err)
+	assert.Equal(t, "run the deploy script", strings.Split(string(b), "\n")[9])
+
+	// Tasks of the todo files are searched too
+	todoName := time.Now().Format(domain.FileNameDateLayoutTodo) + "_todos.md"
+	require.NoError(t, os.MkdirAll(cfg.TodosDir(), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(cfg.TodosDir(), todoName), []byte("## todos\n\n- [ ] fix the deploy script\n"), 0o644))
+	r, w, err = os.Pipe()
+	require.NoError(t, err)
+	os.Stdout = w
+	err = uc.Search("is:task deploy", "", "grep", "relevance")
+	w.Close()
+	out, _ = io.ReadAll(r)
+	os.Stdout = oldStdout
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(cfg.TodosDir(), todoName)+":3:fix the deploy script\n", string(out))
+
+	// Saved searches run by name
+	r, w, err = os.Pipe()
+	require.NoError(t, err)
+	os.Stdout = w
+	err = uc.SearchSaved("open deploys", "", "grep", "relevance")
+	w.Close()
+	out, _ = io.ReadAll(r)
+	os.Stdout = oldStdout
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(cfg.TodosDir(), todoName)+":3:fix the deploy script\n", string(out))
+	assert.Error(t, uc.SearchSaved("closed deploys", "", "grep", "relevance"))
+
+	// A category leaves out the memos outside it and the tasks
+	r, w, err = os.Pipe()
+	require.NoError(t, err)
+	os.Stdout = w
+	err = uc.Search("deploy", "ops", "grep", "relevance")
+	w.Close()
+	out, _ = io.ReadAll(r)
+	os.Stdout = oldStdout
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(cfg.MemosDir(), "ops", m.FileName())+":10:run the deploy script\n", string(out))
+	assert.Error(t, uc.Search("deploy", "dev", "grep", "relevance"))
+
+	assert.Error(t, uc.Search("deploy", "", "xml", "relevance"))
+	assert.Error(t, uc.Search("deploy", "", "grep", "size"))
+}
@@ -0,0 +1,21 @@
+package memo
+
+import (
+	"fmt"
+	"os"
+)
+
+// ListTags prints every tag with the number of memos tagged with it, the most
+// used first. Backs the `memos tags` command.
+func (uc memo) ListTags() error {
+	tags, err := uc.repos.Memo().Tags()
+	if err != nil {
+		return err
+	}
+
+	for _, tag := range tags {
+		fmt.Fprintf(os.Stdout, "%s\t%d\n", tag.Tag, tag.Count)
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package memo
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/hirotoni/memov2/internal/config/toml"
+	"github.com/hirotoni/memov2/internal/repositories"
+	"github.com/hirotoni/memov2/internal/repositories/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureStdout returns what fn writes to stdout
+func captureStdout(t *testing.T, fn func() error) string {
+	t.Helper()
+
+	oldStdout := os.Stdout
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	os.Stdout = w
+
+	err = fn()
+	w.Close()
+	os.Stdout = oldStdout
+	require.NoError(t, err)
+
+	out, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(out)
+}
+
+func TestListTags(t *testing.T) {
+	// Setup
+	tmpDir := t.TempDir()
+	opts := toml.Option{BaseDir: tmpDir}
+	cfg, err := toml.NewConfig(opts)
+	require.NoError(t, err)
+
+	configProvider := toml.NewProvider(cfg)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	repos := repositories.NewRepositories(configProvider, logger)
+	mockEditor := mock.NewMockEditor()
+	uc := NewMemo(configProvider, repos, mockEditor, logger)
+
+	require.NoError(t, uc.GenerateMemoFile("Kickoff", []string{"work"}, []string{"client", "meeting"}))
+	require.NoError(t, uc.GenerateMemoFile("Sync", []string{"work"}, []string{"#meeting"}))
+	require.NoError(t, uc.GenerateMemoFile("Groceries", []string{"personal"}, nil))
+
+	// Execute & Assert
+	output := captureStdout(t, uc.ListTags)
+	assert.Equal(t, "meeting\t2\nclient\t1\n", output)
+
+	output = captureStdout(t, func() error { return uc.List(false, []string{"meeting"}) })
+	assert.Contains(t, output, "Kickoff")
+	assert.Contains(t, output, "Sync")
+	assert.NotContains(t, output, "Groceries")
+
+	// All the tags must match
+	output = captureStdout(t, func() error { return uc.List(false, []string{"meeting", "#client"}) })
+	assert.Contains(t, output, "Kickoff")
+	assert.NotContains(t, output, "Sync")
+}
@@ -5,132 +5,272 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
-	"strings"
-	"time"
 
 	"github.com/hirotoni/memov2/internal/common"
+	"github.com/hirotoni/memov2/internal/config"
 	"github.com/hirotoni/memov2/internal/domain"
 	"github.com/hirotoni/memov2/internal/platform"
-	repoCommon "github.com/hirotoni/memov2/internal/repositories/common"
+	memoRepo "github.com/hirotoni/memov2/internal/repositories/memo"
 )
 
+// memoFileNameRegex matches the file names of memos; other files are left alone
+var memoFileNameRegex = regexp.MustCompile(domain.FileNameRegexMemo)
+
+// tidyMove is a memo file to move into the directory of its category. Paths are
+// relative to the memos directory.
+type tidyMove struct {
+	source string
+	target string
+}
+
+// tidyConflict is a move tidy leaves out so as not to overwrite a file
+type tidyConflict struct {
+	tidyMove
+	other string // source of the planned move to the same target, empty if the target exists
+}
+
+// reason says why the memo is not moved
+func (c tidyConflict) reason() string {
+	if c.other == "" {
+		return "target exists"
+	}
+	return "collides with the move of " + c.other
+}
+
+// tidySkip is a memo file tidy cannot place
+type tidySkip struct {
+	path string
+	err  error
+}
+
+// tidyPlan is what tidying the memos directory would do
+type tidyPlan struct {
+	moves     []tidyMove
+	conflicts []tidyConflict
+	skipped   []tidySkip
+}
+
+// problems returns the number of files the plan leaves out of place
+func (p *tidyPlan) problems() int {
+	return len(p.conflicts) + len(p.skipped)
+}
+
 // TidyMemos organizes memo files by moving them to correct locations based on metadata
-// and removes empty directories
+// and removes empty directories. Memos it cannot move are logged and left in place;
+// `memos tidy` reports them. With title_sync "auto", file names and H1s are then
+// brought in line; otherwise that is left to `memos tidy`.
 func (uc memo) TidyMemos() error {
-	err := uc.moveFilesToCorrectLocation()
+	plan, err := uc.planTidy()
 	if err != nil {
+		return common.Wrap(err, common.ErrorTypeService, "error planning tidy")
+	}
+	for _, c := range plan.conflicts {
+		uc.logger.Warn("Not moving file", "source", c.source, "target", c.target, "reason", c.reason())
+	}
+	for _, s := range plan.skipped {
+		uc.logger.Warn("Skipping unparseable memo file", "path", s.path, "error", s.err)
+	}
+
+	if err := uc.applyTidy(plan); err != nil {
 		return common.Wrap(err, common.ErrorTypeService, "error moving files to correct location")
 	}
-	err = uc.removeEmptyDirectories()
-	if err != nil {
+	if uc.config.TitleSync() == config.TitleSyncAuto {
+		if _, err := uc.syncTitles(false, false); err != nil {
+			return common.Wrap(err, common.ErrorTypeService, "error syncing titles")
+		}
+	}
+	if _, err := uc.removeEmptyDirectories(); err != nil {
 		return common.Wrap(err, common.ErrorTypeService, "error removing empty directories")
 	}
 	return nil
 }
 
-// moveFilesToCorrectLocation traverses directory and moves files to correct location
-// based on metadata category
-func (uc memo) moveFilesToCorrectLocation() error {
+// Tidy moves every memo into the directory of its category, brings file names
+// and H1s in line as title_source and title_sync say, and removes empty
+// directories, printing each change. With dryRun it only prints the moves and
+// renames it would make. Memos that cannot be moved without overwriting another
+// file, and files that cannot be parsed, are left in place and reported; the
+// returned error counts them. So are renames title_sync "prompt" cannot ask
+// about, there being no terminal. Backs the `memos tidy` command.
+func (uc memo) Tidy(dryRun bool) error {
+	plan, err := uc.planTidy()
+	if err != nil {
+		return common.Wrap(err, common.ErrorTypeService, "error planning tidy")
+	}
+
+	for _, m := range plan.moves {
+		fmt.Fprintf(os.Stdout, "move\t%s\t%s\n", m.source, m.target)
+	}
+	for _, c := range plan.conflicts {
+		fmt.Fprintf(os.Stdout, "conflict\t%s\t%s\t%s\n", c.source, c.target, c.reason())
+	}
+	for _, s := range plan.skipped {
+		fmt.Fprintf(os.Stdout, "skip\t%s\t%v\n", s.path, s.err)
+	}
+
+	if !dryRun {
+		if err := uc.applyTidy(plan); err != nil {
+			return common.Wrap(err, common.ErrorTypeService, "error moving files to correct location")
+		}
+	}
+
+	unsynced := 0 // titles not synced for want of a terminal to confirm them on
+	if mode := uc.config.TitleSync(); mode != config.TitleSyncOff {
+		ask := mode == config.TitleSyncPrompt
+		// Without a terminal the renames are only printed, as with dryRun
+		unattended := ask && !dryRun && !platform.IsInteractive()
+		changes, err := uc.syncTitles(ask, dryRun || unattended)
+		for _, c := range changes {
+			fmt.Fprintf(os.Stdout, "title\t%s\t%s\n", c.path, c.title)
+		}
+		if err != nil {
+			return common.Wrap(err, common.ErrorTypeService, "error syncing titles")
+		}
+		if unattended {
+			unsynced = len(changes)
+		}
+	}
+
+	if !dryRun {
+		removed, err := uc.removeEmptyDirectories()
+		if err != nil {
+			return common.Wrap(err, common.ErrorTypeService, "error removing empty directories")
+		}
+		for _, dir := range removed {
+			fmt.Fprintf(os.Stdout, "remove\t%s\n", dir)
+		}
+	}
+
+	if n := plan.problems() + unsynced; n > 0 {
+		msg := fmt.Sprintf("%d memo files left in place: %d conflicts, %d unparseable", n, len(plan.conflicts), len(plan.skipped))
+		if unsynced > 0 {
+			msg += fmt.Sprintf(", %d titles to confirm on a terminal", unsynced)
+		}
+		return common.New(common.ErrorTypeValidation, msg)
+	}
+	return nil
+}
+
+// planTidy finds the memos outside the directory of their category. A memo
+// without a category key stays where it is.
+func (uc memo) planTidy() (*tidyPlan, error) {
+	plan := &tidyPlan{}
 	memosDir := uc.config.MemosDir()
 	// Check if directory exists before walking
 	if _, err := os.Stat(memosDir); os.IsNotExist(err) {
 		// Directory doesn't exist, nothing to tidy
-		return nil
+		return plan, nil
 	}
 
-	return filepath.Walk(memosDir, func(sourcePath string, info os.FileInfo, err error) error {
+	targets := make(map[string]string) // targets of the moves planned so far, to their sources
+	err := filepath.Walk(memosDir, func(sourcePath string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
-
 		if !uc.shouldProcess(info) {
 			return nil
 		}
 
-		return uc.moveFilesIfNeeded(sourcePath, info)
+		source, err := filepath.Rel(memosDir, sourcePath)
+		if err != nil {
+			return err
+		}
+		header, err := memoRepo.ParseMemoHeader(sourcePath, info, uc.logger)
+		if err != nil {
+			plan.skipped = append(plan.skipped, tidySkip{path: source, err: err})
+			return nil
+		}
+
+		category := header.CategoryTree()
+		if category == nil {
+			return nil
+		}
+		target := filepath.Join(filepath.Join(category...), info.Name())
+		if !filepath.IsLocal(target) {
+			plan.skipped = append(plan.skipped, tidySkip{path: source, err: fmt.Errorf("category %q is outside the memos directory", category)})
+			return nil
+		}
+		if target == source {
+			return nil
+		}
+		move := tidyMove{source: source, target: target}
+		if other, ok := targets[target]; ok {
+			plan.conflicts = append(plan.conflicts, tidyConflict{tidyMove: move, other: other})
+			return nil
+		}
+		if platform.Exists(filepath.Join(memosDir, target)) {
+			plan.conflicts = append(plan.conflicts, tidyConflict{tidyMove: move})
+			return nil
+		}
+		targets[target] = source
+		plan.moves = append(plan.moves, move)
+		return nil
 	})
+	if err != nil {
+		return nil, common.Wrap(err, common.ErrorTypeFileSystem, "error walking through memos")
+	}
+	return plan, nil
 }
 
 func (uc memo) shouldProcess(info os.FileInfo) bool {
 	if info.IsDir() {
 		return false
 	}
-	if !strings.HasSuffix(info.Name(), domain.FileExtension) {
-		return false
-	}
-
-	// skip special files
-	specialFiles := map[string]bool{
-		"weekly_report.md": true,
-		"index.md":         true,
-	}
-
-	return !specialFiles[info.Name()]
+	// skip special files such as index.md and weekly_report.md
+	return memoFileNameRegex.MatchString(info.Name())
 }
 
-func (uc memo) moveFilesIfNeeded(sourcePath string, info os.FileInfo) error {
-	// Parse date and title from filename to create a minimal memo file interface
-	date, err := parseDateFromMemoFilename(info.Name())
-	if err != nil {
-		// Skip files we can't parse
-		return nil
-	}
-	title := extractTitleFromMemoFilename(info.Name())
-	tempMemo, err := domain.NewMemoFile(date, title, []string{})
-	if err != nil {
-		return nil
-	}
-
-	// Load the actual memo to get its category
-	loadedMemo, err := uc.repos.Memo().Memo(tempMemo)
-	if err != nil {
-		// Skip files we can't load
-		return nil
-	}
-
-	category := loadedMemo.CategoryTree()
-	targetPath := filepath.Join(uc.config.MemosDir(), filepath.Join(category...), info.Name())
+// applyTidy makes the moves of plan. A target created since the plan was made
+// is not overwritten.
+func (uc memo) applyTidy(plan *tidyPlan) error {
+	memosDir := uc.config.MemosDir()
+	for _, m := range plan.moves {
+		sourcePath := filepath.Join(memosDir, m.source)
+		targetPath := filepath.Join(memosDir, m.target)
 
-	if sourcePath != targetPath {
 		if err := platform.EnsureDir(filepath.Dir(targetPath)); err != nil {
 			return common.Wrap(err, common.ErrorTypeFileSystem, fmt.Sprintf("failed to create directory: %s", targetPath))
 		}
+		if platform.Exists(targetPath) {
+			return common.New(common.ErrorTypeFileSystem, fmt.Sprintf("failed to move file from %s to %s: target exists", sourcePath, targetPath))
+		}
 		uc.logger.Info("Moving file", "source", sourcePath, "target", targetPath)
-		err := os.Rename(sourcePath, targetPath)
-		if err != nil {
+		if err := os.Rename(sourcePath, targetPath); err != nil {
 			return common.Wrap(err, common.ErrorTypeFileSystem, fmt.Sprintf("failed to move file from %s to %s", sourcePath, targetPath))
 		}
 		uc.logger.Info("Moved file", "source", sourcePath, "target", targetPath)
 	}
-
 	return nil
 }
 
-// removeEmptyDirectories removes empty directories in multiple passes
-func (uc memo) removeEmptyDirectories() error {
+// removeEmptyDirectories removes empty directories in multiple passes and
+// returns them, relative to the memos directory
+func (uc memo) removeEmptyDirectories() ([]string, error) {
 	var limit = 10 // limit to prevent infinite loop
 
+	var removed []string
 	for range limit {
-		c, err := uc.removeEmptyDirectoriesInOnePass()
+		dirs, err := uc.removeEmptyDirectoriesInOnePass()
 		if err != nil {
-			return common.Wrap(err, common.ErrorTypeService, "error removing empty directories")
-		} else if c == 0 {
+			return removed, common.Wrap(err, common.ErrorTypeService, "error removing empty directories")
+		} else if len(dirs) == 0 {
 			break // No more empty directories to remove
 		}
+		removed = append(removed, dirs...)
 	}
 
-	return nil
+	return removed, nil
 }
 
-func (uc memo) removeEmptyDirectoriesInOnePass() (int, error) {
+func (uc memo) removeEmptyDirectoriesInOnePass() ([]string, error) {
 	memosDir := uc.config.MemosDir()
 	// Check if directory exists before walking
 	if _, err := os.Stat(memosDir); os.IsNotExist(err) {
 		// Directory doesn't exist, nothing to remove
-		return 0, nil
+		return nil, nil
 	}
 
-	count := 0
+	var removed []string
 	err := filepath.Walk(memosDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
@@ -145,7 +285,8 @@ func (uc memo) removeEmptyDirectoriesInOnePass() (int, error) {
 			if err != nil {
 				return common.Wrap(err, common.ErrorTypeFileSystem, fmt.Sprintf("failed to remove empty directory: %s", path))
 			}
-			count++
+			rel, _ := filepath.Rel(memosDir, path)
+			removed = append(removed, rel)
 			uc.logger.Info("Removed empty directory", "path", path)
 		}
 
@@ -153,10 +294,10 @@ func (uc memo) removeEmptyDirectoriesInOnePass() (int, error) {
 	})
 
 	if err != nil {
-		return 0, common.Wrap(err, common.ErrorTypeFileSystem, "error walking through directories")
+		return nil, common.Wrap(err, common.ErrorTypeFileSystem, "error walking through directories")
 	}
 
-	return count, nil
+	return removed, nil
 }
 
 func (uc memo) shouldCheckDirectory(info os.FileInfo, path string) bool {
@@ -170,21 +311,3 @@ func (uc memo) isDirectoryEmpty(path string) bool {
 	}
 	return len(entries) == 0
 }
-
-// parseDateFromMemoFilename extracts date from memo filename
-func parseDateFromMemoFilename(filename string) (time.Time, error) {
-	date, err := repoCommon.ParseDateFromFilename(filename, repoCommon.DateParserConfig{
-		DateTimeRegex: domain.FileNameDateTimeRegexMemo,
-		DateLayout:    domain.FileNameDateLayoutMemo,
-	})
-	return date, err
-}
-
-// extractTitleFromMemoFilename extracts title from memo filename
-func extractTitleFromMemoFilename(filename string) string {
-	datetimestring := regexp.MustCompile(domain.FileNameDateTimeRegexMemo).FindString(filename)
-	title := strings.TrimPrefix(filename, datetimestring+"_memo_")
-	title = strings.TrimSuffix(title, ".md")
-	return title
-}
-
@@ -0,0 +1,123 @@
+package memo
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hirotoni/memov2/internal/config/toml"
+	"github.com/hirotoni/memov2/internal/domain"
+	"github.com/hirotoni/memov2/internal/repositories"
+	"github.com/hirotoni/memov2/internal/repositories/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTidyMemo writes a memo file at rel, under memosDir, with the given frontmatter
+func writeTidyMemo(t *testing.T, memosDir, rel, frontmatter string) {
+	t.Helper()
+	path := filepath.Join(memosDir, rel)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	content := "---\n" + frontmatter + "---\n\n# " + domain.MemoTitle(filepath.Base(rel)) + "\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func TestTidy(t *testing.T) {
+	// Setup
+	tmpDir := t.TempDir()
+	opts := toml.Option{BaseDir: tmpDir}
+	cfg, err := toml.NewConfig(opts)
+	require.NoError(t, err)
+
+	configProvider := toml.NewProvider(cfg)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	repos := repositories.NewRepositories(configProvider, logger)
+	uc := NewMemo(configProvider, repos, mock.NewMockEditor(), logger)
+
+	memosDir := cfg.MemosDir()
+	name := func(title string) string {
+		return time.Date(2025, 2, 14, 9, 0, 0, 0, time.Local).Format("20060102Mon150405") + "_memo_" + title + ".md"
+	}
+	// Out of place, and free to move
+	writeTidyMemo(t, memosDir, filepath.Join("inbox", name("plan")), "category: [\"work\"]\n")
+	// Out of place, but a memo of the same name is where it belongs
+	writeTidyMemo(t, memosDir, filepath.Join("old", name("notes")), "category: [\"work\"]\n")
+	writeTidyMemo(t, memosDir, filepath.Join("work", name("notes")), "category: [\"work\"]\n")
+	// Unparseable frontmatter
+	writeTidyMemo(t, memosDir, filepath.Join("misc", name("broken")), "category: [unterminated\n")
+	// Without a category key, and not a memo: left alone
+	writeTidyMemo(t, memosDir, filepath.Join("misc", name("loose")), "tags: [x]\n")
+	writeTidyMemo(t, memosDir, filepath.Join("misc", "README.md"), "category: [\"work\"]\n")
+
+	wantOutput := "move\t" + filepath.Join("inbox", name("plan")) + "\t" + filepath.Join("work", name("plan")) + "\n" +
+		"conflict\t" + filepath.Join("old", name("notes")) + "\t" + filepath.Join("work", name("notes")) + "\ttarget exists\n"
+
+	// Execute - dry run
+	var tidyErr error
+	output := captureStdout(t, func() error {
+		tidyErr = uc.Tidy(true)
+		return nil
+	})
+
+	// Assert - problems are reported and nothing is moved
+	require.Error(t, tidyErr)
+	assert.Contains(t, tidyErr.Error(), "2 memo files left in place")
+	assert.Contains(t, output, wantOutput)
+	assert.Contains(t, output, "skip\t"+filepath.Join("misc", name("broken"))+"\t")
+	assert.FileExists(t, filepath.Join(memosDir, "inbox", name("plan")))
+
+	// Execute
+	output = captureStdout(t, func() error {
+		tidyErr = uc.Tidy(false)
+		return nil
+	})
+
+	// Assert - the free memo is moved, the others are left in place
+	require.Error(t, tidyErr)
+	assert.Contains(t, output, wantOutput)
+	assert.Contains(t, output, "remove\tinbox\n")
+	assert.FileExists(t, filepath.Join(memosDir, "work", name("plan")))
+	assert.NoDirExists(t, filepath.Join(memosDir, "inbox"))
+	assert.FileExists(t, filepath.Join(memosDir, "old", name("notes")))
+	assert.FileExists(t, filepath.Join(memosDir, "misc", name("broken")))
+	assert.FileExists(t, filepath.Join(memosDir, "misc", name("loose")))
+	assert.FileExists(t, filepath.Join(memosDir, "misc", "README.md"))
+
+	// weekly and index tidy without failing on the problems
+	require.NoError(t, uc.TidyMemos())
+}
+
+func TestTidy_SameTarget(t *testing.T) {
+	// Setup
+	tmpDir := t.TempDir()
+	opts := toml.Option{BaseDir: tmpDir}
+	cfg, err := toml.NewConfig(opts)
+	require.NoError(t, err)
+
+	configProvider := toml.NewProvider(cfg)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	repos := repositories.NewRepositories(configProvider, logger)
+	uc := NewMemo(configProvider, repos, mock.NewMockEditor(), logger)
+
+	// Two memos of the same name in different folders, both belonging in work
+	memosDir := cfg.MemosDir()
+	fileName := "20250214Fri090000_memo_notes.md"
+	writeTidyMemo(t, memosDir, filepath.Join("a", fileName), "category: [\"work\"]\n")
+	writeTidyMemo(t, memosDir, filepath.Join("b", fileName), "category: [\"work\"]\n")
+
+	// Execute
+	var tidyErr error
+	output := captureStdout(t, func() error {
+		tidyErr = uc.Tidy(false)
+		return nil
+	})
+
+	// Assert - the first is moved, the second is not moved over it
+	require.Error(t, tidyErr)
+	assert.Contains(t, output, "move\t"+filepath.Join("a", fileName))
+	assert.Contains(t, output, "conflict\t"+filepath.Join("b", fileName)+"\t"+filepath.Join("work", fileName)+"\tcollides with the move of "+filepath.Join("a", fileName)+"\n")
+	assert.FileExists(t, filepath.Join(memosDir, "work", fileName))
+	assert.FileExists(t, filepath.Join(memosDir, "b", fileName))
+}
@@ -0,0 +1,145 @@
+package memo
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hirotoni/memov2/internal/common"
+	"github.com/hirotoni/memov2/internal/config"
+	"github.com/hirotoni/memov2/internal/domain"
+	"github.com/hirotoni/memov2/internal/interfaces"
+	"github.com/hirotoni/memov2/internal/platform"
+	memoRepo "github.com/hirotoni/memov2/internal/repositories/memo"
+)
+
+// titleChange is a memo whose file name and H1 are brought in line. The path
+// is relative to the memos directory, before the rename.
+type titleChange struct {
+	path  string
+	title string
+	memo  interfaces.MemoFileInterface
+}
+
+// titleMismatch returns the rename that makes the title in the file name of
+// the memo at path and its H1 agree, taking the title from the one
+// title_source names. Memos that cannot be parsed, or are outside the
+// directory of their category, are left for tidy. It returns nil if there is
+// nothing to rename.
+func (uc memo) titleMismatch(path string) *titleChange {
+	info, err := os.Stat(path)
+	if err != nil || !memoFileNameRegex.MatchString(info.Name()) {
+		return nil
+	}
+	m, err := memoRepo.ParseMemoFile(path, info, uc.logger)
+	if err != nil {
+		uc.logger.Warn("Skipping unparseable memo file", "path", path, "error", err)
+		return nil
+	}
+	// Renaming finds the memo where its category puts it
+	if filepath.Join(uc.config.MemosDir(), domain.MemoPath(m)) != path {
+		return nil
+	}
+
+	title, ok := domain.SyncedTitle(m, uc.config.TitleSource() == config.TitleSourceHeading)
+	if !ok {
+		return nil
+	}
+	return &titleChange{path: domain.MemoPath(m), title: title, memo: m}
+}
+
+// confirmTitleChange asks on the terminal whether to make change
+func confirmTitleChange(change *titleChange) (bool, error) {
+	answer, err := platform.ReadLine(fmt.Sprintf("File name and H1 of %s differ. Retitle as %q? [y/N]: ", change.path, change.title))
+	if err != nil {
+		return false, err
+	}
+	return strings.EqualFold(strings.TrimSpace(answer), "y"), nil
+}
+
+// syncTitleAfterEdit syncs the title of the file at path, as title_sync says,
+// once the editor it was opened in has closed. Without a terminal to ask on,
+// "prompt" leaves the memo alone.
+func (uc memo) syncTitleAfterEdit(path string) error {
+	mode := uc.config.TitleSync()
+	if mode == config.TitleSyncOff {
+		return nil
+	}
+	change := uc.titleMismatch(path)
+	if change == nil {
+		return nil
+	}
+
+	if mode == config.TitleSyncPrompt {
+		if !platform.IsInteractive() {
+			uc.logger.Warn("Not retitling memo, no terminal to ask on", "path", change.path, "title", change.title)
+			return nil
+		}
+		ok, err := confirmTitleChange(change)
+		if err != nil || !ok {
+			return err
+		}
+	}
+	if err := uc.repos.Memo().Rename(change.memo, change.title); err != nil {
+		return common.Wrap(err, common.ErrorTypeService, fmt.Sprintf("error retitling memo %s", change.path))
+	}
+	return nil
+}
+
+// syncTitles brings the file names and H1s of every memo in line, as
+// titleMismatch says, and returns the renames made. With ask each rename is
+// confirmed first, and with dryRun the renames are only returned.
+func (uc memo) syncTitles(ask, dryRun bool) ([]titleChange, error) {
+	memosDir := uc.config.MemosDir()
+	if !platform.Exists(memosDir) {
+		return nil, nil
+	}
+
+	// Collect the paths first, as renaming changes the tree
+	var paths []string
+	err := filepath.WalkDir(memosDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && memoFileNameRegex.MatchString(d.Name()) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, common.Wrap(err, common.ErrorTypeFileSystem, "error walking through memos")
+	}
+
+	var changes []titleChange
+	for _, path := range paths {
+		change := uc.titleMismatch(path)
+		if change == nil {
+			continue
+		}
+		if ask && !dryRun {
+			ok, err := confirmTitleChange(change)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+		}
+		changes = append(changes, *change)
+	}
+	if dryRun {
+		return changes, nil
+	}
+
+	renames := make([]interfaces.MemoRename, len(changes))
+	for i, c := range changes {
+		renames[i] = interfaces.MemoRename{File: c.memo, NewTitle: c.title}
+	}
+	n, err := uc.repos.Memo().RenameAll(renames)
+	if err != nil {
+		return changes[:n], common.Wrap(err, common.ErrorTypeService, fmt.Sprintf("error retitling memo %s", changes[n].path))
+	}
+	return changes, nil
+}
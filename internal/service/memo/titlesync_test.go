@@ -0,0 +1,164 @@
+package memo
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hirotoni/memov2/internal/config"
+	"github.com/hirotoni/memov2/internal/config/toml"
+	"github.com/hirotoni/memov2/internal/platform"
+	"github.com/hirotoni/memov2/internal/repositories"
+	"github.com/hirotoni/memov2/internal/repositories/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpen_SyncTitle(t *testing.T) {
+	const name = "20250214Fri090000_memo_plan.md"
+	testCases := []struct {
+		name      string
+		source    string
+		sync      string
+		heading   string // the H1 written in the editor
+		wantFile  string
+		wantTitle string
+	}{
+		{
+			name:      "heading renames the file, sanitized",
+			source:    config.TitleSourceHeading,
+			sync:      config.TitleSyncAuto,
+			heading:   "launch/plan",
+			wantFile:  "20250214Fri090000_memo_launch-plan.md",
+			wantTitle: "# launch plan\n",
+		},
+		{
+			name:      "file name rewrites the H1",
+			source:    config.TitleSourceFileName,
+			sync:      config.TitleSyncAuto,
+			heading:   "something else",
+			wantFile:  name,
+			wantTitle: "# plan\n",
+		},
+		{
+			name:      "off leaves the memo alone",
+			source:    config.TitleSourceHeading,
+			sync:      config.TitleSyncOff,
+			heading:   "something else",
+			wantFile:  name,
+			wantTitle: "# something else\n",
+		},
+		{
+			name:      "spaces match fillers",
+			source:    config.TitleSourceHeading,
+			sync:      config.TitleSyncPrompt,
+			heading:   "plan",
+			wantFile:  name,
+			wantTitle: "# plan\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Setup
+			tmpDir := t.TempDir()
+			cfg, err := toml.NewConfig(toml.Option{BaseDir: tmpDir, TitleSource: tc.source, TitleSync: tc.sync})
+			require.NoError(t, err)
+
+			configProvider := toml.NewProvider(cfg)
+			logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+			repos := repositories.NewRepositories(configProvider, logger)
+			editor := mock.NewMockEditor()
+			editor.OpenFunc = func(basedir, path string) error {
+				b, err := os.ReadFile(path)
+				if err != nil {
+					return err
+				}
+				return os.WriteFile(path, []byte(strings.Replace(string(b), "# plan\n", "# "+tc.heading+"\n", 1)), 0o644)
+			}
+			uc := NewMemo(configProvider, repos, editor, logger)
+
+			writeTidyMemo(t, cfg.MemosDir(), filepath.Join("work", name), "category: [\"work\"]\n")
+
+			// Execute
+			require.NoError(t, uc.Open(filepath.Join("work", name)))
+
+			// Assert
+			b, err := os.ReadFile(filepath.Join(cfg.MemosDir(), "work", tc.wantFile))
+			require.NoError(t, err)
+			assert.Contains(t, string(b), tc.wantTitle)
+			if tc.wantFile != name {
+				assert.NoFileExists(t, filepath.Join(cfg.MemosDir(), "work", name))
+			}
+		})
+	}
+}
+
+func TestTidy_SyncTitles(t *testing.T) {
+	// Setup
+	tmpDir := t.TempDir()
+	cfg, err := toml.NewConfig(toml.Option{BaseDir: tmpDir, TitleSync: config.TitleSyncAuto})
+	require.NoError(t, err)
+
+	configProvider := toml.NewProvider(cfg)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	repos := repositories.NewRepositories(configProvider, logger)
+	uc := NewMemo(configProvider, repos, mock.NewMockEditor(), logger)
+
+	memosDir := cfg.MemosDir()
+	old := filepath.Join(memosDir, "work", "20250214Fri090000_memo_draft.md")
+	require.NoError(t, os.MkdirAll(filepath.Dir(old), 0o755))
+	require.NoError(t, os.WriteFile(old, []byte("---\ncategory: [\"work\"]\n---\n\n# final: v2\n"), 0o644))
+
+	wantOutput := "title\t" + filepath.Join("work", "20250214Fri090000_memo_draft.md") + "\tfinal v2\n"
+
+	// Execute - dry run
+	output := captureStdout(t, func() error { return uc.Tidy(true) })
+
+	// Assert
+	assert.Equal(t, wantOutput, output)
+	assert.FileExists(t, old)
+
+	// Execute
+	output = captureStdout(t, func() error { return uc.Tidy(false) })
+
+	// Assert
+	assert.Equal(t, wantOutput, output)
+	assert.NoFileExists(t, old)
+	assert.FileExists(t, filepath.Join(memosDir, "work", "20250214Fri090000_memo_final-v2.md"))
+}
+
+func TestTidy_SyncTitlesWithoutTerminal(t *testing.T) {
+	// Setup - there is no terminal to ask on
+	tty := platform.TTY
+	platform.TTY = filepath.Join(t.TempDir(), "tty")
+	defer func() { platform.TTY = tty }()
+
+	tmpDir := t.TempDir()
+	cfg, err := toml.NewConfig(toml.Option{BaseDir: tmpDir, TitleSync: config.TitleSyncPrompt})
+	require.NoError(t, err)
+
+	configProvider := toml.NewProvider(cfg)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	repos := repositories.NewRepositories(configProvider, logger)
+	uc := NewMemo(configProvider, repos, mock.NewMockEditor(), logger)
+
+	old := filepath.Join(cfg.MemosDir(), "work", "20250214Fri090000_memo_draft.md")
+	require.NoError(t, os.MkdirAll(filepath.Dir(old), 0o755))
+	require.NoError(t, os.WriteFile(old, []byte("---\ncategory: [\"work\"]\n---\n\n# final\n"), 0o644))
+
+	// Execute
+	var tidyErr error
+	output := captureStdout(t, func() error {
+		tidyErr = uc.Tidy(false)
+		return nil
+	})
+
+	// Assert - the rename is reported as with --dry-run and fails the exit status
+	assert.Equal(t, "title\t"+filepath.Join("work", "20250214Fri090000_memo_draft.md")+"\tfinal\n", output)
+	require.Error(t, tidyErr)
+	assert.Contains(t, tidyErr.Error(), "1 titles to confirm on a terminal")
+	assert.FileExists(t, old)
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/hirotoni/memov2/internal/interfaces"
 	"github.com/hirotoni/memov2/internal/repositories"
 	"github.com/hirotoni/memov2/internal/service/config"
+	"github.com/hirotoni/memov2/internal/service/doctor"
 	"github.com/hirotoni/memov2/internal/service/memo"
 	"github.com/hirotoni/memov2/internal/service/todo"
 )
@@ -15,6 +16,7 @@ type services struct {
 	memo   interfaces.MemoService
 	todo   interfaces.TodoService
 	config interfaces.ConfigService
+	doctor interfaces.DoctorService
 }
 
 // NewServices creates a new Services instance with all dependencies
@@ -27,9 +29,11 @@ func NewServices(c interfaces.ConfigProvider, e interfaces.Editor, logger *slog.
 		memo:   memo.NewMemo(c, r, e, logger),
 		todo:   todo.NewTodo(c, r, e, logger),
 		config: config.NewConfig(c, r, e, logger),
+		doctor: doctor.NewDoctor(c, r, logger),
 	}
 }
 
 func (r services) Memo() interfaces.MemoService     { return r.memo }
 func (r services) Todo() interfaces.TodoService     { return r.todo }
 func (r services) Config() interfaces.ConfigService { return r.config }
+func (r services) Doctor() interfaces.DoctorService { return r.doctor }
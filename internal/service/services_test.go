@@ -30,6 +30,7 @@ func TestNewServices(t *testing.T) {
 	assert.NotNil(t, ucs.Memo())
 	assert.NotNil(t, ucs.Todo())
 	assert.NotNil(t, ucs.Config())
+	assert.NotNil(t, ucs.Doctor())
 }
 
 func TestServices_Memo(t *testing.T) {
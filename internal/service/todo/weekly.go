@@ -5,9 +5,6 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/hexops/gotextdiff"
-	"github.com/hexops/gotextdiff/myers"
-	"github.com/hexops/gotextdiff/span"
 	"github.com/hirotoni/memov2/internal/common"
 	"github.com/hirotoni/memov2/internal/domain"
 	"github.com/hirotoni/memov2/internal/domain/markdown"
@@ -102,7 +99,5 @@ func generateTodoDiff(prev, curr domain.TodoFileInterface) string {
 }
 
 func todoDiff(aname, atext, bname, btext string) string {
-	edits := myers.ComputeEdits(span.URIFromPath(aname), atext, btext)
-	diff := fmt.Sprint(gotextdiff.ToUnified(aname, bname, atext, edits))
-	return fmt.Sprintln(diff)
+	return fmt.Sprintln(utils.UnifiedDiff(aname, atext, bname, btext))
 }
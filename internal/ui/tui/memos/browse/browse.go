@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -14,12 +15,16 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/hirotoni/memov2/internal/common"
+	"github.com/hirotoni/memov2/internal/config"
 	"github.com/hirotoni/memov2/internal/config/toml"
 	"github.com/hirotoni/memov2/internal/domain"
 	"github.com/hirotoni/memov2/internal/domain/markdown"
 	"github.com/hirotoni/memov2/internal/interfaces"
 	"github.com/hirotoni/memov2/internal/platform"
 	"github.com/hirotoni/memov2/internal/repositories/memo"
+	memsearch "github.com/hirotoni/memov2/internal/search"
+	"github.com/hirotoni/memov2/internal/utils"
+	"github.com/mattn/go-runewidth"
 	"golang.org/x/term"
 )
 
@@ -42,14 +47,17 @@ var (
 )
 
 type item struct {
-	name     string
-	path     string
-	isDir    bool
-	memo     domain.MemoFileInterface
-	depth    int
-	expanded bool
-	parent   *item
-	children []item
+	name      string
+	path      string
+	isDir     bool
+	memo      domain.MemoFileInterface
+	depth     int
+	expanded  bool
+	parent    *item
+	children  []item
+	saved     *config.SavedSearch // The saved search of a virtual folder, nil for directories
+	searchErr error               // Why the saved search could not be searched
+	task      *memsearch.Task     // A task found by a saved search, nil for memos
 }
 
 func (i item) Title() string {
@@ -59,8 +67,18 @@ func (i item) Title() string {
 		if i.expanded {
 			expandChar = "▼"
 		}
+		if i.saved != nil {
+			return prefix + expandChar + " ★ " + i.name
+		}
 		return prefix + expandChar + " " + i.name + "/"
 	}
+	if i.task != nil {
+		checkbox := "[ ]"
+		if i.task.Done {
+			checkbox = "[x]"
+		}
+		return prefix + "  " + checkbox + " " + i.name
+	}
 	return prefix + "  " + domain.MemoTitle(i.name)
 }
 
@@ -97,6 +115,26 @@ type BrowseModel struct {
 	categoryDialogCursor int
 	collapsedCategories  map[string]bool // Track which categories are collapsed
 	categoryList         list.Model      // Add list model for category dialog
+	links                *domain.LinkIndex
+	linksGeneration      int                                 // Incremented by every refresh, to drop stale link indexes
+	contents             map[string]domain.MemoFileInterface // Memos read in full for the preview, by MemoPath
+	showRelinkDialog     bool
+	relinkAction         string                   // "Rename", "Move" or "Retitle"
+	relinkNote           string                   // Why the change is proposed, "" if the user asked for it
+	relinkRewrites       []interfaces.LinkRewrite // Link rewrites awaiting confirmation
+	relinkApply          func() error             // Applies the rename or move together with the rewrites
+	searchIndex          *memsearch.Index         // Searches the saved searches, created when one is first expanded
+	translit             memsearch.Transliterator
+	showTagDialog        bool
+	tagList              list.Model // Tags to filter the tree by
+	tagFilter            string     // Only memos with this tag are shown, "" for all
+}
+
+// LinksLoadedMsg carries the link index built in the background after the tree
+// was refreshed
+type LinksLoadedMsg struct {
+	generation int
+	links      *domain.LinkIndex
 }
 
 // Helper type for category items
@@ -124,6 +162,27 @@ func (i categoryItem) Description() string {
 	return ""
 }
 
+// Helper type for tag filter items. An empty tag shows all memos.
+type tagItem struct {
+	tag   string
+	count int
+}
+
+func (i tagItem) FilterValue() string {
+	return i.tag
+}
+
+func (i tagItem) Title() string {
+	if i.tag == "" {
+		return "(all memos)"
+	}
+	return fmt.Sprintf("#%s (%d)", i.tag, i.count)
+}
+
+func (i tagItem) Description() string {
+	return ""
+}
+
 func New(c *toml.Config, e interfaces.Editor) (*BrowseModel, error) {
 	if err := platform.EnsureDir(c.MemosDir()); err != nil {
 		return nil, fmt.Errorf("failed to ensure memos directory %s: %w", c.MemosDir(), err)
@@ -170,6 +229,7 @@ func New(c *toml.Config, e interfaces.Editor) (*BrowseModel, error) {
 			key.NewBinding(key.WithKeys("<"), key.WithHelp("<", "collapse all under")),
 			// View
 			key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "toggle preview")),
+			key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "filter by tag")),
 			// Memo operations
 			key.NewBinding(key.WithKeys("N"), key.WithHelp("N", "new memo in category")),
 			key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "rename")),
@@ -192,6 +252,7 @@ func New(c *toml.Config, e interfaces.Editor) (*BrowseModel, error) {
 			key.NewBinding(key.WithKeys("<"), key.WithHelp("<", "collapse all")),
 			// View
 			key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "preview")),
+			key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "tag")),
 			// Memo operations
 			key.NewBinding(key.WithKeys("N"), key.WithHelp("N", "new")),
 			key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "rename")),
@@ -225,6 +286,22 @@ func New(c *toml.Config, e interfaces.Editor) (*BrowseModel, error) {
 		}
 	}
 
+	tl := list.New([]list.Item{}, delegate, w, h)
+	tl.Title = "Filter by Tag"
+	tl.Styles.Title = TitleStyle
+	tl.SetShowTitle(true)
+	tl.SetShowHelp(true)
+	tl.SetShowStatusBar(false)
+	tl.SetShowPagination(false)
+	tl.SetFilteringEnabled(false)
+	tl.DisableQuitKeybindings()
+	tl.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{
+			key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "filter")),
+			key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "cancel")),
+		}
+	}
+
 	m := &BrowseModel{
 		list:                 l,
 		config:               c,
@@ -237,6 +314,7 @@ func New(c *toml.Config, e interfaces.Editor) (*BrowseModel, error) {
 		categoryDialogCursor: 0,
 		collapsedCategories:  make(map[string]bool),
 		categoryList:         cl,
+		tagList:              tl,
 	}
 
 	if _, err := m.updateItems(); err != nil {
@@ -247,7 +325,7 @@ func New(c *toml.Config, e interfaces.Editor) (*BrowseModel, error) {
 }
 
 func (m BrowseModel) Init() tea.Cmd {
-	return tea.EnterAltScreen
+	return tea.Batch(tea.EnterAltScreen, m.loadLinks())
 }
 
 func (m BrowseModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -255,6 +333,13 @@ func (m BrowseModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
 	switch msg := msg.(type) {
+	case LinksLoadedMsg:
+		// A refresh since the load started has a newer one on the way
+		if msg.generation == m.linksGeneration {
+			m.links = msg.links
+		}
+		return m, nil
+
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
@@ -270,6 +355,8 @@ func (m BrowseModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.list.SetHeight(msg.Height - 2)
 		m.categoryList.SetWidth(msg.Width)
 		m.categoryList.SetHeight(msg.Height)
+		m.tagList.SetWidth(msg.Width)
+		m.tagList.SetHeight(msg.Height)
 
 		cmd, err := m.updateItems()
 		if err != nil {
@@ -278,7 +365,34 @@ func (m BrowseModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, tea.Batch(cmd)
 
 	case tea.KeyMsg:
-		if m.showNewMemoDialog {
+		if m.showRelinkDialog {
+			// Handle link rewrite confirmation dialog
+			switch msg.String() {
+			case "y", "Y":
+				apply, action := m.relinkApply, m.relinkAction
+				m.showRelinkDialog = false
+				m.relinkNote = ""
+				m.relinkRewrites = nil
+				m.relinkApply = nil
+				if err := apply(); err != nil {
+					m.err = fmt.Errorf("failed to %s memo: %w", strings.ToLower(action), err)
+					return m, nil
+				}
+				cmd, err := m.updateItems()
+				if err != nil {
+					return m, tea.Quit
+				}
+				return m, tea.Batch(cmd)
+			case "n", "N", "esc":
+				// Cancel the rename or move entirely
+				m.showRelinkDialog = false
+				m.relinkNote = ""
+				m.relinkRewrites = nil
+				m.relinkApply = nil
+				return m, nil
+			}
+			return m, nil
+		} else if m.showNewMemoDialog {
 			// Handle new memo dialog
 			switch msg.String() {
 			case "esc":
@@ -312,6 +426,7 @@ func (m BrowseModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						m.newMemoTitleInput = ""
 						return m, nil
 					}
+					newMemo.SetID(domain.NewMemoID(newMemo.Date()))
 
 					// Initialize with empty content structure (proper memo format)
 					emptyContent := &markdown.HeadingBlock{
@@ -345,7 +460,8 @@ func (m BrowseModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						return m, nil
 					}
 
-					return m, tea.Batch(cmd)
+					model, syncCmd := m.syncTitleAfterEdit(newPath)
+					return model, tea.Batch(cmd, syncCmd)
 				}
 				// If empty, just cancel
 				m.showNewMemoDialog = false
@@ -401,19 +517,14 @@ func (m BrowseModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if newTitle != "" && newTitle != m.selectedMemo.Title() {
 					logger := common.DefaultLogger()
 					repo := memo.NewMemo(m.config.MemosDir(), logger)
-					if err := repo.Rename(m.selectedMemo, newTitle); err != nil {
-						m.err = fmt.Errorf("failed to rename memo: %w", err)
-						m.showRenameDialog = false
-						m.renameInput = ""
-						return m, nil
-					}
 					m.showRenameDialog = false
 					m.renameInput = ""
-					cmd, err := m.updateItems()
+					plan, err := repo.PlanRename(m.selectedMemo, newTitle)
 					if err != nil {
-						return m, tea.Quit
+						m.err = fmt.Errorf("failed to rename memo: %w", err)
+						return m, nil
 					}
-					return m, tea.Batch(cmd)
+					return m.relink("Rename", plan.LinkRewrites(), func() error { return repo.ApplyRelocation(plan) })
 				}
 				// If same title or empty, just cancel
 				m.showRenameDialog = false
@@ -454,6 +565,26 @@ func (m BrowseModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 			return m, nil
+		} else if m.showTagDialog {
+			switch msg.String() {
+			case "esc":
+				m.showTagDialog = false
+				return m, nil
+			case "enter":
+				m.showTagDialog = false
+				if i, ok := m.tagList.SelectedItem().(tagItem); ok {
+					cmd, err := m.setTagFilter(i.tag)
+					if err != nil {
+						return m, tea.Quit
+					}
+					return m, cmd
+				}
+				return m, nil
+			default:
+				var cmd tea.Cmd
+				m.tagList, cmd = m.tagList.Update(msg)
+				return m, cmd
+			}
 		} else if m.showCategoryDialog {
 			// Check if we're in new category input mode
 			if m.categoryDialogCursor == -1 {
@@ -564,16 +695,13 @@ func (m BrowseModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 				logger := common.DefaultLogger()
 				repo := memo.NewMemo(m.config.MemosDir(), logger)
-				if err := repo.Move(m.selectedMemo, selectedPath); err != nil {
+				plan, err := repo.PlanMove(m.selectedMemo, selectedPath)
+				if err != nil {
 					m.err = fmt.Errorf("failed to move memo: %w", err)
 					return m, nil
 				}
 				m.showCategoryDialog = false
-				cmd, err := m.updateItems()
-				if err != nil {
-					return m, tea.Quit
-				}
-				return m, tea.Batch(cmd)
+				return m.relink("Move", plan.LinkRewrites(), func() error { return repo.ApplyRelocation(plan) })
 			case "n":
 				m.categoryDialogCursor = -1 // Use -1 as sentinel for input mode
 				m.newCategoryInput = ""     // Start with empty input
@@ -599,6 +727,68 @@ func (m BrowseModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+// relink applies a rename or move right away if no other file links to the memo,
+// otherwise it shows the link rewrites for confirmation first
+func (m BrowseModel) relink(action string, rewrites []interfaces.LinkRewrite, apply func() error) (tea.Model, tea.Cmd) {
+	if len(rewrites) > 0 {
+		m.showRelinkDialog = true
+		m.relinkAction = action
+		m.relinkRewrites = rewrites
+		m.relinkApply = apply
+		return m, nil
+	}
+
+	if err := apply(); err != nil {
+		m.err = fmt.Errorf("failed to %s memo: %w", strings.ToLower(action), err)
+		return m, nil
+	}
+	cmd, err := m.updateItems()
+	if err != nil {
+		return m, tea.Quit
+	}
+	return m, tea.Batch(cmd)
+}
+
+// syncTitleAfterEdit renames the memo at path, after it was edited, so that
+// the title in its file name and its H1 agree, as title_source and title_sync
+// say. With title_sync "prompt" the rename waits in the relink dialog.
+func (m BrowseModel) syncTitleAfterEdit(path string) (tea.Model, tea.Cmd) {
+	if m.config.TitleSync() == config.TitleSyncOff {
+		return m, nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return m, nil // deleted in the editor
+	}
+	logger := common.DefaultLogger()
+	mm, err := memo.ParseMemoFile(path, info, logger)
+	if err != nil || filepath.Join(m.config.MemosDir(), domain.MemoPath(mm)) != path {
+		// Unparseable, or outside its category directory until the next tidy
+		return m, nil
+	}
+	title, ok := domain.SyncedTitle(mm, m.config.TitleSource() == config.TitleSourceHeading)
+	if !ok {
+		return m, nil
+	}
+
+	repo := memo.NewMemo(m.config.MemosDir(), logger)
+	plan, err := repo.PlanRename(mm, title)
+	if err != nil {
+		m.err = fmt.Errorf("failed to retitle memo: %w", err)
+		return m, nil
+	}
+	apply := func() error { return repo.ApplyRelocation(plan) }
+	if m.config.TitleSync() == config.TitleSyncAuto {
+		return m.relink("Retitle", nil, apply)
+	}
+	m.showRelinkDialog = true
+	m.relinkAction = "Retitle"
+	m.relinkNote = fmt.Sprintf("The file name and the H1 of %s differ. Retitle it as %q?", domain.MemoPath(mm), title)
+	m.relinkRewrites = plan.LinkRewrites()
+	m.relinkApply = apply
+	return m, nil
+}
+
 func BrowseKeybindings(m BrowseModel, msg tea.KeyMsg) (BrowseModel, tea.Cmd) {
 	switch {
 	case key.Matches(msg, key.NewBinding(key.WithKeys("ctrl+c", "q"))):
@@ -615,10 +805,31 @@ func BrowseKeybindings(m BrowseModel, msg tea.KeyMsg) (BrowseModel, tea.Cmd) {
 			m.list.SetWidth(m.width)
 		}
 
+		return m, nil
+	case key.Matches(msg, key.NewBinding(key.WithKeys("t"))):
+		logger := common.DefaultLogger()
+		repo := memo.NewMemo(m.config.MemosDir(), logger)
+		tags, err := repo.Tags()
+		if err != nil {
+			m.err = fmt.Errorf("failed to get tags: %w", err)
+			return m, nil
+		}
+
+		items := []list.Item{tagItem{}}
+		selected := 0
+		for _, tc := range tags {
+			if tc.Tag == m.tagFilter {
+				selected = len(items)
+			}
+			items = append(items, tagItem{tag: tc.Tag, count: tc.Count})
+		}
+		m.tagList.SetItems(items)
+		m.tagList.Select(selected)
+		m.showTagDialog = true
 		return m, nil
 	case key.Matches(msg, key.NewBinding(key.WithKeys("N"))):
 		if i, ok := m.list.SelectedItem().(item); ok {
-			if i.isDir {
+			if i.isDir && i.saved == nil {
 				// Directory selected - extract category tree from path
 				m.selectedMemo = nil
 				m.selectedCategoryTree = m.pathToCategoryTree(i.path)
@@ -704,13 +915,20 @@ func BrowseKeybindings(m BrowseModel, msg tea.KeyMsg) (BrowseModel, tea.Cmd) {
 					}
 					return m, tea.Batch(cmd)
 				}
+			} else if i.task != nil {
+				pos := interfaces.Position{Line: i.task.Line + 1, Column: i.task.Column + 1}
+				if err := m.editor.OpenAt(m.config.BaseDir(), i.path, pos); err != nil {
+					m.err = fmt.Errorf("failed to open editor: %w", err)
+				}
+				return m, nil
 			} else {
 				err := m.editor.Open(m.config.BaseDir(), i.path)
 				if err != nil {
 					m.err = fmt.Errorf("failed to open editor: %w", err)
 					return m, nil
 				}
-				return m, nil
+				model, cmd := m.syncTitleAfterEdit(i.path)
+				return model.(BrowseModel), cmd
 			}
 		}
 	case key.Matches(msg, key.NewBinding(key.WithKeys("h"))):
@@ -895,6 +1113,10 @@ func (m BrowseModel) View() string {
 		return fmt.Sprintf("Error: %v", m.err)
 	}
 
+	if m.showRelinkDialog {
+		return m.renderRelinkDialog()
+	}
+
 	if m.showNewMemoDialog {
 		var sb strings.Builder
 
@@ -1007,7 +1229,7 @@ func (m BrowseModel) View() string {
 
 	// Return split view if preview is enabled and not showing dialogs
 	if !m.showNewMemoDialog && !m.showDuplicateDialog && !m.showRenameDialog &&
-		!m.showDeleteDialog && !m.showCategoryDialog && m.showPreview {
+		!m.showDeleteDialog && !m.showCategoryDialog && !m.showTagDialog && m.showPreview {
 		return m.renderSplitView()
 	}
 
@@ -1045,18 +1267,46 @@ func (m BrowseModel) View() string {
 		return m.categoryList.View()
 	}
 
+	if m.showTagDialog {
+		return m.tagList.View()
+	}
+
 	return m.list.View()
 }
 
+// updateItems rebuilds the tree from the memos' file names and frontmatter.
+// Their content is read when previewed, and the returned command builds the
+// link index, which needs every memo's body, in the background.
 func (m *BrowseModel) updateItems() (tea.Cmd, error) {
-	// Build the tree structure starting from the memos directory
-	rootItems, err := m.buildTree(m.config.MemosDir(), 0, nil)
+	logger := common.DefaultLogger()
+	repo := memo.NewMemo(m.config.MemosDir(), logger)
+	memos, err := repo.MemoHeaders()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get memo entries: %w", err)
+	}
+
+	memoMap := make(map[string]domain.MemoFileInterface)
+	for _, memo := range memos {
+		memoMap[domain.MemoPath(memo)] = memo
+	}
+	m.contents = make(map[string]domain.MemoFileInterface)
+	m.linksGeneration++
+
+	// Build the tree structure starting from the memos directory, after the
+	// saved searches
+	rootItems, err := m.buildTree(m.config.MemosDir(), 0, nil, memoMap)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build tree: %w", err)
 	}
+	rootItems = append(m.savedSearchItems(), rootItems...)
 
 	// Preserve expansion state from previous items
 	m.preserveExpansionState(&rootItems, &m.items)
+	for i := range rootItems {
+		if rootItems[i].saved != nil && rootItems[i].expanded {
+			rootItems[i].children, rootItems[i].searchErr = m.savedSearchResults(&rootItems[i], memoMap)
+		}
+	}
 	m.items = rootItems
 
 	// Flatten the tree for display
@@ -1067,10 +1317,123 @@ func (m *BrowseModel) updateItems() (tea.Cmd, error) {
 	}
 
 	m.list.SetItems(listItems)
-	return nil, nil
+	return m.loadLinks(), nil
+}
+
+// setTagFilter shows only the memos tagged with tag, "" for all memos. The
+// directories left are expanded so that the memos found are in view.
+func (m *BrowseModel) setTagFilter(tag string) (tea.Cmd, error) {
+	m.tagFilter = tag
+	m.list.Title = "Memos Browser"
+	if tag != "" {
+		m.list.Title += " #" + tag
+	}
+
+	cmd, err := m.updateItems()
+	if err != nil || tag == "" {
+		return cmd, err
+	}
+	for i := range m.items {
+		if m.items[i].saved == nil {
+			m.expandItemAndChildren(&m.items[i], true)
+		}
+	}
+	return m.updateItems()
 }
 
-func (m *BrowseModel) buildTree(path string, depth int, parent *item) ([]item, error) {
+// savedSearchItems returns the virtual folders of the saved searches, in the
+// order configured. Their results are searched when they are expanded.
+func (m *BrowseModel) savedSearchItems() []item {
+	var items []item
+	for _, s := range m.config.SavedSearches() {
+		items = append(items, item{
+			name:  s.Name,
+			path:  "saved:" + s.Name,
+			isDir: true,
+			saved: &s,
+		})
+	}
+	return items
+}
+
+// savedSearchResults searches the query of the saved search folder and returns
+// the memos and tasks found, as its children
+func (m *BrowseModel) savedSearchResults(folder *item, memoMap map[string]domain.MemoFileInterface) ([]item, error) {
+	if m.searchIndex == nil {
+		tr, err := memsearch.NewTransliterator(m.config.SearchTransliterators(), m.config.SearchDictionaries())
+		if err != nil {
+			return nil, fmt.Errorf("failed to load transliterators: %w", err)
+		}
+		logger := common.DefaultLogger()
+		m.translit = tr
+		m.searchIndex = memsearch.NewIndex(memsearch.IndexPath(m.config.BaseDir()), m.config.MemosDir(), func(path string, info os.FileInfo) (domain.MemoFileInterface, error) {
+			return memo.ParseMemoFile(path, info, logger)
+		}).WithTodos(m.config.TodosDir())
+	}
+
+	q, err := memsearch.ParseQuery(folder.saved.Query, m.translit)
+	if err != nil {
+		return nil, err
+	}
+	results, err := m.searchIndex.Search(q, memsearch.OrderRelevance)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+
+	children := make([]item, 0, len(results))
+	for _, r := range results {
+		child := item{depth: folder.depth + 1, parent: folder}
+		if r.Task != nil {
+			child.name = r.Task.Text
+			child.path = filepath.Join(m.config.TodosDir(), r.Task.Path)
+			child.task = r.Task
+		} else {
+			child.name = r.Memo.FileName()
+			child.path = filepath.Join(m.config.MemosDir(), r.Path())
+			// The tree's header, so the preview reads the memo as elsewhere
+			child.memo = r.Memo
+			if header, ok := memoMap[domain.MemoPath(r.Memo)]; ok {
+				child.memo = header
+			}
+		}
+		children = append(children, child)
+	}
+	return children, nil
+}
+
+// loadLinks returns a command that builds the link index of the memos
+func (m BrowseModel) loadLinks() tea.Cmd {
+	generation := m.linksGeneration
+	dir := m.config.MemosDir()
+	return func() tea.Msg {
+		logger := common.DefaultLogger()
+		memos, err := memo.NewMemo(dir, logger).MemoEntries()
+		if err != nil {
+			logger.Warn("Failed to load memo links", "error", err)
+			return LinksLoadedMsg{generation: generation}
+		}
+		return LinksLoadedMsg{generation: generation, links: domain.NewLinkIndex(memos)}
+	}
+}
+
+// memoContent returns the memo read in full, as the tree holds only its
+// frontmatter. It is read once per refresh; on failure the header is returned.
+func (m BrowseModel) memoContent(header domain.MemoFileInterface) domain.MemoFileInterface {
+	key := domain.MemoPath(header)
+	if mm, ok := m.contents[key]; ok {
+		return mm
+	}
+	mm, err := memo.NewMemo(m.config.MemosDir(), common.DefaultLogger()).Memo(header)
+	if err != nil {
+		return header
+	}
+	if m.contents != nil {
+		m.contents[key] = mm
+	}
+	return mm
+}
+
+func (m *BrowseModel) buildTree(path string, depth int, parent *item, memoMap map[string]domain.MemoFileInterface) ([]item, error) {
 	var items []item
 
 	entries, err := os.ReadDir(path)
@@ -1091,29 +1454,19 @@ func (m *BrowseModel) buildTree(path string, depth int, parent *item) ([]item, e
 			}
 
 			// Recursively build children
-			children, err := m.buildTree(newItem.path, depth+1, &newItem)
+			children, err := m.buildTree(newItem.path, depth+1, &newItem, memoMap)
 			if err != nil {
 				return nil, err
 			}
+			if m.tagFilter != "" && len(children) == 0 {
+				continue // Nothing tagged in this directory
+			}
 			newItem.children = children
 			items = append(items, newItem)
 		}
 	}
 
 	// Then process files
-
-	logger := common.DefaultLogger()
-	repo := memo.NewMemo(m.config.MemosDir(), logger)
-	memos, err := repo.MemoEntries()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get memo entries: %w", err)
-	}
-
-	memoMap := make(map[string]domain.MemoFileInterface)
-	for _, memo := range memos {
-		memoMap[filepath.Join(memo.Location(), memo.FileName())] = memo
-	}
-
 	for _, entry := range entries {
 		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".md") {
 			relPath := strings.TrimPrefix(filepath.Join(path, entry.Name()), m.config.MemosDir())
@@ -1130,6 +1483,9 @@ func (m *BrowseModel) buildTree(path string, depth int, parent *item) ([]item, e
 			if memo, ok := memoMap[relPath]; ok {
 				newItem.memo = memo
 			}
+			if m.tagFilter != "" && (newItem.memo == nil || !slices.Contains(newItem.memo.Tags(), m.tagFilter)) {
+				continue
+			}
 
 			items = append(items, newItem)
 		}
@@ -1191,6 +1547,20 @@ func max(a, b int) int {
 	return b
 }
 
+// SelectedCategory returns the category of the selected directory, or the one
+// the selected memo is in. It is nil at the top of the tree and within saved
+// searches.
+func (m BrowseModel) SelectedCategory() []string {
+	i, ok := m.list.SelectedItem().(item)
+	if !ok || i.saved != nil || (i.parent != nil && i.parent.saved != nil) {
+		return nil
+	}
+	if i.isDir {
+		return m.pathToCategoryTree(i.path)
+	}
+	return m.pathToCategoryTree(filepath.Dir(i.path))
+}
+
 // pathToCategoryTree converts a directory path to a category tree
 func (m *BrowseModel) pathToCategoryTree(dirPath string) []string {
 	// Strip the memos directory prefix
@@ -1341,6 +1711,72 @@ func (m *BrowseModel) updateCategoryItems() {
 	m.categoryList.SetItems(items)
 }
 
+// renderRelinkDialog shows the link rewrites a rename or move will make as a diff
+func (m BrowseModel) renderRelinkDialog() string {
+	var sb strings.Builder
+
+	dialogWidth := 80
+	if m.width > 0 && m.width-4 < dialogWidth {
+		dialogWidth = m.width - 4
+	}
+	dialogStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("63")).
+		Padding(1, 2).
+		Width(dialogWidth)
+
+	addedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	removedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	faintStyle := lipgloss.NewStyle().Faint(true)
+
+	var diff []string
+	for _, rw := range m.relinkRewrites {
+		d := utils.UnifiedDiff(rw.Path, rw.Before, rw.Path, rw.After)
+		diff = append(diff, strings.Split(strings.TrimSuffix(d, "\n"), "\n")...)
+	}
+
+	// Leave room for the dialog frame, header and footer
+	maxLines := 20
+	if m.height > 0 {
+		maxLines = max(m.height-14, 5)
+	}
+
+	header := m.relinkAction + " Memo and Rewrite Links?"
+	if len(m.relinkRewrites) == 0 {
+		header = m.relinkAction + " Memo?"
+	}
+	content := lipgloss.NewStyle().Bold(true).Render(header) + "\n\n"
+	if m.relinkNote != "" {
+		content += m.relinkNote + "\n\n"
+	}
+	if len(m.relinkRewrites) > 0 {
+		content += fmt.Sprintf("%d file(s) link to this memo and will be updated:\n\n", len(m.relinkRewrites))
+	}
+	for i, line := range diff {
+		if i >= maxLines {
+			content += faintStyle.Render(fmt.Sprintf("... and %d more lines", len(diff)-maxLines)) + "\n"
+			break
+		}
+		line = runewidth.Truncate(line, dialogWidth-6, "…")
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"), strings.HasPrefix(line, "@@"):
+			content += faintStyle.Render(line) + "\n"
+		case strings.HasPrefix(line, "+"):
+			content += addedStyle.Render(line) + "\n"
+		case strings.HasPrefix(line, "-"):
+			content += removedStyle.Render(line) + "\n"
+		default:
+			content += line + "\n"
+		}
+	}
+	content += "\n" + lipgloss.NewStyle().Bold(true).Render("Press Y to apply all changes, N or Esc to cancel")
+
+	sb.WriteString("\n")
+	sb.WriteString(dialogStyle.Render(content))
+
+	return sb.String()
+}
+
 // renderSplitView renders the browse list on the left and preview on the right
 func (m BrowseModel) renderSplitView() string {
 	// Calculate split widths (60/40 split)
@@ -1394,6 +1830,10 @@ func (m BrowseModel) renderPreview(width, height int) string {
 		return m.renderMemoPreview(item, previewStyle)
 	}
 
+	if item.task != nil {
+		return m.renderTaskPreview(item, previewStyle)
+	}
+
 	return previewStyle.Render("No preview available")
 }
 
@@ -1404,6 +1844,21 @@ func (m BrowseModel) renderDirectoryPreview(item item, style lipgloss.Style) str
 	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("63"))
 	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
 
+	if item.saved != nil {
+		content.WriteString(titleStyle.Render("★ Saved search") + "\n\n")
+		content.WriteString(labelStyle.Render("Name: ") + item.saved.Name + "\n")
+		content.WriteString(labelStyle.Render("Query: ") + item.saved.Query + "\n\n")
+		switch {
+		case item.searchErr != nil:
+			content.WriteString(labelStyle.Render("Error: ") + item.searchErr.Error() + "\n")
+		case item.expanded:
+			content.WriteString(labelStyle.Render("Results: ") + fmt.Sprint(len(item.children)) + "\n")
+		default:
+			content.WriteString(lipgloss.NewStyle().Faint(true).Render("Expand to search") + "\n")
+		}
+		return style.Render(content.String())
+	}
+
 	content.WriteString(titleStyle.Render("📁 Directory") + "\n\n")
 	content.WriteString(labelStyle.Render("Name: ") + item.name + "\n")
 	content.WriteString(labelStyle.Render("Path: ") + item.path + "\n\n")
@@ -1426,6 +1881,29 @@ func (m BrowseModel) renderDirectoryPreview(item item, style lipgloss.Style) str
 	return style.Render(content.String())
 }
 
+// renderTaskPreview shows a task found by a saved search
+func (m BrowseModel) renderTaskPreview(item item, style lipgloss.Style) string {
+	var content strings.Builder
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("63"))
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	status := "open"
+	if item.task.Done {
+		status = "done"
+	}
+	content.WriteString(titleStyle.Render("☑ Task") + "\n\n")
+	content.WriteString(item.task.Text + "\n\n")
+	content.WriteString(labelStyle.Render("Status: ") + status + "\n")
+	content.WriteString(labelStyle.Render("Date: ") + item.task.Date.Format("2006-01-02") + "\n")
+	if item.task.Section != "" {
+		content.WriteString(labelStyle.Render("Section: ") + item.task.Section + "\n")
+	}
+	content.WriteString(labelStyle.Render("File: ") + fmt.Sprintf("%s:%d", item.task.Path, item.task.Line+1) + "\n")
+
+	return style.Render(content.String())
+}
+
 // renderMemoPreview shows memo metadata and content preview
 func (m BrowseModel) renderMemoPreview(item item, style lipgloss.Style) string {
 	var content strings.Builder
@@ -1435,7 +1913,7 @@ func (m BrowseModel) renderMemoPreview(item item, style lipgloss.Style) string {
 	headingStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("230"))
 	faintStyle := lipgloss.NewStyle().Faint(true)
 
-	memo := item.memo
+	memo := m.memoContent(item.memo)
 
 	// Title
 	content.WriteString(titleStyle.Render("📝 "+domain.MemoTitle(memo.FileName())) + "\n\n")
@@ -1450,6 +1928,10 @@ func (m BrowseModel) renderMemoPreview(item item, style lipgloss.Style) string {
 		content.WriteString(labelStyle.Render("Category: ") + faintStyle.Render("(root)") + "\n")
 	}
 
+	if tags := memo.Tags(); len(tags) > 0 {
+		content.WriteString(labelStyle.Render("Tags: ") + "#" + strings.Join(tags, " #") + "\n")
+	}
+
 	content.WriteString(labelStyle.Render("File: ") + memo.FileName() + "\n")
 
 	// Content preview section - smart 8-line preview
@@ -1520,5 +2002,28 @@ func (m BrowseModel) renderMemoPreview(item item, style lipgloss.Style) string {
 		}
 	}
 
+	// Show memos linking to this one
+	if m.links != nil {
+		backlinks := m.links.Backlinks(memo)
+		if len(backlinks) > 0 {
+			if len(headingBlocks) == 0 {
+				content.WriteString("\n")
+			}
+			content.WriteString("\n" + headingStyle.Render("Linked from") + "\n")
+			maxBacklinks := 5
+			for i, bl := range backlinks {
+				if i >= maxBacklinks {
+					content.WriteString(faintStyle.Render(fmt.Sprintf("  ... and %d more", len(backlinks)-maxBacklinks)) + "\n")
+					break
+				}
+				line := "• " + domain.MemoTitle(bl.Source.FileName())
+				if bl.Section != "" {
+					line += faintStyle.Render(" › " + bl.Section)
+				}
+				content.WriteString(line + "\n")
+			}
+		}
+	}
+
 	return style.Render(content.String())
 }
@@ -10,8 +10,10 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/hirotoni/memov2/internal/common"
+	"github.com/hirotoni/memov2/internal/config"
 	"github.com/hirotoni/memov2/internal/config/toml"
 	"github.com/hirotoni/memov2/internal/domain"
+	"github.com/hirotoni/memov2/internal/domain/markdown"
 	"github.com/hirotoni/memov2/internal/repositories/memo"
 	"github.com/hirotoni/memov2/internal/repositories/mock"
 	"github.com/stretchr/testify/assert"
@@ -1633,3 +1635,301 @@ func TestBrowseModel_ComplexWorkflow(t *testing.T) {
 	updatedModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyCtrlD})
 	assert.NotNil(t, updatedModel)
 }
+
+// TestBrowseModel_PreviewBacklinks tests that the memo preview lists memos linking to it
+func TestBrowseModel_PreviewBacklinks(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg, err := toml.NewConfig(toml.Option{
+		BaseDir:         tempDir,
+		MemosFolderName: "memos/",
+	})
+	require.NoError(t, err)
+
+	logger := common.DefaultLogger()
+	repo := memo.NewMemo(cfg.MemosDir(), logger)
+	now := time.Now()
+	target, err := domain.NewMemoFile(now, "target", []string{"work"})
+	require.NoError(t, err)
+	source, err := domain.NewMemoFile(now.Add(time.Second), "source", []string{"notes"})
+	require.NoError(t, err)
+	source.SetHeadingBlocks([]*markdown.HeadingBlock{
+		{Level: 2, HeadingText: "refs", ContentText: "see [[target]]\n"},
+	})
+	require.NoError(t, repo.Save(target, true))
+	require.NoError(t, repo.Save(source, true))
+
+	editor := &mock.MockEditor{}
+	model, err := New(cfg, editor)
+	require.NoError(t, err)
+	cmd, err := model.updateItems()
+	require.NoError(t, err)
+
+	// Directories start collapsed, so walk the whole tree
+	var targetItem, sourceItem item
+	var walk func(items []item)
+	walk = func(items []item) {
+		for _, it := range items {
+			walk(it.children)
+			if it.memo == nil {
+				continue
+			}
+			switch it.memo.FileName() {
+			case target.FileName():
+				targetItem = it
+			case source.FileName():
+				sourceItem = it
+			}
+		}
+	}
+	walk(model.items)
+	require.NotNil(t, targetItem.memo)
+	require.NotNil(t, sourceItem.memo)
+
+	// The link index is built in the background
+	previewStyle := lipgloss.NewStyle().Width(80)
+	preview := model.renderMemoPreview(targetItem, previewStyle)
+	assert.NotContains(t, preview, "Linked from")
+	require.NotNil(t, cmd)
+	updatedModel, _ := model.Update(cmd())
+	*model = updatedModel.(BrowseModel)
+
+	preview = model.renderMemoPreview(targetItem, previewStyle)
+	assert.Contains(t, preview, "Linked from")
+	assert.Contains(t, preview, "source")
+	assert.Contains(t, preview, "refs")
+
+	preview = model.renderMemoPreview(sourceItem, previewStyle)
+	assert.NotContains(t, preview, "Linked from")
+}
+
+// TestBrowseModel_RenameRelinkDialog tests that renaming a linked memo previews and applies link rewrites
+func TestBrowseModel_RenameRelinkDialog(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg, err := toml.NewConfig(toml.Option{
+		BaseDir:         tempDir,
+		MemosFolderName: "memos/",
+	})
+	require.NoError(t, err)
+
+	logger := common.DefaultLogger()
+	repo := memo.NewMemo(cfg.MemosDir(), logger)
+	now := time.Now()
+	target, err := domain.NewMemoFile(now, "target", []string{"work"})
+	require.NoError(t, err)
+	source, err := domain.NewMemoFile(now.Add(time.Second), "source", nil)
+	require.NoError(t, err)
+	source.SetHeadingBlocks([]*markdown.HeadingBlock{
+		{Level: 2, HeadingText: "refs", ContentText: "[target](work/" + target.FileName() + ")\n"},
+	})
+	require.NoError(t, repo.Save(target, true))
+	require.NoError(t, repo.Save(source, true))
+
+	editor := &mock.MockEditor{}
+	model, err := New(cfg, editor)
+	require.NoError(t, err)
+
+	model.selectedMemo = target
+	model.showRenameDialog = true
+	model.renameInput = "renamed"
+
+	// Confirming the new title shows the link rewrites instead of renaming right away
+	updatedModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m, ok := updatedModel.(BrowseModel)
+	require.True(t, ok)
+	assert.False(t, m.showRenameDialog)
+	assert.True(t, m.showRelinkDialog)
+	view := m.View()
+	assert.Contains(t, view, "Rename Memo and Rewrite Links?")
+	assert.Contains(t, view, source.FileName())
+	assert.FileExists(t, filepath.Join(cfg.MemosDir(), "work", target.FileName()))
+
+	// Pressing Y applies the rename and the rewrites
+	updatedModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	m, ok = updatedModel.(BrowseModel)
+	require.True(t, ok)
+	assert.False(t, m.showRelinkDialog)
+	require.NoError(t, m.err)
+
+	renamed, err := domain.NewMemoFile(now, "renamed", []string{"work"})
+	require.NoError(t, err)
+	assert.NoFileExists(t, filepath.Join(cfg.MemosDir(), "work", target.FileName()))
+	assert.FileExists(t, filepath.Join(cfg.MemosDir(), "work", renamed.FileName()))
+	content, err := os.ReadFile(filepath.Join(cfg.MemosDir(), source.FileName()))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "[target](work/"+renamed.FileName()+")")
+}
+
+// TestBrowseModel_SavedSearches tests that saved searches are listed as virtual
+// folders, searched when expanded
+func TestBrowseModel_SavedSearches(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg, err := toml.NewConfig(toml.Option{
+		BaseDir:         tempDir,
+		MemosFolderName: "memos/",
+		SavedSearches:   []config.SavedSearch{{Name: "deploys", Query: "deploy"}},
+	})
+	require.NoError(t, err)
+
+	logger := common.DefaultLogger()
+	repo := memo.NewMemo(cfg.MemosDir(), logger)
+	now := time.Now()
+	release, err := domain.NewMemoFile(now, "release", []string{"ops"})
+	require.NoError(t, err)
+	release.SetHeadingBlocks([]*markdown.HeadingBlock{
+		{Level: 2, HeadingText: "steps", ContentText: "run the deploy script\n"},
+	})
+	require.NoError(t, repo.Save(release, true))
+	todoName := now.Format(domain.FileNameDateLayoutTodo) + "_todos.md"
+	require.NoError(t, os.MkdirAll(cfg.TodosDir(), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(cfg.TodosDir(), todoName), []byte("## todos\n\n- [ ] fix the deploy script\n"), 0o644))
+
+	editor := &mock.MockEditor{}
+	model, err := New(cfg, editor)
+	require.NoError(t, err)
+
+	// The folder comes first and is searched only once expanded
+	folder := model.list.Items()[0].(item)
+	require.NotNil(t, folder.saved)
+	assert.Equal(t, "▶ ★ deploys", folder.Title())
+	assert.Empty(t, folder.children)
+
+	model.list.Select(0)
+	updated, _ := BrowseKeybindings(*model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'l'}})
+	*model = updated
+	folder = model.items[0]
+	require.NoError(t, folder.searchErr)
+	require.Len(t, folder.children, 2)
+
+	var memoItem, taskItem item
+	for _, child := range folder.children {
+		if child.task != nil {
+			taskItem = child
+		} else {
+			memoItem = child
+		}
+	}
+	require.NotNil(t, memoItem.memo)
+	assert.Equal(t, release.FileName(), memoItem.memo.FileName())
+	require.NotNil(t, taskItem.task)
+	assert.Equal(t, "    [ ] fix the deploy script", taskItem.Title())
+
+	// Tasks open in their todo file at the task
+	for i, li := range model.list.Items() {
+		if li.(item).task != nil {
+			model.list.Select(i)
+		}
+	}
+	updated, _ = BrowseKeybindings(*model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'l'}})
+	*model = updated
+	require.Len(t, editor.Calls, 1)
+	assert.Equal(t, filepath.Join(cfg.TodosDir(), todoName), editor.Calls[0].Path)
+	assert.Equal(t, 3, editor.Calls[0].Position.Line)
+
+	// Refreshing searches again
+	retro, err := domain.NewMemoFile(now.Add(time.Second), "deploy-retro", nil)
+	require.NoError(t, err)
+	require.NoError(t, repo.Save(retro, true))
+	_, err = model.updateItems()
+	require.NoError(t, err)
+	assert.Len(t, model.items[0].children, 3)
+}
+
+func TestBrowseModel_TagFilter(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg, err := toml.NewConfig(toml.Option{
+		BaseDir:         tempDir,
+		MemosFolderName: "memos/",
+	})
+	require.NoError(t, err)
+
+	logger := common.DefaultLogger()
+	repo := memo.NewMemo(cfg.MemosDir(), logger)
+	now := time.Now()
+	kickoff, err := domain.NewMemoFile(now, "kickoff", []string{"work", "acme"})
+	require.NoError(t, err)
+	kickoff.SetTags([]string{"client", "meeting"})
+	require.NoError(t, repo.Save(kickoff, true))
+	groceries, err := domain.NewMemoFile(now, "groceries", []string{"personal"})
+	require.NoError(t, err)
+	require.NoError(t, repo.Save(groceries, true))
+
+	model, err := New(cfg, &mock.MockEditor{})
+	require.NoError(t, err)
+
+	// The dialog lists every tag after the entry showing all memos
+	updated, _ := BrowseKeybindings(*model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'t'}})
+	*model = updated
+	require.True(t, model.showTagDialog)
+	var titles []string
+	for _, li := range model.tagList.Items() {
+		titles = append(titles, li.(tagItem).Title())
+	}
+	assert.Equal(t, []string{"(all memos)", "#client (1)", "#meeting (1)"}, titles)
+
+	// Filtering keeps the tagged memo, expanded into view, and drops the rest
+	model.tagList.Select(2)
+	result, _ := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	*model = result.(BrowseModel)
+	assert.False(t, model.showTagDialog)
+	assert.Equal(t, "meeting", model.tagFilter)
+	assert.Equal(t, "Memos Browser #meeting", model.list.Title)
+	var names []string
+	for _, li := range model.list.Items() {
+		names = append(names, li.(item).name)
+	}
+	assert.Equal(t, []string{"work", "acme", kickoff.FileName()}, names)
+
+	// The first entry clears the filter
+	updated, _ = BrowseKeybindings(*model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'t'}})
+	*model = updated
+	assert.Equal(t, 2, model.tagList.Index())
+	model.tagList.Select(0)
+	result, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	*model = result.(BrowseModel)
+	assert.Empty(t, model.tagFilter)
+	assert.Equal(t, "Memos Browser", model.list.Title)
+	assert.Len(t, model.items, 2)
+}
+
+func TestBrowseModel_SyncTitleAfterEdit(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg, err := toml.NewConfig(toml.Option{
+		BaseDir:         tempDir,
+		MemosFolderName: "memos/",
+		TitleSync:       config.TitleSyncPrompt,
+	})
+	require.NoError(t, err)
+
+	logger := common.DefaultLogger()
+	repo := memo.NewMemo(cfg.MemosDir(), logger)
+	draft, err := domain.NewMemoFile(time.Now(), "draft", []string{"work"})
+	require.NoError(t, err)
+	require.NoError(t, repo.Save(draft, true))
+
+	// The H1 is changed in the editor
+	path := filepath.Join(cfg.MemosDir(), draft.Location(), draft.FileName())
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, []byte(strings.Replace(string(b), "# draft", "# final: v2", 1)), 0o644))
+
+	model, err := New(cfg, &mock.MockEditor{})
+	require.NoError(t, err)
+
+	// The rename waits for confirmation
+	result, _ := model.syncTitleAfterEdit(path)
+	*model = result.(BrowseModel)
+	require.True(t, model.showRelinkDialog)
+	assert.Equal(t, "Retitle", model.relinkAction)
+	assert.Contains(t, model.renderRelinkDialog(), "Retitle Memo?")
+	assert.FileExists(t, path)
+
+	result, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	*model = result.(BrowseModel)
+	assert.False(t, model.showRelinkDialog)
+	assert.NoFileExists(t, path)
+	renamed, err := domain.NewMemoFile(draft.Date(), "final v2", nil)
+	require.NoError(t, err)
+	b, err = os.ReadFile(filepath.Join(cfg.MemosDir(), "work", renamed.FileName()))
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "# final v2\n")
+}
@@ -71,14 +71,18 @@ func (m *ExplorerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if keyMsg, ok := msg.(tea.KeyMsg); ok {
 		switch keyMsg.String() {
 		case "tab":
-			m.toggleMode()
+			if cmd := m.toggleMode(); cmd != nil {
+				cmds = append(cmds, cmd)
+			}
 		case "q", "ctrl+c":
 			return m, tea.Quit
 		}
 	}
 
-	// Update the current mode's model
-	if m.mode == BrowseMode {
+	// Update the current mode's model. Browse's link index may finish loading
+	// while searching.
+	_, linksLoaded := msg.(browse.LinksLoadedMsg)
+	if m.mode == BrowseMode || linksLoaded {
 		updatedModel, cmd := m.browseModel.Update(msg)
 		if browseModel, ok := updatedModel.(browse.BrowseModel); ok {
 			m.browseModel = browseModel
@@ -111,13 +115,15 @@ func (m *ExplorerModel) View() string {
 	return m.searchModel.View()
 }
 
-// toggleMode switches between browse and search modes
-func (m *ExplorerModel) toggleMode() {
+// toggleMode switches between browse and search modes. The search is limited
+// to the category selected in browse mode.
+func (m *ExplorerModel) toggleMode() tea.Cmd {
 	if m.mode == BrowseMode {
 		m.mode = SearchMode
-	} else {
-		m.mode = BrowseMode
+		return m.searchModel.SetScope(m.browseModel.SelectedCategory())
 	}
+	m.mode = BrowseMode
+	return nil
 }
 
 // CurrentMode returns the current mode
@@ -1,6 +1,8 @@
 package memos
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -514,3 +516,28 @@ func TestExplorerModel_NonKeyMessages(t *testing.T) {
 	updatedModel, _ = m.Update(errorMsg)
 	assert.NotNil(t, updatedModel)
 }
+
+// TestExplorerModel_SearchScope tests that switching to search limits it to the
+// category selected in browse mode
+func TestExplorerModel_SearchScope(t *testing.T) {
+	cfg, err := toml.NewConfig(toml.Option{
+		BaseDir:         t.TempDir(),
+		TodosFolderName: "todos/",
+		MemosFolderName: "memos/",
+	})
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(cfg.MemosDir(), "work", "projects"), 0o755))
+
+	m, err := NewIntegratedModel(cfg, &mock.MockEditor{})
+	require.NoError(t, err)
+
+	// The first item of the tree, the work directory, is selected
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	assert.NotNil(t, cmd)
+	assert.Equal(t, SearchMode, m.CurrentMode())
+	assert.Equal(t, []string{"work"}, m.searchModel.Scope())
+	assert.Contains(t, m.View(), "in work")
+
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlG})
+	assert.Nil(t, m.searchModel.Scope())
+}
@@ -2,47 +2,44 @@ package picker
 
 import (
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/hirotoni/memov2/internal/interfaces"
 	memsearch "github.com/hirotoni/memov2/internal/search"
 )
 
-// romajiMatcher returns a MatchFunc that expands each query word through the
-// SKK romaji converter, so romaji input (e.g. "kaigi") matches Japanese text
-// (e.g. "会議"). Every query word must match some variation.
-func romajiMatcher(rc *memsearch.RomajiConverter) MatchFunc {
-	return func(query string, item Item) bool {
-		if query == "" {
-			return true
-		}
-		text := strings.ToLower(item.FilterBy)
+// transliteratorMatcher returns a MatchFunc that expands each query word
+// through the search transliterators, so romaji input (e.g. "kaigi") matches
+// Japanese text (e.g. "会議"). Every query word must fuzzily match some
+// variation; the best variation of each word counts towards the score.
+func transliteratorMatcher(tr memsearch.Transliterator) MatchFunc {
+	return func(query string, item Item) (Match, bool) {
+		var match Match
 		for _, word := range strings.Fields(query) {
 			variations := []string{word}
-			if rc != nil {
-				variations = rc.Convert(word)
-			}
-			found := false
-			for _, v := range variations {
-				if strings.Contains(text, strings.ToLower(v)) {
-					found = true
-					break
-				}
+			if tr != nil {
+				variations = tr.Convert(word)
 			}
-			if !found {
-				return false
+			f, ok := memsearch.FuzzyMatchAny(variations, item.FilterBy)
+			if !ok {
+				return Match{}, false
 			}
+			match.Score += f.Score
+			match.Positions = append(match.Positions, f.Positions...)
 		}
-		return true
+		sort.Ints(match.Positions)
+		return match, true
 	}
 }
 
 // SelectMemoForRename shows a romaji-aware memo picker, then prompts for a new
-// title. It returns the selected memo's path (relative to the memos directory,
-// the same format the rename command accepts) and the entered title. ok is
-// false if the user cancelled.
-func SelectMemoForRename(repo interfaces.MemoRepo) (relPath string, newTitle string, ok bool, err error) {
-	entries, err := repo.MemoEntries()
+// title. tr expands what is typed into other scripts; nil matches it as typed.
+// It returns the selected memo's path (relative to the memos directory, the
+// same format the rename command accepts) and the entered title. ok is false
+// if the user cancelled.
+func SelectMemoForRename(repo interfaces.MemoRepo, tr memsearch.Transliterator) (relPath string, newTitle string, ok bool, err error) {
+	entries, err := repo.MemoHeaders()
 	if err != nil {
 		return "", "", false, err
 	}
@@ -58,13 +55,10 @@ func SelectMemoForRename(repo interfaces.MemoRepo) (relPath string, newTitle str
 		})
 	}
 
-	// Romaji converter is best-effort; on failure fall back to plain substring.
-	rc, _ := memsearch.NewRomajiConverter()
-
 	res, err := Run(Config{
 		Title:       "Rename memo — pick a file",
 		Items:       items,
-		Match:       romajiMatcher(rc),
+		Match:       transliteratorMatcher(tr),
 		WithInput:   true,
 		InputPrompt: "New title: ",
 	})
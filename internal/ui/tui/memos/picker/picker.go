@@ -12,10 +12,14 @@ package picker
 
 import (
 	"os"
+	"sort"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	memsearch "github.com/hirotoni/memov2/internal/search"
 	"github.com/hirotoni/memov2/internal/ui/tui/styles"
 	"golang.org/x/term"
 )
@@ -32,14 +36,24 @@ type Item struct {
 	Payload any
 }
 
-// MatchFunc reports whether item matches query. An empty query should match all.
-type MatchFunc func(query string, item Item) bool
+// Match describes how an item matched a query.
+type Match struct {
+	// Score ranks the item; better matches are listed first.
+	Score int
+	// Positions are the byte offsets of the matched characters in FilterBy,
+	// highlighted in the list.
+	Positions []int
+}
+
+// MatchFunc reports whether item matches query, and how. An empty query should
+// match all.
+type MatchFunc func(query string, item Item) (Match, bool)
 
 // Config configures a picker run.
 type Config struct {
 	Title       string    // header shown above the filter input
 	Items       []Item    // candidates
-	Match       MatchFunc // filter predicate; nil => case-insensitive substring on FilterBy
+	Match       MatchFunc // filter predicate; nil => fuzzy match on FilterBy
 	WithInput   bool      // if true, a text-input phase follows selection
 	InputPrompt string    // label for the input phase (e.g. "New title: ")
 
@@ -70,7 +84,8 @@ const (
 // the synthetic free-text row.
 type row struct {
 	freeText  bool
-	itemIndex int // index into cfg.Items, valid when !freeText
+	itemIndex int   // index into cfg.Items, valid when !freeText
+	match     Match // how the item matched the query, valid when !freeText
 }
 
 // Model is the picker Bubbletea model. After Run, read Result.
@@ -93,17 +108,11 @@ type Model struct {
 	cancelled bool
 }
 
-func defaultMatch(query string, item Item) bool {
-	if query == "" {
-		return true
-	}
-	text := strings.ToLower(item.FilterBy)
-	for _, word := range strings.Fields(query) {
-		if !strings.Contains(text, strings.ToLower(word)) {
-			return false
-		}
-	}
-	return true
+// defaultMatch fuzzily matches every query word against FilterBy: as a
+// substring, as an abbreviation ("mtng" for "meeting") or with a typo.
+func defaultMatch(query string, item Item) (Match, bool) {
+	f, ok := memsearch.FuzzyMatch(query, item.FilterBy)
+	return Match{Score: f.Score, Positions: f.Positions}, ok
 }
 
 // New builds a picker model from cfg.
@@ -140,21 +149,27 @@ func New(cfg Config) *Model {
 	return m
 }
 
-// recompute rebuilds the visible rows for the current query and clamps the
-// selection. When free text is offered, its row is appended at the bottom.
+// recompute rebuilds the visible rows for the current query, best matches
+// first, and clamps the selection. When free text is offered, its row is
+// appended at the bottom.
 func (m *Model) recompute() {
 	q := m.filterInput.Value()
 	m.rows = m.rows[:0]
 
 	exactMatch := false
 	for i, it := range m.cfg.Items {
-		if m.match(q, it) {
-			m.rows = append(m.rows, row{itemIndex: i})
+		if match, ok := m.match(q, it); ok {
+			m.rows = append(m.rows, row{itemIndex: i, match: match})
 		}
 		if it.Display == strings.TrimSpace(q) {
 			exactMatch = true
 		}
 	}
+	if strings.TrimSpace(q) != "" {
+		sort.SliceStable(m.rows, func(i, j int) bool {
+			return m.rows[i].match.Score > m.rows[j].match.Score
+		})
+	}
 
 	if m.cfg.AllowFreeText && strings.TrimSpace(q) != "" && !exactMatch {
 		m.rows = append(m.rows, row{freeText: true})
@@ -295,10 +310,11 @@ func (m *Model) viewFilter() string {
 	for i := start; i < end; i++ {
 		r := m.rows[i]
 		var display, secondary string
+		var it Item
 		if r.freeText {
 			display = m.freeTextLabel()
 		} else {
-			it := m.cfg.Items[r.itemIndex]
+			it = m.cfg.Items[r.itemIndex]
 			display, secondary = it.Display, it.Secondary
 		}
 
@@ -308,10 +324,13 @@ func (m *Model) viewFilter() string {
 				text += "  " + secondary
 			}
 			b.WriteString(styles.SelectedBar(m.width, text) + "\n")
+		} else if r.freeText {
+			b.WriteString("  " + display + "\n")
 		} else {
-			line := "  " + display
+			displayPos, secondaryPos := splitPositions(it, r.match.Positions)
+			line := "  " + highlight(display, displayPos, lipgloss.NewStyle())
 			if secondary != "" {
-				line += "  " + styles.Dim.Render(secondary)
+				line += "  " + highlight(secondary, secondaryPos, styles.Dim)
 			}
 			b.WriteString(line + "\n")
 		}
@@ -324,6 +343,57 @@ func (m *Model) viewFilter() string {
 	return b.String()
 }
 
+// splitPositions maps matched positions in FilterBy to positions in Display and
+// Secondary, for the usual FilterBy of Display, or Display and Secondary joined
+// by a space. Other FilterBy texts are not highlighted.
+func splitPositions(it Item, positions []int) (display, secondary []int) {
+	switch it.FilterBy {
+	case it.Display:
+		return positions, nil
+	case it.Display + " " + it.Secondary:
+		offset := len(it.Display) + 1
+		for _, p := range positions {
+			if p < len(it.Display) {
+				display = append(display, p)
+			} else if p >= offset {
+				secondary = append(secondary, p-offset)
+			}
+		}
+		return display, secondary
+	}
+	return nil, nil
+}
+
+// highlight renders text in style with the characters at positions in the
+// match style
+func highlight(text string, positions []int, style lipgloss.Style) string {
+	if len(positions) == 0 {
+		return style.Render(text)
+	}
+	matched := make(map[int]bool, len(positions))
+	for _, p := range positions {
+		matched[p] = true
+	}
+
+	var b strings.Builder
+	start := 0
+	for start < len(text) {
+		isMatch := matched[start]
+		end := start
+		for end < len(text) && matched[end] == isMatch {
+			_, size := utf8.DecodeRuneInString(text[end:])
+			end += size
+		}
+		if isMatch {
+			b.WriteString(styles.Match.Render(text[start:end]))
+		} else {
+			b.WriteString(style.Render(text[start:end]))
+		}
+		start = end
+	}
+	return b.String()
+}
+
 func (m *Model) viewInput() string {
 	var b strings.Builder
 	if m.cfg.Title != "" {
@@ -164,16 +164,64 @@ func TestFreeTextDisabledByDefault(t *testing.T) {
 	}
 }
 
-func TestRomajiMatcherFallbackSubstring(t *testing.T) {
-	match := romajiMatcher(nil) // nil converter => plain per-word substring
+func TestTransliteratorMatcherFallback(t *testing.T) {
+	match := transliteratorMatcher(nil) // nil transliterator => per-word fuzzy match as typed
 	it := Item{FilterBy: "Weekly Meeting Notes"}
-	if !match("meeting notes", it) {
+	if _, ok := match("meeting notes", it); !ok {
 		t.Error("expected multi-word substring match")
 	}
-	if match("absent", it) {
+	if _, ok := match("mtng nts", it); !ok {
+		t.Error("expected abbreviated words to match")
+	}
+	if _, ok := match("absent", it); ok {
 		t.Error("did not expect a match for absent word")
 	}
-	if !match("", it) {
+	if _, ok := match("", it); !ok {
 		t.Error("empty query should match")
 	}
 }
+
+func TestFuzzyFilterRanksBestMatchFirst(t *testing.T) {
+	items := []Item{
+		{Display: "monthly report", FilterBy: "monthly report", Payload: "report"},
+		{Display: "meeting notes", FilterBy: "meeting notes", Payload: "notes"},
+		{Display: "mtg", FilterBy: "mtg", Payload: "mtg"},
+	}
+	m := New(Config{Items: items})
+
+	m = send(m, runes("mtng nts"))
+	if len(m.rows) != 1 || m.cfg.Items[m.rows[0].itemIndex].Payload != "notes" {
+		t.Fatalf("expected only 'meeting notes' for 'mtng nts', got %+v", m.rows)
+	}
+
+	m = New(Config{Items: items})
+	m = send(m, runes("mtg"))
+	if len(m.rows) != 2 {
+		t.Fatalf("expected 2 rows for 'mtg', got %+v", m.rows)
+	}
+	if got := m.cfg.Items[m.rows[0].itemIndex].Payload; got != "mtg" {
+		t.Errorf("expected the exact match first, got %v", got)
+	}
+
+	m = New(Config{Items: items})
+	m = send(m, runes("meetnig"))
+	if len(m.rows) != 1 || m.cfg.Items[m.rows[0].itemIndex].Payload != "notes" {
+		t.Fatalf("expected a typo to match 'meeting notes', got %+v", m.rows)
+	}
+}
+
+func TestSplitPositions(t *testing.T) {
+	it := Item{Display: "Notes", Secondary: "work/notes.md", FilterBy: "Notes work/notes.md"}
+	display, secondary := splitPositions(it, []int{0, 1, 6, 7})
+	if len(display) != 2 || display[0] != 0 || display[1] != 1 {
+		t.Errorf("display positions = %v, want [0 1]", display)
+	}
+	if len(secondary) != 2 || secondary[0] != 0 || secondary[1] != 1 {
+		t.Errorf("secondary positions = %v, want [0 1]", secondary)
+	}
+
+	other := Item{Display: "(no category)", FilterBy: "no category"}
+	if d, s := splitPositions(other, []int{0}); d != nil || s != nil {
+		t.Errorf("expected no positions for an unrelated FilterBy, got %v %v", d, s)
+	}
+}
@@ -0,0 +1,69 @@
+package picker
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hirotoni/memov2/internal/interfaces"
+)
+
+// SelectTagsForNew shows a tag picker, once per tag, until the user chooses to
+// stop. Existing tags are listed most used first, and a typed tag not in the
+// list can be created. It returns the chosen tags, empty for none. ok is false
+// if cancelled.
+func SelectTagsForNew(repo interfaces.MemoRepo) (tags []string, ok bool, err error) {
+	existing, err := repo.Tags()
+	if err != nil {
+		return nil, false, err
+	}
+
+	tags = []string{}
+	for {
+		title := "New memo — add tags"
+		stop := "(no tags)"
+		if len(tags) > 0 {
+			title += " (#" + strings.Join(tags, " #") + ")"
+			stop = "(done)"
+		}
+
+		items := []Item{{Display: stop, FilterBy: stop}}
+		for _, tc := range existing {
+			if slices.Contains(tags, tc.Tag) {
+				continue
+			}
+			items = append(items, Item{
+				Display:   tc.Tag,
+				Secondary: strconv.Itoa(tc.Count),
+				FilterBy:  tc.Tag,
+				Payload:   tc.Tag,
+			})
+		}
+
+		res, err := Run(Config{
+			Title:         title,
+			Items:         items,
+			AllowFreeText: true,
+			FreeTextLabel: func(q string) string { return "+ new tag \"" + q + "\"" },
+		})
+		if err != nil {
+			return nil, false, err
+		}
+		if res.Cancelled {
+			return nil, false, nil
+		}
+
+		var tag string
+		switch {
+		case res.FreeText != "":
+			tag = strings.TrimPrefix(strings.TrimSpace(res.FreeText), "#")
+		case res.Item != nil && res.Item.Payload != nil:
+			tag = res.Item.Payload.(string)
+		default:
+			return tags, true, nil
+		}
+		if tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+}
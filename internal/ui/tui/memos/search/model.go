@@ -1,11 +1,14 @@
 package search
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
@@ -13,6 +16,7 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/hirotoni/memov2/internal/common"
 	"github.com/hirotoni/memov2/internal/config/toml"
+	"github.com/hirotoni/memov2/internal/domain"
 	"github.com/hirotoni/memov2/internal/interfaces"
 	"github.com/hirotoni/memov2/internal/repositories/memo"
 	memsearch "github.com/hirotoni/memov2/internal/search"
@@ -27,6 +31,14 @@ const (
 	focusList
 )
 
+const (
+	// searchDebounce is how long the query must stay unchanged before it is searched
+	searchDebounce = 80 * time.Millisecond
+	// indexMaxAge is how long the index snapshot is searched before the memo
+	// files are checked for changes again
+	indexMaxAge = 2 * time.Second
+)
+
 type Model struct {
 	searchInput textinput.Model
 	viewport    viewport.Model
@@ -37,20 +49,37 @@ type Model struct {
 	width       int
 	height      int
 	focus       focusState
-	romajiConv  *memsearch.RomajiConverter
+	translit    memsearch.Transliterator
+	index       *memsearch.Index
 	lastKeyG    bool // Track if last key was 'g' for 'gg' command
 	err         error
+	searchErr   error // error of the last search, shown instead of the results
+	order       memsearch.Order
+	scope       []string // category the search is limited to, nil for the whole vault
 
 	// quitOnSelect makes the model quit and report the chosen file via
 	// selectedPath instead of opening it inline. Used by the standalone
 	// `alt search` command so control returns to the shell after editing.
 	quitOnSelect bool
 	selectedPath string
+	selectedPos  interfaces.Position
 
 	// lastQuery is the query that produced the current results. Search is only
 	// re-run when the query actually changes, so non-input messages (e.g. cursor
 	// blink) don't reset the selection.
 	lastQuery string
+
+	// generation counts the queries typed. Searches run in the background and
+	// their results are only shown if no newer query was typed meanwhile.
+	generation   int
+	cancelSearch context.CancelFunc // cancels the search in flight, if any
+
+	// history holds the queries whose results were opened. up/down recall
+	// them: historyPos is the query recalled, len(history.Queries()) if none,
+	// and historyDraft what was typed before the first recall.
+	history      *memsearch.History
+	historyPos   int
+	historyDraft string
 }
 
 // Styles are sourced from the shared palette (internal/ui/tui/styles) so the
@@ -73,17 +102,23 @@ func New(c *toml.Config, e interfaces.Editor) (*Model, error) {
 	}
 
 	ti := textinput.New()
-	ti.Placeholder = "Search memos..."
+	ti.Placeholder = "Search memos and todos..."
 	ti.Focus()
 	ti.Width = w
 
 	vp := viewport.New(w, h-6)
 	vp.Style = unfocusedStyle
 
-	// Initialize romaji converter
-	rc, err := memsearch.NewRomajiConverter()
+	// Initialize the transliterators expanding search words, romaji by default
+	tr, err := memsearch.NewTransliterator(c.SearchTransliterators(), c.SearchDictionaries())
 	if err != nil {
-		return nil, fmt.Errorf("failed to load SKK dictionary: %w", err)
+		return nil, fmt.Errorf("failed to load transliterators: %w", err)
+	}
+
+	history, err := memsearch.LoadHistory(c.SearchHistory())
+	if err != nil {
+		common.DefaultLogger().Warn("Failed to load search history", "error", err)
+		history, _ = memsearch.LoadHistory("")
 	}
 
 	m := &Model{
@@ -94,13 +129,25 @@ func New(c *toml.Config, e interfaces.Editor) (*Model, error) {
 		results:     []memsearch.SearchResult{},
 		selected:    0,
 		focus:       focusInput,
-		romajiConv:  rc,
+		translit:    tr,
+		index:       newIndex(c),
+		order:       memsearch.OrderRelevance,
 		lastKeyG:    false,
+		history:     history,
+		historyPos:  len(history.Queries()),
 	}
 
 	return m, nil
 }
 
+// newIndex returns the persistent search index over the memos and todos of c
+func newIndex(c *toml.Config) *memsearch.Index {
+	logger := common.DefaultLogger()
+	return memsearch.NewIndex(memsearch.IndexPath(c.BaseDir()), c.MemosDir(), func(path string, info os.FileInfo) (domain.MemoFileInterface, error) {
+		return memo.ParseMemoFile(path, info, logger)
+	}).WithTodos(c.TodosDir())
+}
+
 // NewStandalone builds a search model that quits and reports the selected file
 // (via SelectedPath) instead of opening it inline. The caller opens the file
 // after the program exits, so the terminal is restored and control returns to
@@ -119,23 +166,127 @@ func (m Model) SelectedPath() string {
 	return m.selectedPath
 }
 
+// SelectedPosition returns the position of the chosen match in SelectedPath.
+func (m Model) SelectedPosition() interfaces.Position {
+	return m.selectedPos
+}
+
+// matchPosition returns the position in the file to open result at: the first
+// match in the body or a heading, otherwise the first match. A task opens at
+// its line.
+func (m *Model) matchPosition(result memsearch.SearchResult) interfaces.Position {
+	if len(result.Matches) == 0 {
+		if result.Task != nil {
+			return interfaces.Position{Line: result.Task.Line + 1, Column: result.Task.Column + 1}
+		}
+		return interfaces.Position{}
+	}
+	match := result.Matches[0]
+	for _, mt := range result.Matches {
+		if mt.Type == memsearch.MatchContent || mt.Type == memsearch.MatchHeading || mt.Type == memsearch.MatchTask {
+			match = mt
+			break
+		}
+	}
+	line, column := result.Position(match, m.getQueryVariations())
+	return interfaces.Position{Line: line, Column: column}
+}
+
+// resultPath returns the path of the memo or todo file of result
+func (m Model) resultPath(result memsearch.SearchResult) string {
+	if result.Task != nil {
+		return filepath.Join(m.config.TodosDir(), result.Task.Path)
+	}
+	return filepath.Join(m.config.MemosDir(), result.Path())
+}
+
 // openOrSelect either records the selection and quits (standalone mode) or opens
 // the file inline (integrated browse mode).
 func (m Model) openOrSelect(result memsearch.SearchResult) (tea.Model, tea.Cmd) {
-	filePath := filepath.Join(m.config.MemosDir(), result.Memo.Location(), result.Memo.FileName())
+	filePath := m.resultPath(result)
+	pos := m.matchPosition(result)
+	if err := m.history.Add(m.searchInput.Value()); err != nil {
+		common.DefaultLogger().Warn("Failed to save search history", "error", err)
+	}
+	m.historyPos = len(m.history.Queries())
 	if m.quitOnSelect {
 		m.selectedPath = filePath
+		m.selectedPos = pos
 		return m, tea.Quit
 	}
-	if err := m.editor.Open(m.config.BaseDir(), filePath); err != nil {
+	if err := m.editor.OpenAt(m.config.BaseDir(), filePath, pos); err != nil {
 		m.err = fmt.Errorf("failed to open editor: %w", err)
 	}
 	return m, nil
 }
 
+// SetScope limits the search to the memos in category and its subcategories,
+// nil for the whole vault, and searches the query again
+func (m *Model) SetScope(category []string) tea.Cmd {
+	m.scope = category
+	var cmd tea.Cmd
+	*m, cmd = m.scheduleSearch()
+	return cmd
+}
+
+// Scope returns the category the search is limited to, nil for the whole vault
+func (m Model) Scope() []string {
+	return m.scope
+}
+
+// widenScope limits the search to the parent of its category, or to the whole
+// vault if all is set
+func (m Model) widenScope(all bool) (tea.Model, tea.Cmd) {
+	if len(m.scope) == 0 {
+		return m, nil
+	}
+	scope := m.scope[:len(m.scope)-1]
+	if all || len(scope) == 0 {
+		scope = nil
+	}
+	cmd := m.SetScope(scope)
+	return m, cmd
+}
+
+// recalling reports whether the query is one recalled from the history and
+// not edited since
+func (m Model) recalling() bool {
+	queries := m.history.Queries()
+	return m.historyPos < len(queries) && m.searchInput.Value() == queries[m.historyPos]
+}
+
+// recallHistory replaces the query with the one step entries away in the
+// history: -1 for the older one, 1 for the newer. Past the newest, what was
+// typed before the recall is restored.
+func (m Model) recallHistory(step int) (tea.Model, tea.Cmd) {
+	queries := m.history.Queries()
+	if !m.recalling() {
+		m.historyPos = len(queries)
+		m.historyDraft = m.searchInput.Value()
+	}
+	pos := m.historyPos + step
+	if pos < 0 || pos > len(queries) {
+		return m, nil
+	}
+	m.historyPos = pos
+
+	value := m.historyDraft
+	if pos < len(queries) {
+		value = queries[pos]
+	}
+	m.searchInput.SetValue(value)
+	m.searchInput.CursorEnd()
+	if value == m.lastQuery {
+		return m, nil
+	}
+	m.lastQuery = value
+	return m.scheduleSearch()
+}
+
 // updateStandalone implements the fzf-style interaction used by `alt search`:
 // focus stays in the search input, ctrl+n/ctrl+p (or arrows) move the highlight
-// in the result list, and enter opens the highlighted file directly.
+// in the result list, and enter opens the highlighted file directly. With the
+// input empty or a query recalled, up/down recall the history instead.
 func (m Model) updateStandalone(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	moveTo := func(i int) (tea.Model, tea.Cmd) {
 		m.selected = i
@@ -148,12 +299,24 @@ func (m Model) updateStandalone(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "ctrl+c", "esc":
 		return m, tea.Quit
+	case "ctrl+o":
+		return m.toggleOrder()
+	case "ctrl+g":
+		return m.widenScope(false)
+	case "ctrl+x":
+		return m.widenScope(true)
 	case "ctrl+p", "up":
+		if msg.String() == "up" && (m.searchInput.Value() == "" || m.recalling()) {
+			return m.recallHistory(-1)
+		}
 		if m.selected > 0 {
 			return moveTo(m.selected - 1)
 		}
 		return m, nil
 	case "ctrl+n", "down":
+		if msg.String() == "down" && m.recalling() {
+			return m.recallHistory(1)
+		}
 		if m.selected < len(m.results)-1 {
 			return moveTo(m.selected + 1)
 		}
@@ -171,13 +334,58 @@ func (m Model) updateStandalone(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	m.searchInput, tiCmd = m.searchInput.Update(msg)
 	if m.searchInput.Value() != m.lastQuery {
 		m.lastQuery = m.searchInput.Value()
-		return m, tea.Batch(tiCmd, m.search)
+		var searchCmd tea.Cmd
+		m, searchCmd = m.scheduleSearch()
+		return m, tea.Batch(tiCmd, searchCmd)
 	}
 	return m, tiCmd
 }
 
+// toggleOrder switches the results between relevance and date order
+func (m Model) toggleOrder() (tea.Model, tea.Cmd) {
+	m.order = m.order.Next()
+	memsearch.SortResults(m.results, m.order)
+	m.selected = 0
+	m.viewport.SetContent(m.renderResults())
+	m.viewport.GotoTop()
+	return m, nil
+}
+
 func (m Model) Init() tea.Cmd {
-	return textinput.Blink
+	return tea.Batch(textinput.Blink, m.loadIndex)
+}
+
+type indexLoadedMsg struct {
+	err error
+}
+
+// loadIndex brings the index snapshot up to date in the background, so the
+// first search does not wait for it
+func (m Model) loadIndex() tea.Msg {
+	return indexLoadedMsg{err: m.index.Refresh()}
+}
+
+// scheduleSearch starts a new query generation and searches it once the input
+// settles. The search in flight, now obsolete, is cancelled.
+func (m Model) scheduleSearch() (Model, tea.Cmd) {
+	m.generation++
+	if m.cancelSearch != nil {
+		m.cancelSearch()
+		m.cancelSearch = nil
+	}
+	generation := m.generation
+	return m, tea.Tick(searchDebounce, func(time.Time) tea.Msg {
+		return searchDebounceMsg{generation: generation}
+	})
+}
+
+// startSearch runs the current query in the background
+func (m Model) startSearch() (Model, tea.Cmd) {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelSearch = cancel
+	return m, func() tea.Msg {
+		return m.search(ctx)
+	}
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -194,6 +402,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		switch msg.String() {
 		case "ctrl+c":
 			return m, tea.Quit
+		case "ctrl+o":
+			return m.toggleOrder()
+		case "ctrl+g":
+			return m.widenScope(false)
+		case "ctrl+x":
+			return m.widenScope(true)
 		case "esc":
 			// Esc key behavior depends on focus state
 			if m.focus == focusList {
@@ -239,6 +453,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.focus == focusList && m.selected < len(m.results) {
 				return m.openOrSelect(m.results[m.selected])
 			}
+		case "up":
+			if m.focus == focusInput {
+				return m.recallHistory(-1)
+			}
+		case "down":
+			if m.focus == focusInput {
+				return m.recallHistory(1)
+			}
 		}
 
 		// Handle navigation only when list is focused
@@ -318,8 +540,30 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.scrollToSelected(content)
 		}
 
+	case indexLoadedMsg:
+		if msg.err != nil {
+			m.searchErr = msg.err
+			m.viewport.SetContent(m.renderResults())
+		}
+		return m, nil
+
+	case searchDebounceMsg:
+		if msg.generation != m.generation {
+			return m, nil // the query changed again
+		}
+		return m.startSearch()
+
 	case searchResultMsg:
+		if msg.generation != m.generation {
+			return m, nil // results of an older query
+		}
+		m.cancelSearch = nil
+		if msg.order != m.order {
+			// The order was toggled while searching
+			memsearch.SortResults(msg.results, m.order)
+		}
 		m.results = msg.results
+		m.searchErr = msg.err
 		m.selected = 0
 		content := m.renderResults()
 		m.viewport.SetContent(content)
@@ -333,7 +577,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// messages (e.g. cursor blink) don't reset the result selection.
 		if m.searchInput.Value() != m.lastQuery {
 			m.lastQuery = m.searchInput.Value()
-			return m, tea.Batch(tiCmd, m.search)
+			var searchCmd tea.Cmd
+			m, searchCmd = m.scheduleSearch()
+			return m, tea.Batch(tiCmd, searchCmd)
 		}
 		return m, tiCmd
 	}
@@ -350,7 +596,13 @@ func (m Model) View() string {
 	if m.focus == focusInput {
 		inputStyle = focusedStyle
 	}
-	s.WriteString("\n" + inputStyle.Render(m.searchInput.View()) + "\n\n")
+	s.WriteString("\n" + inputStyle.Render(m.searchInput.View()) + "\n")
+
+	// Scope indicator, on the line otherwise left blank
+	if len(m.scope) > 0 {
+		s.WriteString(dimStyle.Render("  in " + strings.Join(m.scope, " › ") + " | ctrl+g: parent category | ctrl+x: whole vault"))
+	}
+	s.WriteString("\n")
 
 	// Results viewport
 	s.WriteString(m.viewport.View())
@@ -358,9 +610,9 @@ func (m Model) View() string {
 	// Help
 	var help string
 	if m.quitOnSelect {
-		help = "\n  type to search | ctrl+n/ctrl+p (↓/↑): move | enter: open | esc: quit\n"
+		help = "\n  type to search | ctrl+n/ctrl+p (↓/↑): move | ↑/↓ on an empty query: history | enter: open | ctrl+o: sort by relevance/date | esc: quit\n"
 	} else if m.focus == focusInput {
-		help = "\n  tab/ctrl+j: switch to list | type to search | esc: quit\n"
+		help = "\n  tab/ctrl+j: switch to list | type to search | ↑/↓: history | ctrl+o: sort by relevance/date | esc: quit\n"
 	} else {
 		help = "\n  ↑/k,↓/j: navigate | ctrl+u/ctrl+d: move by 5 | gg/G: top/bottom | enter/l: open | tab/ctrl+k/esc: switch to search | ctrl+c: quit\n"
 	}
@@ -369,163 +621,47 @@ func (m Model) View() string {
 	return s.String()
 }
 
+// searchDebounceMsg fires when the query of generation has been left alone
+// for searchDebounce
+type searchDebounceMsg struct {
+	generation int
+}
+
 type searchResultMsg struct {
-	results []memsearch.SearchResult
+	generation int // of the query searched
+	order      memsearch.Order
+	results    []memsearch.SearchResult
+	err        error // the query could not be parsed or searched
 }
 
-func (m *Model) search() tea.Msg {
+// search runs the query of m against the index snapshot, refreshing it first
+// if it is older than indexMaxAge. It runs off the UI goroutine on a copy of
+// the model, and gives up once ctx is cancelled.
+func (m Model) search(ctx context.Context) tea.Msg {
+	msg := searchResultMsg{generation: m.generation, order: m.order, results: []memsearch.SearchResult{}}
 	query := m.searchInput.Value()
 	if query == "" {
-		return searchResultMsg{results: []memsearch.SearchResult{}}
+		return msg
 	}
 
-	logger := common.DefaultLogger()
-	memos, err := memo.NewMemo(m.config.MemosDir(), logger).MemoEntries()
+	q, err := memsearch.ParseQuery(query, m.translit)
 	if err != nil {
-		return searchResultMsg{results: []memsearch.SearchResult{}}
+		msg.err = err
+		return msg
 	}
+	q = q.Within(m.scope)
 
-	// Map to store results by file path to merge matches from same file
-	resultsByFile := make(map[string]*memsearch.SearchResult)
-
-	// Split query into words and convert each word
-	queryWords := strings.Fields(query)
-	wordQueries := make([][]string, len(queryWords))
-
-	// Convert each word and collect all possible variations
-	for i, word := range queryWords {
-		if m.romajiConv != nil {
-			wordQueries[i] = m.romajiConv.Convert(word)
-		} else {
-			wordQueries[i] = []string{word}
-		}
+	if err := m.index.RefreshStale(indexMaxAge); err != nil {
+		msg.err = err
+		return msg
 	}
-
-	// Search with all query candidates
-	for _, memo := range memos {
-		filePath := filepath.Join(memo.Location(), memo.FileName())
-
-		// Initialize result for this file
-		result := &memsearch.SearchResult{
-			Memo:    memo,
-			Matches: []memsearch.Match{},
-		}
-
-		// Track matches by type
-		matchesByType := make(map[memsearch.MatchType][]memsearch.Match)
-
-		// For each word in the query
-		for _, wordVariations := range wordQueries {
-			// Try each variation of the current word
-			for _, q := range wordVariations {
-				// Search by title
-				if matches := memsearch.SearchMemo(memo, q, memsearch.MatchTitle); len(matches.Matches) > 0 {
-					matchesByType[memsearch.MatchTitle] = append(matchesByType[memsearch.MatchTitle], matches.Matches...)
-				}
-
-				// Search by category
-				if matches := memsearch.SearchMemo(memo, q, memsearch.MatchCategory); len(matches.Matches) > 0 {
-					matchesByType[memsearch.MatchCategory] = append(matchesByType[memsearch.MatchCategory], matches.Matches...)
-				}
-
-				// Search by heading
-				if matches := memsearch.SearchMemo(memo, q, memsearch.MatchHeading); len(matches.Matches) > 0 {
-					matchesByType[memsearch.MatchHeading] = append(matchesByType[memsearch.MatchHeading], matches.Matches...)
-				}
-
-				// Search by content
-				if matches := memsearch.SearchMemo(memo, q, memsearch.MatchContent); len(matches.Matches) > 0 {
-					matchesByType[memsearch.MatchContent] = append(matchesByType[memsearch.MatchContent], matches.Matches...)
-				}
-			}
-		}
-
-		// Check each match type independently
-		var validMatches []memsearch.Match
-		hasValidMatches := false
-
-		// Helper function to check if all query words appear in a text
-		containsAllWords := func(text string) bool {
-			textLower := strings.ToLower(text)
-			for _, wordVariations := range wordQueries {
-				wordFound := false
-				for _, word := range wordVariations {
-					if strings.Contains(textLower, strings.ToLower(word)) {
-						wordFound = true
-						break
-					}
-				}
-				if !wordFound {
-					return false
-				}
-			}
-			return true
-		}
-
-		// Process each match type
-		for _, matches := range matchesByType {
-			// Group matches by their unique content
-			uniqueMatches := make(map[string]memsearch.Match)
-			for _, match := range matches {
-				uniqueMatches[match.Content] = match
-			}
-
-			// Check each unique match
-			for _, match := range uniqueMatches {
-				if containsAllWords(match.Content) {
-					validMatches = append(validMatches, match)
-					hasValidMatches = true
-				}
-			}
-		}
-
-		// Only keep results that have valid matches
-		if hasValidMatches {
-			result.Matches = validMatches
-			resultsByFile[filePath] = result
-		}
-	}
-
-	// Convert map to slice and sort results
-	var allResults []memsearch.SearchResult
-	for _, result := range resultsByFile {
-		// Sort matches within each result by type and position
-		sort.Slice(result.Matches, func(i, j int) bool {
-			// First sort by match type
-			if result.Matches[i].Type != result.Matches[j].Type {
-				return result.Matches[i].Type < result.Matches[j].Type
-			}
-
-			// Then sort by heading order for all match types
-			if result.Matches[i].HeadingOrder != result.Matches[j].HeadingOrder {
-				return result.Matches[i].HeadingOrder < result.Matches[j].HeadingOrder
-			}
-
-			// For content matches, sort by line number within heading
-			if result.Matches[i].Type == memsearch.MatchContent {
-				return result.Matches[i].Line < result.Matches[j].Line
-			}
-
-			// For heading matches at the same position (should be rare), sort by content for stability
-			return result.Matches[i].Content < result.Matches[j].Content
-		})
-		allResults = append(allResults, *result)
+	results, err := m.index.SearchContext(ctx, q, m.order)
+	if err != nil {
+		msg.err = err
+		return msg
 	}
-
-	// Sort results by date (newest first) and then by file path for stability
-	sort.Slice(allResults, func(i, j int) bool {
-		dateI := allResults[i].Memo.Date()
-		dateJ := allResults[j].Memo.Date()
-		if !dateI.Equal(dateJ) {
-			return dateI.After(dateJ)
-		}
-		// If dates are equal, sort by file path for stability
-		pathI := filepath.Join(allResults[i].Memo.Location(), allResults[i].Memo.FileName())
-		pathJ := filepath.Join(allResults[j].Memo.Location(), allResults[j].Memo.FileName())
-		return pathI < pathJ
-	})
-
-	return searchResultMsg{results: allResults}
+	msg.results = results
+	return msg
 }
 
 // matchInfo stores information about where matches occur in text
@@ -534,31 +670,17 @@ type matchInfo struct {
 	end   int
 }
 
-// getQueryVariations returns all variations of the search query words
+// getQueryVariations returns all variations of the search query terms
 func (m *Model) getQueryVariations() []string {
 	if m.searchInput.Value() == "" {
 		return nil
 	}
 
-	queryWords := strings.Fields(m.searchInput.Value())
-	var allQueries []string
-	seen := make(map[string]bool) // For deduplication
-
-	for _, word := range queryWords {
-		if m.romajiConv != nil {
-			variations := m.romajiConv.Convert(word)
-			for _, v := range variations {
-				if !seen[v] {
-					allQueries = append(allQueries, v)
-					seen[v] = true
-				}
-			}
-		} else if !seen[word] {
-			allQueries = append(allQueries, word)
-			seen[word] = true
-		}
+	q, err := memsearch.ParseQuery(m.searchInput.Value(), m.translit)
+	if err != nil {
+		return nil
 	}
-	return allQueries
+	return q.Terms()
 }
 
 // findAllMatches finds all match positions in the text for given queries
@@ -623,8 +745,38 @@ func (m *Model) highlightMatches(text string, queryVariations []string, style li
 		return style.Render(text)
 	}
 
-	// Find all matches
-	matches := findAllMatches(text, queryVariations)
+	return renderHighlights(text, findAllMatches(text, queryVariations), style)
+}
+
+// highlightMatch highlights the matched portions of the content of match: the
+// matched characters of a fuzzy match, otherwise the query variations
+func (m *Model) highlightMatch(match memsearch.Match, queryVariations []string, style lipgloss.Style) string {
+	if len(match.Positions) > 0 {
+		return renderHighlights(match.Content, positionMatches(match.Content, match.Positions), style)
+	}
+	return m.highlightMatches(match.Content, queryVariations, style)
+}
+
+// positionMatches turns the byte offsets of matched characters into ranges,
+// merging adjacent characters
+func positionMatches(text string, positions []int) []matchInfo {
+	var matches []matchInfo
+	for _, p := range positions {
+		if p < 0 || p >= len(text) {
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(text[p:])
+		if n := len(matches); n > 0 && matches[n-1].end == p {
+			matches[n-1].end = p + size
+			continue
+		}
+		matches = append(matches, matchInfo{start: p, end: p + size})
+	}
+	return matches
+}
+
+// renderHighlights renders text with the ranges in matches highlighted
+func renderHighlights(text string, matches []matchInfo, style lipgloss.Style) string {
 	if len(matches) == 0 {
 		return style.Render(text)
 	}
@@ -651,6 +803,11 @@ func (m Model) renderResults() string {
 	var s strings.Builder
 	s.Grow(4096) // Preallocate initial capacity
 
+	if m.searchErr != nil {
+		s.WriteString(fmt.Sprintf("Search error: %v", m.searchErr))
+		return s.String()
+	}
+
 	if len(m.results) == 0 {
 		s.WriteString("No results found")
 		return s.String()
@@ -660,23 +817,30 @@ func (m Model) renderResults() string {
 	queryVariations := m.getQueryVariations()
 
 	// Results count
-	resultInfo := fmt.Sprintf("Found %d results", len(m.results))
+	resultInfo := fmt.Sprintf("Found %d results (by %s)", len(m.results), m.order)
 	s.WriteString(pageStyle.Render(resultInfo))
 	s.WriteString("\n\n")
 
 	// Render all results
 	for i, result := range m.results {
+		if result.Task != nil {
+			m.renderTask(&s, result, i == m.selected, queryVariations)
+			continue
+		}
+
 		// Memo title and category. The selected row is drawn as a full-width
 		// highlight bar so it stays visible regardless of cursor rendering.
 		if i == m.selected {
-			width := m.viewport.Width
-			if width <= 0 {
-				width = m.width - 4
-			}
-			fmt.Fprintf(&s, "%s\n", styles.SelectedBar(width, "▸ "+result.Memo.Title()))
+			fmt.Fprintf(&s, "%s\n", styles.SelectedBar(m.barWidth(), "▸ "+badgeMemo+" "+result.Memo.Title()))
 		} else {
 			title := m.highlightMatches(result.Memo.Title(), queryVariations, titleStyle)
-			fmt.Fprintf(&s, "  %s\n", title)
+			for _, match := range result.Matches {
+				if match.Type == memsearch.MatchTitle {
+					title = m.highlightMatch(match, queryVariations, titleStyle)
+					break
+				}
+			}
+			fmt.Fprintf(&s, "  %s %s\n", typeStyle.Render(badgeMemo), title)
 		}
 		datetime := result.Memo.Date().Format("2006-01-02 15:04")
 		datetime = dimStyle.Render(datetime)
@@ -740,7 +904,7 @@ func (m Model) renderResults() string {
 						if match.PrevLineContext != "" {
 							fmt.Fprintf(&s, "           %d: %s\n", match.Line-1, dimStyle.Render(match.PrevLineContext))
 						}
-						fmt.Fprintf(&s, "       >>> %d: %s\n", match.Line, m.highlightMatches(match.Content, queryVariations, lipgloss.NewStyle()))
+						fmt.Fprintf(&s, "       >>> %d: %s\n", match.Line, m.highlightMatch(match, queryVariations, lipgloss.NewStyle()))
 						if match.NextLineContext != "" {
 							fmt.Fprintf(&s, "           %d: %s\n", match.Line+1, dimStyle.Render(match.NextLineContext))
 						}
@@ -754,7 +918,7 @@ func (m Model) renderResults() string {
 					for _, match := range sortedMatches {
 						switch match.Type {
 						case memsearch.MatchTitle, memsearch.MatchCategory, memsearch.MatchHeading:
-							fmt.Fprintf(&s, "     %s\n", m.highlightMatches(match.Content, queryVariations, lipgloss.NewStyle()))
+							fmt.Fprintf(&s, "     %s\n", m.highlightMatch(match, queryVariations, lipgloss.NewStyle()))
 						}
 					}
 				}
@@ -766,6 +930,50 @@ func (m Model) renderResults() string {
 	return s.String()
 }
 
+// Result-type badges, shown before the title of every result
+const (
+	badgeMemo = "memo"
+	badgeTodo = "todo"
+)
+
+// barWidth returns the width of the selection bar
+func (m Model) barWidth() int {
+	if m.viewport.Width > 0 {
+		return m.viewport.Width
+	}
+	return m.width - 4
+}
+
+// renderTask renders a task result: its checkbox and text, then the date and
+// section of its todo file
+func (m Model) renderTask(s *strings.Builder, result memsearch.SearchResult, selected bool, queryVariations []string) {
+	task := result.Task
+	checkbox := "[ ]"
+	if task.Done {
+		checkbox = "[x]"
+	}
+
+	if selected {
+		fmt.Fprintf(s, "%s\n", styles.SelectedBar(m.barWidth(), "▸ "+badgeTodo+" "+checkbox+" "+task.Text))
+	} else {
+		text := m.highlightMatches(task.Text, queryVariations, lipgloss.NewStyle())
+		for _, match := range result.Matches {
+			if match.Type == memsearch.MatchTask {
+				text = m.highlightMatch(match, queryVariations, lipgloss.NewStyle())
+				break
+			}
+		}
+		fmt.Fprintf(s, "  %s %s %s\n", typeStyle.Render(badgeTodo), dimStyle.Render(checkbox), text)
+	}
+
+	datetime := dimStyle.Render(task.Date.Format("2006-01-02"))
+	section := ""
+	if task.Section != "" {
+		section = m.highlightMatches(task.Section, queryVariations, dimStyle)
+	}
+	fmt.Fprintf(s, "   %s | %s\n\n", datetime, section)
+}
+
 // scrollToSelected ensures the selected item is visible in the viewport
 func (m *Model) scrollToSelected(content string) {
 	lines := strings.Split(content, "\n")
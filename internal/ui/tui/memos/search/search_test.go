@@ -1,6 +1,7 @@
 package search
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -10,6 +11,8 @@ import (
 	"github.com/hirotoni/memov2/internal/common"
 	"github.com/hirotoni/memov2/internal/config/toml"
 	"github.com/hirotoni/memov2/internal/domain"
+	"github.com/hirotoni/memov2/internal/domain/markdown"
+	"github.com/hirotoni/memov2/internal/interfaces"
 	"github.com/hirotoni/memov2/internal/repositories/memo"
 	"github.com/hirotoni/memov2/internal/repositories/mock"
 	memsearch "github.com/hirotoni/memov2/internal/search"
@@ -23,6 +26,7 @@ func TestNew(t *testing.T) {
 	cfg, err := toml.NewConfig(toml.Option{
 		BaseDir:         tempDir,
 		MemosFolderName: "memos/",
+		SearchHistory:   filepath.Join(tempDir, "search_history"),
 	})
 	require.NoError(t, err)
 
@@ -33,7 +37,7 @@ func TestNew(t *testing.T) {
 	assert.NotNil(t, model)
 	assert.Equal(t, cfg, model.config)
 	assert.Equal(t, editor, model.editor)
-	assert.NotNil(t, model.romajiConv)
+	assert.NotNil(t, model.translit)
 	assert.Equal(t, focusInput, model.focus)
 }
 
@@ -43,6 +47,7 @@ func TestModel_Init(t *testing.T) {
 	cfg, err := toml.NewConfig(toml.Option{
 		BaseDir:         tempDir,
 		MemosFolderName: "memos/",
+		SearchHistory:   filepath.Join(tempDir, "search_history"),
 	})
 	require.NoError(t, err)
 
@@ -60,6 +65,7 @@ func TestModel_View(t *testing.T) {
 	cfg, err := toml.NewConfig(toml.Option{
 		BaseDir:         tempDir,
 		MemosFolderName: "memos/",
+		SearchHistory:   filepath.Join(tempDir, "search_history"),
 	})
 	require.NoError(t, err)
 
@@ -85,6 +91,7 @@ func TestModel_FocusManagement(t *testing.T) {
 	cfg, err := toml.NewConfig(toml.Option{
 		BaseDir:         tempDir,
 		MemosFolderName: "memos/",
+		SearchHistory:   filepath.Join(tempDir, "search_history"),
 	})
 	require.NoError(t, err)
 
@@ -129,6 +136,7 @@ func TestModel_EscKeyBehavior(t *testing.T) {
 	cfg, err := toml.NewConfig(toml.Option{
 		BaseDir:         tempDir,
 		MemosFolderName: "memos/",
+		SearchHistory:   filepath.Join(tempDir, "search_history"),
 	})
 	require.NoError(t, err)
 
@@ -158,6 +166,7 @@ func TestModel_NavigationKeys(t *testing.T) {
 	cfg, err := toml.NewConfig(toml.Option{
 		BaseDir:         tempDir,
 		MemosFolderName: "memos/",
+		SearchHistory:   filepath.Join(tempDir, "search_history"),
 	})
 	require.NoError(t, err)
 
@@ -257,6 +266,7 @@ func TestModel_OpenMemo(t *testing.T) {
 	cfg, err := toml.NewConfig(toml.Option{
 		BaseDir:         tempDir,
 		MemosFolderName: "memos/",
+		SearchHistory:   filepath.Join(tempDir, "search_history"),
 	})
 	require.NoError(t, err)
 
@@ -289,6 +299,94 @@ func TestModel_OpenMemo(t *testing.T) {
 	assert.NotNil(t, updatedModel)
 }
 
+// TestModel_OpenMemoAtMatch verifies that a selection opens the editor at the
+// line and column of the first body match
+func TestModel_OpenMemoAtMatch(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg, err := toml.NewConfig(toml.Option{
+		BaseDir:         tempDir,
+		MemosFolderName: "memos/",
+		SearchHistory:   filepath.Join(tempDir, "search_history"),
+	})
+	require.NoError(t, err)
+
+	testMemo, err := domain.NewMemoFile(time.Now(), "agenda", nil)
+	require.NoError(t, err)
+	testMemo.SetHeadingBlocks([]*markdown.HeadingBlock{
+		{Level: 2, HeadingText: "sync", ContentText: "first\nthe agenda\n", LineNumber: 8, ContentLineNumber: 10},
+	})
+
+	editor := &mock.MockEditor{}
+	modelPtr, err := New(cfg, editor)
+	require.NoError(t, err)
+	model := *modelPtr
+	model.searchInput.SetValue("agenda")
+	model.results = []memsearch.SearchResult{{
+		Memo: testMemo,
+		Matches: []memsearch.Match{
+			{Type: memsearch.MatchTitle, Content: "agenda"},
+			{Type: memsearch.MatchContent, HeadingOrder: 0, Line: 2, Content: "the agenda"},
+		},
+	}}
+	model.focus = focusList
+
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	require.Len(t, editor.Calls, 1)
+	assert.Equal(t, interfaces.Position{Line: 11, Column: 5}, editor.Calls[0].Position)
+
+	// Standalone mode reports the position with the path
+	standalonePtr, err := NewStandalone(cfg, editor)
+	require.NoError(t, err)
+	standalone := *standalonePtr
+	standalone.searchInput.SetValue("agenda")
+	standalone.results = model.results
+
+	updatedModel, _ := standalone.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	standalone = updatedModel.(Model)
+	assert.Equal(t, interfaces.Position{Line: 11, Column: 5}, standalone.SelectedPosition())
+}
+
+// TestModel_OpenTask tests that a task result is listed with its badge and
+// opens its todo file at the task
+func TestModel_OpenTask(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg, err := toml.NewConfig(toml.Option{
+		BaseDir:         tempDir,
+		MemosFolderName: "memos/",
+		SearchHistory:   filepath.Join(tempDir, "search_history"),
+	})
+	require.NoError(t, err)
+
+	task := &memsearch.Task{
+		Task: markdown.Task{Text: "fix the deploy script", Section: "todos", Line: 4, Column: 6},
+		Path: "2025/01/20250115Wed_todos.md",
+		Date: time.Date(2025, 1, 15, 0, 0, 0, 0, time.Local),
+	}
+	results := []memsearch.SearchResult{{
+		Task:    task,
+		Matches: []memsearch.Match{{Type: memsearch.MatchTask, Line: 5, Content: "fix the deploy script"}},
+	}}
+
+	editor := &mock.MockEditor{}
+	standalonePtr, err := NewStandalone(cfg, editor)
+	require.NoError(t, err)
+	standalone := *standalonePtr
+	standalone.searchInput.SetValue("deploy")
+	standalone.results = results
+	standalone.selected = -1
+
+	view := standalone.renderResults()
+	assert.Contains(t, view, "todo")
+	assert.Contains(t, view, "[ ]")
+	assert.Contains(t, view, "2025-01-15")
+
+	standalone.selected = 0
+	updatedModel, _ := standalone.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	standalone = updatedModel.(Model)
+	assert.Equal(t, filepath.Join(cfg.TodosDir(), task.Path), standalone.SelectedPath())
+	assert.Equal(t, interfaces.Position{Line: 5, Column: 15}, standalone.SelectedPosition())
+}
+
 // TestModel_StandaloneFzfNavigation verifies the fzf-style interaction used by
 // `alt search`: focus stays in the input, ctrl+n/ctrl+p move the highlight, and
 // enter selects-and-quits (reporting the path) instead of switching focus.
@@ -297,6 +395,7 @@ func TestModel_StandaloneFzfNavigation(t *testing.T) {
 	cfg, err := toml.NewConfig(toml.Option{
 		BaseDir:         tempDir,
 		MemosFolderName: "memos/",
+		SearchHistory:   filepath.Join(tempDir, "search_history"),
 	})
 	require.NoError(t, err)
 
@@ -348,6 +447,7 @@ func TestModel_WindowResize(t *testing.T) {
 	cfg, err := toml.NewConfig(toml.Option{
 		BaseDir:         tempDir,
 		MemosFolderName: "memos/",
+		SearchHistory:   filepath.Join(tempDir, "search_history"),
 	})
 	require.NoError(t, err)
 
@@ -376,6 +476,7 @@ func TestModel_SearchTrigger(t *testing.T) {
 	cfg, err := toml.NewConfig(toml.Option{
 		BaseDir:         tempDir,
 		MemosFolderName: "memos/",
+		SearchHistory:   filepath.Join(tempDir, "search_history"),
 	})
 	require.NoError(t, err)
 
@@ -408,6 +509,7 @@ func TestModel_EmptySearch(t *testing.T) {
 	cfg, err := toml.NewConfig(toml.Option{
 		BaseDir:         tempDir,
 		MemosFolderName: "memos/",
+		SearchHistory:   filepath.Join(tempDir, "search_history"),
 	})
 	require.NoError(t, err)
 
@@ -417,18 +519,44 @@ func TestModel_EmptySearch(t *testing.T) {
 	model := *modelPtr
 
 	// Empty search should return empty results
-	results := model.search()
+	results := model.search(context.Background())
 	searchMsg, ok := results.(searchResultMsg)
 	require.True(t, ok)
 	assert.Empty(t, searchMsg.results, "Empty search should return no results")
 }
 
+// TestModel_InvalidQuery tests that a query that cannot be parsed shows the error
+func TestModel_InvalidQuery(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg, err := toml.NewConfig(toml.Option{
+		BaseDir:         tempDir,
+		MemosFolderName: "memos/",
+		SearchHistory:   filepath.Join(tempDir, "search_history"),
+	})
+	require.NoError(t, err)
+
+	editor := &mock.MockEditor{}
+	modelPtr, err := New(cfg, editor)
+	require.NoError(t, err)
+	model := *modelPtr
+
+	model.searchInput.SetValue("after:yesterday")
+	searchMsg, ok := model.search(context.Background()).(searchResultMsg)
+	require.True(t, ok)
+	require.Error(t, searchMsg.err)
+
+	updatedModel, _ := model.Update(searchMsg)
+	model = updatedModel.(Model)
+	assert.Contains(t, model.renderResults(), "Search error: invalid date in after:yesterday")
+}
+
 // TestModel_ViewWithResults tests view rendering with search results
 func TestModel_ViewWithResults(t *testing.T) {
 	tempDir := t.TempDir()
 	cfg, err := toml.NewConfig(toml.Option{
 		BaseDir:         tempDir,
 		MemosFolderName: "memos/",
+		SearchHistory:   filepath.Join(tempDir, "search_history"),
 	})
 	require.NoError(t, err)
 
@@ -456,12 +584,73 @@ func TestModel_ViewWithResults(t *testing.T) {
 	assert.NotEmpty(t, view)
 }
 
+// TestModel_ToggleOrder tests switching the results between relevance and date order
+func TestModel_ToggleOrder(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg, err := toml.NewConfig(toml.Option{
+		BaseDir:         tempDir,
+		MemosFolderName: "memos/",
+		SearchHistory:   filepath.Join(tempDir, "search_history"),
+	})
+	require.NoError(t, err)
+
+	editor := &mock.MockEditor{}
+	modelPtr, err := New(cfg, editor)
+	require.NoError(t, err)
+	model := *modelPtr
+	assert.Equal(t, memsearch.OrderRelevance, model.order)
+
+	older, err := domain.NewMemoFile(time.Now().Add(-time.Hour), "older", nil)
+	require.NoError(t, err)
+	newer, err := domain.NewMemoFile(time.Now(), "newer", nil)
+	require.NoError(t, err)
+	model.results = []memsearch.SearchResult{
+		{Memo: older, Score: 2},
+		{Memo: newer, Score: 1},
+	}
+	model.selected = 1
+
+	updatedModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyCtrlO})
+	model = updatedModel.(Model)
+	assert.Equal(t, memsearch.OrderDate, model.order)
+	assert.Equal(t, "newer", model.results[0].Memo.Title())
+	assert.Equal(t, 0, model.selected)
+	assert.Contains(t, model.renderResults(), "(by date)")
+
+	updatedModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyCtrlO})
+	model = updatedModel.(Model)
+	assert.Equal(t, memsearch.OrderRelevance, model.order)
+	assert.Equal(t, "older", model.results[0].Memo.Title())
+}
+
+// TestPositionMatches tests turning fuzzy match positions into highlight ranges
+func TestPositionMatches(t *testing.T) {
+	testCases := []struct {
+		name      string
+		text      string
+		positions []int
+		want      []matchInfo
+	}{
+		{name: "adjacent characters merge", text: "meeting", positions: []int{0, 3, 5, 6}, want: []matchInfo{{0, 1}, {3, 4}, {5, 7}}},
+		{name: "multibyte characters", text: "定例会議", positions: []int{6, 9}, want: []matchInfo{{6, 12}}},
+		{name: "out of range positions are ignored", text: "abc", positions: []int{2, 3}, want: []matchInfo{{2, 3}}},
+		{name: "no positions", text: "abc", positions: nil, want: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, positionMatches(tc.text, tc.positions))
+		})
+	}
+}
+
 // TestModel_Quit tests quit functionality
 func TestModel_Quit(t *testing.T) {
 	tempDir := t.TempDir()
 	cfg, err := toml.NewConfig(toml.Option{
 		BaseDir:         tempDir,
 		MemosFolderName: "memos/",
+		SearchHistory:   filepath.Join(tempDir, "search_history"),
 	})
 	require.NoError(t, err)
 
@@ -480,6 +669,7 @@ func TestModel_ErrorHandling(t *testing.T) {
 	cfg, err := toml.NewConfig(toml.Option{
 		BaseDir:         tempDir,
 		MemosFolderName: "memos/",
+		SearchHistory:   filepath.Join(tempDir, "search_history"),
 	})
 	require.NoError(t, err)
 
@@ -501,6 +691,7 @@ func TestModel_LastKeyGTracking(t *testing.T) {
 	cfg, err := toml.NewConfig(toml.Option{
 		BaseDir:         tempDir,
 		MemosFolderName: "memos/",
+		SearchHistory:   filepath.Join(tempDir, "search_history"),
 	})
 	require.NoError(t, err)
 
@@ -545,3 +736,162 @@ func TestModel_LastKeyGTracking(t *testing.T) {
 		assert.False(t, model.lastKeyG, "Other keys should clear lastKeyG")
 	}
 }
+
+// TestModel_DebouncedSearch tests that only the latest query is searched and shown
+func TestModel_DebouncedSearch(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg, err := toml.NewConfig(toml.Option{
+		BaseDir:         tempDir,
+		MemosFolderName: "memos/",
+		SearchHistory:   filepath.Join(tempDir, "search_history"),
+	})
+	require.NoError(t, err)
+
+	modelPtr, err := New(cfg, &mock.MockEditor{})
+	require.NoError(t, err)
+	model := *modelPtr
+
+	for _, r := range "ab" {
+		updatedModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		model = updatedModel.(Model)
+	}
+	assert.Equal(t, 2, model.generation)
+
+	// The input was still changing when the first debounce fired
+	_, cmd := model.Update(searchDebounceMsg{generation: 1})
+	assert.Nil(t, cmd)
+
+	updatedModel, cmd := model.Update(searchDebounceMsg{generation: 2})
+	model = updatedModel.(Model)
+	require.NotNil(t, cmd)
+	assert.NotNil(t, model.cancelSearch)
+	msg, ok := cmd().(searchResultMsg)
+	require.True(t, ok)
+	assert.Equal(t, 2, msg.generation)
+
+	// Results of an older query arriving late are dropped
+	stale, err := domain.NewMemoFile(time.Now(), "stale", nil)
+	require.NoError(t, err)
+	updatedModel, _ = model.Update(searchResultMsg{generation: 1, results: []memsearch.SearchResult{{Memo: stale}}})
+	model = updatedModel.(Model)
+	assert.Empty(t, model.results)
+
+	updatedModel, _ = model.Update(msg)
+	model = updatedModel.(Model)
+	assert.Nil(t, model.cancelSearch)
+}
+
+// TestModel_History tests that up/down recall the queries of opened results
+func TestModel_History(t *testing.T) {
+	tempDir := t.TempDir()
+	historyPath := filepath.Join(tempDir, "search_history")
+	require.NoError(t, os.WriteFile(historyPath, []byte("kaigi\ncat:work deploy\n"), 0o644))
+	cfg, err := toml.NewConfig(toml.Option{
+		BaseDir:         tempDir,
+		MemosFolderName: "memos/",
+		SearchHistory:   historyPath,
+	})
+	require.NoError(t, err)
+
+	editor := &mock.MockEditor{}
+	modelPtr, err := New(cfg, editor)
+	require.NoError(t, err)
+	model := *modelPtr
+
+	press := func(k tea.KeyType) {
+		updatedModel, _ := model.Update(tea.KeyMsg{Type: k})
+		model = updatedModel.(Model)
+	}
+
+	for _, r := range "draft" {
+		updatedModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		model = updatedModel.(Model)
+	}
+	press(tea.KeyUp)
+	assert.Equal(t, "cat:work deploy", model.searchInput.Value())
+	press(tea.KeyUp)
+	assert.Equal(t, "kaigi", model.searchInput.Value())
+	press(tea.KeyUp)
+	assert.Equal(t, "kaigi", model.searchInput.Value(), "the oldest query stays")
+	press(tea.KeyDown)
+	assert.Equal(t, "cat:work deploy", model.searchInput.Value())
+	press(tea.KeyDown)
+	assert.Equal(t, "draft", model.searchInput.Value(), "the typed query is restored")
+
+	// Opening a result records its query as the newest
+	testMemo, err := domain.NewMemoFile(time.Now(), "agenda", nil)
+	require.NoError(t, err)
+	model.results = []memsearch.SearchResult{{Memo: testMemo}}
+	model.focus = focusList
+	press(tea.KeyEnter)
+	require.Len(t, editor.Calls, 1)
+
+	b, err := os.ReadFile(historyPath)
+	require.NoError(t, err)
+	assert.Equal(t, "kaigi\ncat:work deploy\ndraft\n", string(b))
+
+	// In standalone mode up recalls only on an empty query, and moves the
+	// highlight otherwise
+	standalonePtr, err := NewStandalone(cfg, editor)
+	require.NoError(t, err)
+	model = *standalonePtr
+	press(tea.KeyUp)
+	assert.Equal(t, "draft", model.searchInput.Value())
+	model.results = []memsearch.SearchResult{{Memo: testMemo}, {Memo: testMemo}}
+	press(tea.KeyCtrlN)
+	assert.Equal(t, 1, model.selected)
+
+	model.searchInput.SetValue("typed")
+	press(tea.KeyUp)
+	assert.Equal(t, "typed", model.searchInput.Value())
+	assert.Equal(t, 0, model.selected)
+}
+
+// TestModel_Scope tests limiting the search to a category and widening it
+func TestModel_Scope(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg, err := toml.NewConfig(toml.Option{
+		BaseDir:         tempDir,
+		MemosFolderName: "memos/",
+		SearchHistory:   filepath.Join(tempDir, "search_history"),
+	})
+	require.NoError(t, err)
+
+	repo := memo.NewMemo(cfg.MemosDir(), common.DefaultLogger())
+	now := time.Now()
+	for i, category := range [][]string{{"work", "projects"}, {"work"}, {"home"}} {
+		m, err := domain.NewMemoFile(now.Add(time.Duration(i)*time.Second), "plan-"+category[len(category)-1], category)
+		require.NoError(t, err)
+		require.NoError(t, repo.Save(m, true))
+	}
+
+	modelPtr, err := New(cfg, &mock.MockEditor{})
+	require.NoError(t, err)
+	model := *modelPtr
+	model.searchInput.SetValue("plan")
+
+	titles := func() []string {
+		msg := model.search(context.Background()).(searchResultMsg)
+		require.NoError(t, msg.err)
+		var ts []string
+		for _, r := range msg.results {
+			ts = append(ts, r.Memo.Title())
+		}
+		return ts
+	}
+
+	require.NotNil(t, model.SetScope([]string{"work", "projects"}))
+	assert.ElementsMatch(t, []string{"plan-projects"}, titles())
+	assert.Contains(t, model.View(), "in work › projects")
+
+	updatedModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyCtrlG})
+	model = updatedModel.(Model)
+	assert.Equal(t, []string{"work"}, model.Scope())
+	assert.ElementsMatch(t, []string{"plan-projects", "plan-work"}, titles())
+
+	updatedModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyCtrlX})
+	model = updatedModel.(Model)
+	assert.Nil(t, model.Scope())
+	assert.Len(t, titles(), 3)
+	assert.NotContains(t, model.View(), "ctrl+g")
+}
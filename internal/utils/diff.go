@@ -0,0 +1,15 @@
+package utils
+
+import (
+	"fmt"
+
+	"github.com/hexops/gotextdiff"
+	"github.com/hexops/gotextdiff/myers"
+	"github.com/hexops/gotextdiff/span"
+)
+
+// UnifiedDiff returns the unified diff turning atext into btext, or an empty string if they are equal
+func UnifiedDiff(aname, atext, bname, btext string) string {
+	edits := myers.ComputeEdits(span.URIFromPath(aname), atext, btext)
+	return fmt.Sprint(gotextdiff.ToUnified(aname, bname, atext, edits))
+}
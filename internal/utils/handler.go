@@ -22,6 +22,9 @@ type MarkdownHandler struct {
 func NewMarkdownHandler() *MarkdownHandler {
 	return &MarkdownHandler{
 		md: goldmark.New(
+			goldmark.WithParser(
+				mygoldmark.NewParser(),
+			),
 			goldmark.WithRenderer(
 				mygoldmark.NewMarkdownRenderer(),
 			),
@@ -104,10 +107,11 @@ func (h *MarkdownHandler) HeadingBlockByHeading(source []byte, heading Heading)
 	}
 
 	markdownEntity := &interfaces.HeadingBlock{
-		HeadingText: string(foundHeading.Lines().Value(source)),
-		Level:       foundHeading.(*ast.Heading).Level,
-		ContentText: strings.TrimRight(contents.String(), "\n"),
-		LineNumber:  foundHeading.Lines().At(0).Start,
+		HeadingText:       string(foundHeading.Lines().Value(source)),
+		Level:             foundHeading.(*ast.Heading).Level,
+		ContentText:       strings.TrimRight(contents.String(), "\n"),
+		LineNumber:        lineAt(source, foundHeading.Lines().At(0).Start),
+		ContentLineNumber: contentLineAfter(source, foundHeading.Lines().At(0).Start, len(hangingNodes) > 0),
 	}
 
 	return markdownEntity, nil
@@ -142,10 +146,6 @@ func (h *MarkdownHandler) HeadingBlocksByLevel(source []byte, level int) ([]*int
 		// Remove leading and trailing newlines
 		markdownEntity.ContentText = strings.Trim(markdownEntity.ContentText, "\n")
 
-		// Normalize multiple consecutive newlines to single newlines
-		markdownEntity.ContentText = strings.ReplaceAll(markdownEntity.ContentText, "\n\n\n", "\n\n")
-		markdownEntity.ContentText = strings.ReplaceAll(markdownEntity.ContentText, "\n\n\n", "\n\n")
-
 		// Add a single newline at the end
 		markdownEntity.ContentText += "\n"
 
@@ -188,15 +188,46 @@ func (h *MarkdownHandler) TopLevelBodyContent(source []byte) *interfaces.Heading
 	}
 
 	markdownEntity := &interfaces.HeadingBlock{
-		HeadingText: string(firstChild.Lines().Value(source)),
-		Level:       1,
-		ContentText: strings.TrimLeft(strings.TrimRight(contents.String(), "\n"), "\n"),
-		LineNumber:  firstChild.Lines().At(0).Start,
+		HeadingText:       string(firstChild.Lines().Value(source)),
+		Level:             1,
+		ContentText:       strings.TrimLeft(strings.TrimRight(contents.String(), "\n"), "\n"),
+		LineNumber:        lineAt(source, firstChild.Lines().At(0).Start),
+		ContentLineNumber: contentLineAfter(source, firstChild.Lines().At(0).Start, contents.Len() > 0),
 	}
 
 	return markdownEntity
 }
 
+// lineAt returns the 1-based line number of the byte offset in source
+func lineAt(source []byte, offset int) int {
+	return bytes.Count(source[:offset], []byte("\n")) + 1
+}
+
+// contentLineAfter returns the line number of the first non-blank line after the
+// heading at offset, which is where its content starts, or 0 if it has no content
+func contentLineAfter(source []byte, offset int, hasContent bool) int {
+	if !hasContent {
+		return 0
+	}
+	line := lineAt(source, offset)
+	rest := source[offset:]
+	for {
+		i := bytes.IndexByte(rest, '\n')
+		if i < 0 {
+			return 0
+		}
+		rest = rest[i+1:]
+		line++
+		end := bytes.IndexByte(rest, '\n')
+		if end < 0 {
+			end = len(rest)
+		}
+		if len(bytes.TrimSpace(rest[:end])) > 0 {
+			return line
+		}
+	}
+}
+
 func (h *MarkdownHandler) Metadata(source []byte) map[string]interface{} {
 	reader := text.NewReader(source)
 	doc := h.md.Parser().Parse(reader)
@@ -308,3 +308,85 @@ More content`,
 		})
 	}
 }
+
+func TestMarkdownHandler_HeadingBlocksByLevel_PreservesContent(t *testing.T) {
+	handler := NewMarkdownHandler()
+
+	source := loadTestFile(t, "lossless_content.md")
+
+	blocks, err := handler.HeadingBlocksByLevel(source, 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"Before ![alt](a.png) after, <span style=\"color:red\">red</span>, ~~struck~~.\n\n<details>\n<summary>More</summary>\n\nHidden text.\n</details>\n",
+		"See [the spec][spec] and the note[^1].\n\n```go\nfunc main() {\n\n\n}\n```\n\n[spec]: https://example.com/spec\n[^1]: The note.\n",
+		"_underscored_ and __strong__ text\n",
+	}
+	if len(blocks) != len(expected) {
+		t.Fatalf("Expected %d level 2 headings, got %d", len(expected), len(blocks))
+	}
+	for i, block := range blocks {
+		if block.ContentText != expected[i] {
+			t.Errorf("Block %q: expected content %q, got %q", block.HeadingText, expected[i], block.ContentText)
+		}
+	}
+
+	top := handler.TopLevelBodyContent(source)
+	if top == nil {
+		t.Fatal("Expected top level body content")
+	}
+	if want := "Intro with ![diagram](img/diagram.png \"Diagram\") and <kbd>Ctrl</kbd>."; top.ContentText != want {
+		t.Errorf("Expected top level content %q, got %q", want, top.ContentText)
+	}
+}
+
+func TestMarkdownHandler_LineNumbers(t *testing.T) {
+	handler := NewMarkdownHandler()
+
+	source := []byte("---\ncategory: [work]\n---\n# Title\n\nIntro\n\n## first\n\nalpha\n\nbeta\n## empty\n## last\ngamma\n")
+	lines := strings.Split(string(source), "\n")
+
+	top := handler.TopLevelBodyContent(source)
+	if top == nil {
+		t.Fatal("Expected top level body content")
+	}
+	if top.LineNumber != 4 || top.ContentLineNumber != 6 {
+		t.Errorf("Expected top level heading at line 4 and content at line 6, got %d and %d", top.LineNumber, top.ContentLineNumber)
+	}
+
+	blocks, err := handler.HeadingBlocksByLevel(source, 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		heading     string
+		line        int
+		contentLine int
+	}{
+		{"first", 8, 10},
+		{"empty", 13, 0},
+		{"last", 14, 15},
+	}
+	if len(blocks) != len(tests) {
+		t.Fatalf("Expected %d level 2 headings, got %d", len(tests), len(blocks))
+	}
+	for i, tt := range tests {
+		block := blocks[i]
+		if block.HeadingText != tt.heading || block.LineNumber != tt.line || block.ContentLineNumber != tt.contentLine {
+			t.Errorf("Expected %q at line %d with content at line %d, got %q at line %d with content at line %d",
+				tt.heading, tt.line, tt.contentLine, block.HeadingText, block.LineNumber, block.ContentLineNumber)
+		}
+		if tt.contentLine == 0 {
+			continue
+		}
+		// Every content line is found at its offset from ContentLineNumber
+		for j, l := range strings.Split(strings.TrimRight(block.ContentText, "\n"), "\n") {
+			if got := lines[block.ContentLineNumber-1+j]; got != l {
+				t.Errorf("Block %q line %d: expected %q in the file, got %q", block.HeadingText, j+1, l, got)
+			}
+		}
+	}
+}
@@ -33,6 +33,11 @@ func (mb *MarkdownBuilder) text2tag(text string) string {
 	return tag
 }
 
+// HeadingTag returns the anchor BuildLink uses to link to a heading with the given text
+func (mb *MarkdownBuilder) HeadingTag(text string) string {
+	return mb.text2tag(text)
+}
+
 func (mb *MarkdownBuilder) removeCharacters(text, charsToRemove string) string {
 	for _, char := range charsToRemove {
 		text = strings.ReplaceAll(text, string(char), "")
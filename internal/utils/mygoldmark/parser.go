@@ -0,0 +1,162 @@
+package mygoldmark
+
+import (
+	"github.com/yuin/goldmark/ast"
+	extast "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// sourceStartAttr is the node attribute holding the byte offset at which a block starts in the source.
+var sourceStartAttr = []byte("mygoldmark-source-start")
+
+// NewParser returns a goldmark parser with the default block parsers, where every
+// block remembers the byte offset it starts at. The renderer uses these offsets to
+// write parsed blocks back exactly as they appear in the source.
+func NewParser() parser.Parser {
+	blockParsers := parser.DefaultBlockParsers()
+	for i, bp := range blockParsers {
+		blockParsers[i] = util.Prioritized(&positionedBlockParser{bp.Value.(parser.BlockParser)}, bp.Priority)
+	}
+
+	return parser.NewParser(
+		parser.WithBlockParsers(blockParsers...),
+		parser.WithInlineParsers(parser.DefaultInlineParsers()...),
+		parser.WithParagraphTransformers(parser.DefaultParagraphTransformers()...),
+	)
+}
+
+// positionedBlockParser records the start offset of every block opened by the wrapped parser
+type positionedBlockParser struct {
+	parser.BlockParser
+}
+
+func (p *positionedBlockParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	_, segment := reader.PeekLine()
+	node, state := p.BlockParser.Open(parent, reader, pc)
+	if node != nil {
+		node.SetAttribute(sourceStartAttr, segment.Start)
+	}
+	return node, state
+}
+
+// sourceStart returns the byte offset at which block n starts in source.
+// A heading owns its own lines only, so whatever follows it up to the next
+// block (e.g. link reference definitions) belongs to the next block.
+func sourceStart(source []byte, n ast.Node) (int, bool) {
+	if prev, ok := n.PreviousSibling().(*ast.Heading); ok {
+		return headingStop(source, prev)
+	}
+
+	switch n := n.(type) {
+	case *ast.Document:
+		return 0, true
+	case *ast.Heading:
+		start, ok := recordedStart(n)
+		// A setext heading is opened at its underline, after the text lines
+		if n.Lines().Len() > 0 && (!ok || n.Lines().At(0).Start < start) {
+			return n.Lines().At(0).Start, true
+		}
+		return start, ok
+	case *extast.Table:
+		return tableStart(source, n)
+	}
+
+	if start, ok := recordedStart(n); ok {
+		return start, true
+	}
+	// Paragraphs of tight lists are replaced by text blocks after parsing
+	if n.Lines().Len() > 0 {
+		return n.Lines().At(0).Start, true
+	}
+	return 0, false
+}
+
+// headingStop returns the offset just after the last line of heading n
+func headingStop(source []byte, n *ast.Heading) (int, bool) {
+	start, ok := recordedStart(n)
+	if !ok {
+		return 0, false
+	}
+	if n.Lines().Len() == 0 || n.Lines().At(0).Start > start {
+		// ATX heading: a single line
+		return lineEnd(source, start), true
+	}
+	// Setext heading: the text lines followed by the underline
+	return lineEnd(source, lineEnd(source, n.Lines().At(n.Lines().Len()-1).Start)), true
+}
+
+// lineEnd returns the offset just after the newline ending the line at pos
+func lineEnd(source []byte, pos int) int {
+	for pos < len(source) {
+		pos++
+		if source[pos-1] == '\n' {
+			break
+		}
+	}
+	return pos
+}
+
+func recordedStart(n ast.Node) (int, bool) {
+	v, ok := n.Attribute(sourceStartAttr)
+	if !ok {
+		return 0, false
+	}
+	start, ok := v.(int)
+	return start, ok
+}
+
+// tableStart locates a table by its first header cell, as tables are built from
+// paragraphs after block parsing and have no recorded offset.
+func tableStart(source []byte, n *extast.Table) (int, bool) {
+	header := n.FirstChild()
+	if header == nil || header.FirstChild() == nil || header.FirstChild().Lines().Len() == 0 {
+		return 0, false
+	}
+
+	start := header.FirstChild().Lines().At(0).Start
+	for start > 0 && (source[start-1] == ' ' || source[start-1] == '\t') {
+		start--
+	}
+	if start > 0 && source[start-1] == '|' {
+		start--
+	}
+	return start, true
+}
+
+// sourceStop returns the offset at which the block following n starts, or the
+// end of source if n is the last block
+func sourceStop(source []byte, n ast.Node) (int, bool) {
+	for cur := n; cur != nil; cur = cur.Parent() {
+		for next := cur.NextSibling(); next != nil; next = next.NextSibling() {
+			if start, ok := sourceStart(source, next); ok {
+				return start, true
+			}
+			// Empty blocks (e.g. left behind by link reference definitions) take no source
+			if next.HasChildren() || next.Lines().Len() > 0 {
+				return 0, false
+			}
+		}
+	}
+	return len(source), true
+}
+
+// sourceSpan returns the source range of block n, including the blank lines and
+// container markers up to the next block. ok is false for nodes that were not
+// parsed from source.
+func sourceSpan(source []byte, n ast.Node) (start, stop int, ok bool) {
+	if n.Type() != ast.TypeBlock && n.Type() != ast.TypeDocument {
+		return 0, 0, false
+	}
+	if start, ok = sourceStart(source, n); !ok {
+		return 0, 0, false
+	}
+	if stop, ok = sourceStop(source, n); !ok {
+		return 0, 0, false
+	}
+	if start > stop || stop > len(source) {
+		return 0, 0, false
+	}
+	return start, stop, true
+}
@@ -2,6 +2,7 @@ package mygoldmark
 
 import (
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/yuin/goldmark/ast"
@@ -10,12 +11,48 @@ import (
 	"github.com/yuin/goldmark/util"
 )
 
+// NewMarkdownRenderer returns a renderer that writes nodes back as markdown.
+// Blocks parsed by NewParser are written as their exact source bytes, so that
+// parse and render is an identity; other nodes are rendered from the AST.
 func NewMarkdownRenderer() renderer.Renderer {
-	return renderer.NewRenderer(
-		renderer.WithNodeRenderers(
-			util.Prioritized(NewRenderer(), 1),
+	return &markdownRenderer{
+		Renderer: renderer.NewRenderer(
+			renderer.WithNodeRenderers(
+				util.Prioritized(NewRenderer(), 1),
+			),
 		),
-	)
+	}
+}
+
+type markdownRenderer struct {
+	renderer.Renderer
+}
+
+func (r *markdownRenderer) Render(w io.Writer, source []byte, n ast.Node) error {
+	start, stop, ok := sourceSpan(source, n)
+	if !ok {
+		return r.Renderer.Render(w, source, n)
+	}
+
+	if n.Kind() != ast.KindDocument || !n.HasChildren() {
+		_, err := w.Write(source[start:stop])
+		return err
+	}
+
+	// Render the document block by block, keeping anything before the first block (e.g. frontmatter)
+	first, ok := sourceStart(source, n.FirstChild())
+	if !ok {
+		return r.Renderer.Render(w, source, n)
+	}
+	if _, err := w.Write(source[:first]); err != nil {
+		return err
+	}
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		if err := r.Render(w, source, c); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 type Renderer struct{}
@@ -54,12 +91,17 @@ func (r *Renderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
 	reg.Register(ast.KindAutoLink, r.renderAutoLink)
 	reg.Register(ast.KindCodeSpan, r.renderCodeSpan)
 	reg.Register(ast.KindEmphasis, r.renderEmphasis)
-	// reg.Register(ast.KindImage, r.renderImage)
+	reg.Register(ast.KindImage, r.renderImage)
 	reg.Register(ast.KindLink, r.renderLink)
-	// reg.Register(ast.KindRawHTML, r.renderRawHTML)
+	reg.Register(ast.KindRawHTML, r.renderRawHTML)
 	reg.Register(ast.KindText, r.renderText)
-	// reg.Register(ast.KindString, r.renderString)
+	reg.Register(ast.KindString, r.renderString)
 	reg.Register(extast.KindTaskCheckBox, r.renderTaskCheckBox)
+	reg.Register(extast.KindStrikethrough, r.renderStrikethrough)
+	reg.Register(extast.KindFootnoteLink, r.renderFootnoteLink)
+	reg.Register(extast.KindFootnoteBacklink, r.renderFootnoteBacklink)
+	reg.Register(extast.KindFootnoteList, r.renderFootnoteList)
+	reg.Register(extast.KindFootnote, r.renderFootnote)
 }
 
 func (r *Renderer) renderDocument(
@@ -107,8 +149,8 @@ func (r *Renderer) renderText(
 		if p == nil {
 			return ast.WalkContinue, nil
 		}
-		if p.Kind() == ast.KindLink {
-			// r.renderLink() renders text in advance. no rendering needed here.
+		if p.Kind() == ast.KindLink || p.Kind() == ast.KindImage {
+			// r.renderLink() and r.renderImage() render text in advance. no rendering needed here.
 			return ast.WalkContinue, nil
 		}
 
@@ -328,6 +370,102 @@ func (r *Renderer) renderLink(
 	return ast.WalkContinue, nil
 }
 
+func (r *Renderer) renderImage(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*ast.Image)
+	if entering {
+		w.WriteString(fmt.Sprintf("![%s](%s", n.Text(source), n.Destination))
+		if len(n.Title) > 0 {
+			w.WriteString(fmt.Sprintf(" %q", n.Title))
+		}
+		w.WriteString(")")
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *Renderer) renderRawHTML(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*ast.RawHTML)
+	if entering {
+		for i := 0; i < n.Segments.Len(); i++ {
+			segment := n.Segments.At(i)
+			_, _ = w.Write(segment.Value(source))
+		}
+	}
+	return ast.WalkSkipChildren, nil
+}
+
+func (r *Renderer) renderString(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*ast.String)
+	if entering {
+		_, _ = w.Write(n.Value)
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *Renderer) renderStrikethrough(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	_, _ = w.WriteString("~~")
+	return ast.WalkContinue, nil
+}
+
+// footnoteRef returns the label of the footnote that n refers to, falling back to its index
+func footnoteRef(n *extast.FootnoteLink) string {
+	var doc ast.Node = n
+	for doc.Parent() != nil {
+		doc = doc.Parent()
+	}
+	if doc.Kind() == ast.KindDocument {
+		for c := doc.LastChild(); c != nil; c = c.PreviousSibling() {
+			if c.Kind() != extast.KindFootnoteList {
+				continue
+			}
+			for fn := c.FirstChild(); fn != nil; fn = fn.NextSibling() {
+				if fn, ok := fn.(*extast.Footnote); ok && fn.Index == n.Index {
+					return string(fn.Ref)
+				}
+			}
+		}
+	}
+	return fmt.Sprintf("%d", n.Index)
+}
+
+func (r *Renderer) renderFootnoteLink(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*extast.FootnoteLink)
+	if entering {
+		w.WriteString(fmt.Sprintf("[^%s]", footnoteRef(n)))
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *Renderer) renderFootnoteBacklink(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	// backlinks only exist in rendered html
+	return ast.WalkContinue, nil
+}
+
+func (r *Renderer) renderFootnoteList(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering && node.PreviousSibling() != nil {
+		_, _ = w.WriteString("\n\n")
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *Renderer) renderFootnote(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*extast.Footnote)
+	if entering {
+		if n.PreviousSibling() != nil {
+			_, _ = w.WriteString("\n")
+		}
+		w.WriteString(fmt.Sprintf("[^%s]: ", n.Ref))
+	}
+	return ast.WalkContinue, nil
+}
+
 func (r *Renderer) renderAutoLink(
 	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
 	n := node.(*ast.AutoLink)
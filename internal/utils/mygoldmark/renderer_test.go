@@ -2,13 +2,20 @@ package mygoldmark
 
 import (
 	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/yuin/goldmark"
+	meta "github.com/yuin/goldmark-meta"
 	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
 	extast "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/text"
 )
 
 func TestMarkdownRenderer_renderHeading(t *testing.T) {
@@ -1264,3 +1271,245 @@ func TestMarkdownRenderer_renderTableCell(t *testing.T) {
 		})
 	}
 }
+
+func TestMarkdownRenderer_renderImage(t *testing.T) {
+	type args struct {
+		node     ast.Node
+		entering bool
+	}
+	type wants struct {
+		status ast.WalkStatus
+		str    string
+		err    bool
+	}
+
+	source := []byte("alt text")
+
+	tests := []struct {
+		name  string
+		args  args
+		wants wants
+	}{
+		{
+			name:  "entering true",
+			args:  args{node: genImageNode([]byte("alt text"), []byte("image.png"), nil), entering: true},
+			wants: wants{status: ast.WalkContinue, str: "![alt text](image.png)", err: false},
+		},
+		{
+			name:  "entering true, with title",
+			args:  args{node: genImageNode([]byte("alt text"), []byte("image.png"), []byte("a title")), entering: true},
+			wants: wants{status: ast.WalkContinue, str: "![alt text](image.png \"a title\")", err: false},
+		},
+		{
+			name:  "entering false",
+			args:  args{node: genImageNode([]byte("alt text"), []byte("image.png"), nil), entering: false},
+			wants: wants{status: ast.WalkContinue, str: "", err: false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			r := NewRenderer()
+			sb := new(strings.Builder)
+			w := bufio.NewWriter(sb)
+
+			got, err := r.renderImage(w, source, tt.args.node, tt.args.entering)
+			if (err != nil) != tt.wants.err {
+				t.Errorf("MarkdownRenderer.renderImage() error = %v, wantErr %v", err, tt.wants.err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.wants.status) {
+				t.Errorf("MarkdownRenderer.renderImage() = %v, want %v", got, tt.wants.status)
+			}
+
+			assert.NoError(w.Flush())
+			assert.Equal(tt.wants.str, sb.String())
+		})
+	}
+}
+
+func TestMarkdownRenderer_renderRawHTML(t *testing.T) {
+	type args struct {
+		node     ast.Node
+		entering bool
+	}
+	type wants struct {
+		status ast.WalkStatus
+		str    string
+		err    bool
+	}
+
+	source := []byte("<span\nclass=\"x\">")
+
+	tests := []struct {
+		name  string
+		args  args
+		wants wants
+	}{
+		{
+			name:  "entering true, single segment",
+			args:  args{node: genRawHTMLNode(text.NewSegment(0, 5)), entering: true},
+			wants: wants{status: ast.WalkSkipChildren, str: "<span", err: false},
+		},
+		{
+			name:  "entering true, multiple segments",
+			args:  args{node: genRawHTMLNode(text.NewSegment(0, 6), text.NewSegment(6, 16)), entering: true},
+			wants: wants{status: ast.WalkSkipChildren, str: "<span\nclass=\"x\">", err: false},
+		},
+		{
+			name:  "entering false",
+			args:  args{node: genRawHTMLNode(text.NewSegment(0, 5)), entering: false},
+			wants: wants{status: ast.WalkSkipChildren, str: "", err: false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			r := NewRenderer()
+			sb := new(strings.Builder)
+			w := bufio.NewWriter(sb)
+
+			got, err := r.renderRawHTML(w, source, tt.args.node, tt.args.entering)
+			if (err != nil) != tt.wants.err {
+				t.Errorf("MarkdownRenderer.renderRawHTML() error = %v, wantErr %v", err, tt.wants.err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.wants.status) {
+				t.Errorf("MarkdownRenderer.renderRawHTML() = %v, want %v", got, tt.wants.status)
+			}
+
+			assert.NoError(w.Flush())
+			assert.Equal(tt.wants.str, sb.String())
+		})
+	}
+}
+
+func TestMarkdownRenderer_renderString(t *testing.T) {
+	tests := []struct {
+		name     string
+		node     ast.Node
+		entering bool
+		want     string
+	}{
+		{name: "entering true", node: ast.NewString([]byte("value")), entering: true, want: "value"},
+		{name: "entering false", node: ast.NewString([]byte("value")), entering: false, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			r := NewRenderer()
+			sb := new(strings.Builder)
+			w := bufio.NewWriter(sb)
+
+			got, err := r.renderString(w, []byte("dummy source"), tt.node, tt.entering)
+			assert.NoError(err)
+			assert.Equal(ast.WalkContinue, got)
+
+			assert.NoError(w.Flush())
+			assert.Equal(tt.want, sb.String())
+		})
+	}
+}
+
+func TestMarkdownRenderer_renderStrikethrough(t *testing.T) {
+	tests := []struct {
+		name     string
+		entering bool
+		want     string
+	}{
+		{name: "entering true", entering: true, want: "~~"},
+		{name: "entering false", entering: false, want: "~~"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			r := NewRenderer()
+			sb := new(strings.Builder)
+			w := bufio.NewWriter(sb)
+
+			got, err := r.renderStrikethrough(w, []byte("dummy source"), genStrikethroughNode(), tt.entering)
+			assert.NoError(err)
+			assert.Equal(ast.WalkContinue, got)
+
+			assert.NoError(w.Flush())
+			assert.Equal(tt.want, sb.String())
+		})
+	}
+}
+
+func TestMarkdownRenderer_renderFootnoteLink(t *testing.T) {
+	tests := []struct {
+		name     string
+		node     ast.Node
+		entering bool
+		want     string
+	}{
+		{name: "entering true", node: genFootnoteLinkNode(1, []byte("note")), entering: true, want: "[^note]"},
+		{name: "entering true, no footnote list", node: genFootnoteLinkNode(2, nil), entering: true, want: "[^2]"},
+		{name: "entering false", node: genFootnoteLinkNode(1, []byte("note")), entering: false, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			r := NewRenderer()
+			sb := new(strings.Builder)
+			w := bufio.NewWriter(sb)
+
+			got, err := r.renderFootnoteLink(w, []byte("dummy source"), tt.node, tt.entering)
+			assert.NoError(err)
+			assert.Equal(ast.WalkContinue, got)
+
+			assert.NoError(w.Flush())
+			assert.Equal(tt.want, sb.String())
+		})
+	}
+}
+
+func TestMarkdownRenderer_RoundTrip(t *testing.T) {
+	md := goldmark.New(
+		goldmark.WithParser(NewParser()),
+		goldmark.WithRenderer(NewMarkdownRenderer()),
+		goldmark.WithExtensions(
+			extension.GFM,
+			meta.New(meta.WithStoresInDocument()),
+		),
+	)
+
+	files, err := filepath.Glob(filepath.Join("testdata", "roundtrip", "*.md"))
+	if err != nil {
+		t.Fatalf("failed to list golden files: %v", err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no golden files found")
+	}
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			source, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatalf("failed to read golden file %s: %v", file, err)
+			}
+			doc := md.Parser().Parse(text.NewReader(source))
+
+			// The whole document
+			buf := new(bytes.Buffer)
+			if err := md.Renderer().Render(buf, source, doc); err != nil {
+				t.Fatalf("failed to render document: %v", err)
+			}
+			assert.Equal(t, string(source), buf.String())
+
+			// Every top-level block on its own, as MarkdownHandler renders them
+			first, _ := sourceStart(source, doc.FirstChild())
+			buf = bytes.NewBuffer(source[:first:first])
+			for c := doc.FirstChild(); c != nil; c = c.NextSibling() {
+				if _, _, ok := sourceSpan(source, c); !ok && (c.HasChildren() || c.Lines().Len() > 0) {
+					t.Errorf("%s block has no source position", c.Kind())
+				}
+				if err := md.Renderer().Render(buf, source, c); err != nil {
+					t.Fatalf("failed to render %s: %v", c.Kind(), err)
+				}
+			}
+			assert.Equal(t, string(source), buf.String())
+		})
+	}
+}
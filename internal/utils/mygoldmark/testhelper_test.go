@@ -278,3 +278,49 @@ func genTableNode(headers []string, rows [][]string, alignments []extast.Alignme
 
 	return table
 }
+
+func genImageNode(text, destination, title []byte) ast.Node {
+	ni := ast.NewImage(ast.NewLink())
+	ni.Destination = destination
+	ni.Title = title
+
+	// segment
+	t := ast.NewText()
+	t.Segment.Start = 0
+	t.Segment.Stop = len(text)
+	ni.AppendChild(ni, t)
+
+	return ni
+}
+
+func genRawHTMLNode(segments ...text.Segment) ast.Node {
+	n := ast.NewRawHTML()
+	for _, s := range segments {
+		n.Segments.Append(s)
+	}
+	return n
+}
+
+func genStrikethroughNode() ast.Node {
+	return extast.NewStrikethrough()
+}
+
+func genFootnoteLinkNode(index int, ref []byte) ast.Node {
+	fl := extast.NewFootnoteLink(index)
+	if ref == nil {
+		return fl
+	}
+
+	// FootnoteLink refers to the footnote by index, so the document must hold the footnote list
+	doc := ast.NewDocument()
+	p := ast.NewParagraph()
+	p.AppendChild(p, fl)
+	doc.AppendChild(doc, p)
+
+	list := extast.NewFootnoteList()
+	list.AppendChild(list, extast.NewFootnote(ref))
+	list.FirstChild().(*extast.Footnote).Index = index
+	doc.AppendChild(doc, list)
+
+	return fl
+}